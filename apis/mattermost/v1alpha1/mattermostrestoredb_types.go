@@ -4,6 +4,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -15,9 +16,17 @@ func init() {
 // +k8s:openapi-gen=true
 type MattermostRestoreDBSpec struct {
 	// MattermostClusterName defines the ClusterInstallation name.
+	// Deprecated: use MattermostName to restore the database of a Mattermost installation.
 	MattermostClusterName string `json:"mattermostClusterName,omitempty"`
+	// MattermostName defines the name of the Mattermost installation
+	// (installation.mattermost.com/v1beta1). Takes precedence over MattermostClusterName.
+	// +optional
+	MattermostName string `json:"mattermostName,omitempty"`
 	// RestoreSecret defines the secret that holds the credentials to
-	// MySQL Operator be able to download the DB backup file
+	// MySQL Operator be able to download the DB backup file.
+	// For external databases the secret keys are exposed as environment
+	// variables to the AWS CLI, ie AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+	// AWS_REGION and AWS_ENDPOINT_URL.
 	RestoreSecret string `json:"restoreSecret,omitempty"`
 	// InitBucketURL defines where the DB backup file is located.
 	InitBucketURL string `json:"initBucketURL,omitempty"`
@@ -33,6 +42,15 @@ type MattermostRestoreDBSpec struct {
 	// Need to set if different from `mattermost`.
 	// +optional
 	MattermostDBName string `json:"mattermostDBName,omitempty"`
+	// RestoreImage overrides the database client image used to restore
+	// external databases. Defaults to postgres or mysql image matching the
+	// database type.
+	// +optional
+	RestoreImage string `json:"restoreImage,omitempty"`
+	// Resources defines the resource requests and limits for the restore job
+	// of external databases.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // RestoreState is the state of the Mattermost Restore Database
@@ -49,6 +67,24 @@ const (
 	Failed RestoreState = "failed"
 )
 
+// RestorePhase is the phase of the restore of a Mattermost installation.
+type RestorePhase string
+
+// Restore phases reported for Mattermost installations:
+const (
+	// RestoreScalingDown is the phase when Mattermost and the dedicated job
+	// server are scaled down to stop using the database.
+	RestoreScalingDown RestorePhase = "ScalingDown"
+	// RestoreRestoring is the phase when the database is being restored.
+	RestoreRestoring RestorePhase = "Restoring"
+	// RestoreFinished is the phase when the database is restored and
+	// Mattermost is scaled up.
+	RestoreFinished RestorePhase = "Finished"
+	// RestoreFailed is the phase when the restore failed. Mattermost is
+	// scaled up if it was scaled down.
+	RestoreFailed RestorePhase = "Failed"
+)
+
 // MattermostRestoreDBStatus defines the observed state of MattermostRestoreDB
 // +k8s:openapi-gen=true
 type MattermostRestoreDBStatus struct {
@@ -58,6 +94,24 @@ type MattermostRestoreDBStatus struct {
 	// The original number of database replicas. will be used to restore after applying the db restore process.
 	// +optional
 	OriginalDBReplicas int32 `json:"originalDBReplicas,omitempty"`
+	// Represents the phase of the restore of a Mattermost installation.
+	// +optional
+	Phase RestorePhase `json:"phase,omitempty"`
+	// Describes the reason of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// The original number of Mattermost replicas, restored after the restore.
+	// +optional
+	OriginalReplicas *int32 `json:"originalReplicas,omitempty"`
+	// Whether the dedicated job server was enabled before the restore.
+	// +optional
+	OriginalDedicatedJobServer bool `json:"originalDedicatedJobServer,omitempty"`
+	// Time when the restore was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time when the restore finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -67,6 +121,7 @@ type MattermostRestoreDBStatus struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:priority=0,name="State",type=string,JSONPath=".status.state",description="State of Mattermost DB Restore"
+// +kubebuilder:printcolumn:priority=0,name="Phase",type=string,JSONPath=".status.phase",description="Phase of Mattermost DB Restore"
 // +kubebuilder:printcolumn:priority=0,name="Original DB Replicas",type=string,JSONPath=".status.originalDBReplicas",description="Original DB Replicas"
 type MattermostRestoreDB struct {
 	metav1.TypeMeta   `json:",inline"`
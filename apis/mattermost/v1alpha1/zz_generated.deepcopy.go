@@ -279,8 +279,8 @@ func (in *MattermostRestoreDB) DeepCopyInto(out *MattermostRestoreDB) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MattermostRestoreDB.
@@ -336,6 +336,7 @@ func (in *MattermostRestoreDBList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MattermostRestoreDBSpec) DeepCopyInto(out *MattermostRestoreDBSpec) {
 	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MattermostRestoreDBSpec.
@@ -351,6 +352,19 @@ func (in *MattermostRestoreDBSpec) DeepCopy() *MattermostRestoreDBSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MattermostRestoreDBStatus) DeepCopyInto(out *MattermostRestoreDBStatus) {
 	*out = *in
+	if in.OriginalReplicas != nil {
+		in, out := &in.OriginalReplicas, &out.OriginalReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MattermostRestoreDBStatus.
@@ -348,14 +348,21 @@ func schema_mattermost_operator_apis_mattermost_v1alpha1_MattermostRestoreDBSpec
 				Properties: map[string]spec.Schema{
 					"mattermostClusterName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MattermostClusterName defines the ClusterInstallation name.",
+							Description: "MattermostClusterName defines the ClusterInstallation name. Deprecated: use MattermostName to restore the database of a Mattermost installation.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mattermostName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MattermostName defines the name of the Mattermost installation (installation.mattermost.com/v1beta1). Takes precedence over MattermostClusterName.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 					"restoreSecret": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RestoreSecret defines the secret that holds the credentials to MySQL Operator be able to download the DB backup file",
+							Description: "RestoreSecret defines the secret that holds the credentials to MySQL Operator be able to download the DB backup file. For external databases the secret keys are exposed as environment variables to the AWS CLI, ie AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_REGION and AWS_ENDPOINT_URL.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -388,9 +395,25 @@ func schema_mattermost_operator_apis_mattermost_v1alpha1_MattermostRestoreDBSpec
 							Format:      "",
 						},
 					},
+					"restoreImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RestoreImage overrides the database client image used to restore external databases. Defaults to postgres or mysql image matching the database type.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources defines the resource requests and limits for the restore job of external databases.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ResourceRequirements"},
 	}
 }
 
@@ -415,8 +438,50 @@ func schema_mattermost_operator_apis_mattermost_v1alpha1_MattermostRestoreDBStat
 							Format:      "int32",
 						},
 					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Represents the phase of the restore of a Mattermost installation.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Describes the reason of the current phase.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"originalReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The original number of Mattermost replicas, restored after the restore.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"originalDedicatedJobServer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the dedicated job server was enabled before the restore.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the restore was started.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the restore finished.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
@@ -38,9 +38,30 @@ func (omd *OperatorManagedDatabase) SetDefaults() {
 	}
 	if omd.Version == "" {
 		omd.Version = DefaultDatabaseVersion
+		if omd.Type == DatabaseTypePostgres {
+			omd.Version = DefaultPostgresDatabaseVersion
+		}
 	}
 }
 
+// GetPostgresImage returns the PostgreSQL image of the provisioned database.
+func (omd *OperatorManagedDatabase) GetPostgresImage() string {
+	return PostgresImageRepository + ":" + omd.Version
+}
+
+// GetReadinessCheckImage returns the image of the readiness init container
+// of an operator-managed database. It defaults to the PostgreSQL image of the
+// provisioned database, which matches its major version.
+func (db *Database) GetReadinessCheckImage() string {
+	if db.ReadinessCheck != nil && db.ReadinessCheck.Image != "" {
+		return db.ReadinessCheck.Image
+	}
+	if db.OperatorManaged == nil {
+		return PostgresImageRepository + ":" + DefaultPostgresDatabaseVersion
+	}
+	return db.OperatorManaged.GetPostgresImage()
+}
+
 func (db *Database) SetDefaultReplicasAndResources() {
 	if db.IsExternal() {
 		return
@@ -36,6 +36,12 @@ func (fs *FileStore) IsLocal() bool {
 	return fs.Local != nil && fs.Local.Enabled
 }
 
+// IsOperatorManaged returns true if the filestore is the MinIO instance
+// managed by the operator.
+func (fs *FileStore) IsOperatorManaged() bool {
+	return !fs.isAnyExceptOperatorManaged()
+}
+
 // isAnyExceptOperatorManaged checks if any filestore types are configurated
 // except the operator managed type. This is generally used to see if defaults
 // should be applied.
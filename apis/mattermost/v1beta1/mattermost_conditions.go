@@ -0,0 +1,148 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported in MattermostStatus.Conditions.
+const (
+	// ConditionDatabaseReady indicates whether the database configuration
+	// was resolved successfully.
+	ConditionDatabaseReady = "DatabaseReady"
+	// ConditionFileStoreReady indicates whether the file store configuration
+	// was resolved successfully.
+	ConditionFileStoreReady = "FileStoreReady"
+	// ConditionUpdateJobSucceeded indicates the result of the update job
+	// run before rolling out a new Mattermost image.
+	ConditionUpdateJobSucceeded = "UpdateJobSucceeded"
+	// ConditionDeploymentRolledOut indicates whether all Mattermost pods are
+	// running the desired version.
+	ConditionDeploymentRolledOut = "DeploymentRolledOut"
+	// ConditionIngressReady indicates whether the Ingress is reconciled and
+	// its load balancer is available.
+	ConditionIngressReady = "IngressReady"
+	// ConditionHTTPRouteAccepted indicates whether the HTTPRoute is accepted
+	// by all of the Gateways it references.
+	ConditionHTTPRouteAccepted = "HTTPRouteAccepted"
+	// ConditionCertificateReady indicates whether the TLS certificate
+	// issued by cert-manager is ready for use.
+	ConditionCertificateReady = "CertificateReady"
+	// ConditionJobServerReady indicates whether the dedicated job server is
+	// rolled out.
+	ConditionJobServerReady = "JobServerReady"
+	// ConditionResourcePatchApplied indicates whether the resource patches
+	// were applied successfully.
+	ConditionResourcePatchApplied = "ResourcePatchApplied"
+	// ConditionRolledBack indicates whether the last rollout of a new image
+	// failed and the stable image was restored.
+	ConditionRolledBack = "RolledBack"
+	// ConditionCleanupComplete indicates whether the data of a deleted
+	// Mattermost instance was handled according to its deletion policy.
+	ConditionCleanupComplete = "CleanupComplete"
+	// ConditionUpgradeAllowed indicates whether the version in the spec can
+	// be rolled out from the current version.
+	ConditionUpgradeAllowed = "UpgradeAllowed"
+	// ConditionImageDigestResolved indicates whether the image tag was
+	// resolved to a digest.
+	ConditionImageDigestResolved = "ImageDigestResolved"
+)
+
+// Condition reasons reported in MattermostStatus.Conditions.
+const (
+	ReasonDatabaseConfigured       = "DatabaseConfigured"
+	ReasonDatabaseCheckFailed      = "DatabaseCheckFailed"
+	ReasonFileStoreConfigured      = "FileStoreConfigured"
+	ReasonFileStoreCheckFailed     = "FileStoreCheckFailed"
+	ReasonUpdateJobRunning         = "UpdateJobRunning"
+	ReasonUpdateJobCompleted       = "UpdateJobCompleted"
+	ReasonUpdateJobFailed          = "UpdateJobFailed"
+	ReasonUpdateJobDisabled        = "UpdateJobDisabled"
+	ReasonDeploymentCheckFailed    = "DeploymentCheckFailed"
+	ReasonRolloutInProgress        = "RolloutInProgress"
+	ReasonRolloutComplete          = "RolloutComplete"
+	ReasonIngressReconciled        = "IngressReconciled"
+	ReasonIngressDisabled          = "IngressDisabled"
+	ReasonIngressCheckFailed       = "IngressCheckFailed"
+	ReasonLoadBalancerPending      = "LoadBalancerPending"
+	ReasonHTTPRouteAccepted        = "HTTPRouteAccepted"
+	ReasonHTTPRoutePending         = "HTTPRoutePending"
+	ReasonHTTPRouteNotAccepted     = "HTTPRouteNotAccepted"
+	ReasonHTTPRouteCheckFailed     = "HTTPRouteCheckFailed"
+	ReasonCertificateIssued        = "CertificateIssued"
+	ReasonCertificatePending       = "CertificatePending"
+	ReasonCertificateFailed        = "CertificateIssuanceFailed"
+	ReasonCertificateCheckFailed   = "CertificateCheckFailed"
+	ReasonJobServerDisabled        = "JobServerDisabled"
+	ReasonJobServerCheckFailed     = "JobServerCheckFailed"
+	ReasonJobServerRolloutComplete = "JobServerRolloutComplete"
+	ReasonJobServerRolloutPending  = "JobServerRolloutPending"
+	ReasonPatchesApplied           = "PatchesApplied"
+	ReasonPatchFailed              = "PatchFailed"
+	ReasonNoPatches                = "NoPatches"
+	ReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	ReasonNewImageUnhealthy        = "NewImageUnhealthy"
+	ReasonDataRetained             = "DataRetained"
+	ReasonSnapshotRunning          = "SnapshotRunning"
+	ReasonSnapshotSucceeded        = "SnapshotSucceeded"
+	ReasonSnapshotFailed           = "SnapshotFailed"
+	ReasonCleanupFailed            = "CleanupFailed"
+	ReasonDeletionProtected        = "DeletionProtected"
+	ReasonUpgradeSupported         = "UpgradeSupported"
+	ReasonUpgradeOverridden        = "UpgradeOverridden"
+	ReasonIntermediateUpgrade      = "IntermediateUpgrade"
+	ReasonDowngradeBlocked         = "DowngradeBlocked"
+	ReasonMajorVersionSkipBlocked  = "MajorVersionSkipBlocked"
+	ReasonImageDigestResolved      = "ImageDigestResolved"
+	ReasonImageDigestResolveFailed = "ImageDigestResolveFailed"
+)
+
+// SetCondition adds or updates the condition of the given type. The
+// transition time is only changed when the condition status changes.
+func (s *MattermostStatus) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: s.ObservedGeneration,
+	})
+}
+
+// GetCondition returns the condition of the given type or nil if it is not set.
+func (s *MattermostStatus) GetCondition(conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(s.Conditions, conditionType)
+}
+
+// RemoveCondition removes the condition of the given type.
+func (s *MattermostStatus) RemoveCondition(conditionType string) {
+	meta.RemoveStatusCondition(&s.Conditions, conditionType)
+}
+
+// IsConditionTrue returns true if the condition of the given type is set to True.
+func (s *MattermostStatus) IsConditionTrue(conditionType string) bool {
+	return meta.IsStatusConditionTrue(s.Conditions, conditionType)
+}
+
+// updateResourcePatchCondition sets ResourcePatchApplied condition based on
+// the status of particular patches.
+func (s *MattermostStatus) updateResourcePatchCondition() {
+	if s.ResourcePatch == nil || (s.ResourcePatch.DeploymentPatch == nil && s.ResourcePatch.ServicePatch == nil) {
+		s.SetCondition(ConditionResourcePatchApplied, metav1.ConditionTrue, ReasonNoPatches, "No resource patches specified")
+		return
+	}
+
+	if patch := s.ResourcePatch.DeploymentPatch; patch != nil && !patch.Applied {
+		s.SetCondition(ConditionResourcePatchApplied, metav1.ConditionFalse, ReasonPatchFailed, patch.Error)
+		return
+	}
+	if patch := s.ResourcePatch.ServicePatch; patch != nil && !patch.Applied {
+		s.SetCondition(ConditionResourcePatchApplied, metav1.ConditionFalse, ReasonPatchFailed, patch.Error)
+		return
+	}
+
+	s.SetCondition(ConditionResourcePatchApplied, metav1.ConditionTrue, ReasonPatchesApplied, "Resource patches applied")
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultImageDigestRefreshInterval is the default interval between
+	// resolutions of the image tag.
+	DefaultImageDigestRefreshInterval = time.Hour
+	// minImageDigestRefreshInterval limits the requests to the registry.
+	minImageDigestRefreshInterval = time.Minute
+)
+
+// ImageDigestPinningEnabled returns true if the image tag is pinned to a
+// digest. Versions which are digests already are not pinned.
+func (mm *Mattermost) ImageDigestPinningEnabled() bool {
+	return mm.Spec.ImageDigestPinning != nil && mm.Spec.ImageDigestPinning.Enabled &&
+		!strings.HasPrefix(mm.Spec.Version, "sha256:") && !strings.Contains(mm.Spec.Version, "@")
+}
+
+// GetRefreshInterval returns the interval between resolutions of the tag.
+func (p *ImageDigestPinning) GetRefreshInterval() time.Duration {
+	if p.RefreshInterval == nil || p.RefreshInterval.Duration == 0 {
+		return DefaultImageDigestRefreshInterval
+	}
+	return p.RefreshInterval.Duration
+}
+
+// GetPinnedImageName returns the image the installation runs, which is the
+// image pinned to the digest recorded in the status if digest pinning is
+// enabled.
+func (mm *Mattermost) GetPinnedImageName() string {
+	image := mm.GetImageName()
+	digest := mm.Status.ImageDigest
+	if !mm.ImageDigestPinningEnabled() || digest == nil || digest.Image != image {
+		return image
+	}
+	return image + "@" + digest.Digest
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ForceUpgradeAnnotation applies the image change outside of the maintenance
+// window when set to the image waiting for the window.
+const ForceUpgradeAnnotation = "installation.mattermost.com/force-upgrade"
+
+// UpgradeForced returns true if the image change is forced to be applied
+// outside of the maintenance window.
+func (mm *Mattermost) UpgradeForced(image string) bool {
+	return image != "" && mm.Annotations[ForceUpgradeAnnotation] == image
+}
+
+// Check returns true if the time is within a maintenance window, and the
+// start of the first window after the time.
+func (w *MaintenanceWindow) Check(t time.Time) (bool, time.Time, error) {
+	location, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		return false, time.Time{}, errors.Wrap(err, "invalid time zone")
+	}
+	schedule, err := utils.ParseCronSchedule(w.Schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	t = t.In(location)
+	// The most recent window started after t - duration if t is within it.
+	start := schedule.Next(t.Add(-w.Duration.Duration))
+	inWindow := !start.IsZero() && !start.After(t)
+
+	return inWindow, schedule.Next(t), nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"fmt"
+)
+
+// IsEnabled returns true if the plugin is activated.
+func (p Plugin) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// Source returns a description of the source of the plugin bundle.
+func (p Plugin) Source() string {
+	switch {
+	case p.URL != "":
+		return p.URL
+	case p.ConfigMap != nil:
+		return fmt.Sprintf("configmap:%s/%s", p.ConfigMap.Name, p.ConfigMap.Key)
+	case p.Image != nil:
+		return fmt.Sprintf("image:%s:%s", p.Image.Name, p.Image.Path)
+	}
+	return ""
+}
+
+// GetPluginStagingImage returns the image of the init container staging the
+// plugin bundles.
+func (mm *Mattermost) GetPluginStagingImage() string {
+	if mm.Spec.PluginStagingImage != "" {
+		return mm.Spec.PluginStagingImage
+	}
+	return DefaultPluginStagingImage
+}
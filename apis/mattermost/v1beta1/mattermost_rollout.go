@@ -0,0 +1,132 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"time"
+)
+
+const (
+	// DefaultRolloutAnalysisSeconds is the default time the new image has to
+	// stay healthy before it is promoted.
+	DefaultRolloutAnalysisSeconds = 300
+	// DefaultRolloutProgressDeadlineSeconds is the default time the new image
+	// can take to become healthy before the rollout is aborted.
+	DefaultRolloutProgressDeadlineSeconds = 600
+	// DefaultCanaryReplicas is the default number of canary replicas.
+	DefaultCanaryReplicas = 1
+	// DefaultCanaryWeight is the default percentage of requests routed to the canary.
+	DefaultCanaryWeight = 10
+	// DefaultPreviewCookie is the default name of the cookie routing requests
+	// to the BlueGreen preview deployment.
+	DefaultPreviewCookie = "mattermost-preview"
+)
+
+// RolloutStrategy returns the strategy used to roll out new images.
+func (mm *Mattermost) RolloutStrategy() RolloutStrategyType {
+	if mm.Spec.Rollout == nil || mm.Spec.Rollout.Strategy == "" {
+		return RolloutStrategyRollingUpdate
+	}
+	return mm.Spec.Rollout.Strategy
+}
+
+// ProgressiveRolloutEnabled returns true if new images are rolled out with
+// the Canary or BlueGreen strategy.
+func (mm *Mattermost) ProgressiveRolloutEnabled() bool {
+	strategy := mm.RolloutStrategy()
+	return strategy == RolloutStrategyCanary || strategy == RolloutStrategyBlueGreen
+}
+
+// RolloutTrafficRoutingEnabled returns true if the traffic can be routed to
+// the new image by the Ingress.
+func (mm *Mattermost) RolloutTrafficRoutingEnabled() bool {
+	return !mm.Spec.UseServiceLoadBalancer && !mm.AWSLoadBalancerEnabled() && mm.IngressEnabled()
+}
+
+// RolloutDeploymentName returns the name of the deployment running the new
+// image during a Canary or BlueGreen rollout.
+func (mm *Mattermost) RolloutDeploymentName() string {
+	if mm.RolloutStrategy() == RolloutStrategyBlueGreen {
+		return mm.Name + "-preview"
+	}
+	return mm.Name + "-canary"
+}
+
+// RolloutReplicas returns the number of replicas of the deployment running
+// the new image.
+func (mm *Mattermost) RolloutReplicas() int32 {
+	if mm.RolloutStrategy() == RolloutStrategyBlueGreen {
+		if mm.Spec.Replicas != nil {
+			return *mm.Spec.Replicas
+		}
+		return 1
+	}
+	if mm.Spec.Rollout.Canary != nil && mm.Spec.Rollout.Canary.Replicas != nil {
+		return *mm.Spec.Rollout.Canary.Replicas
+	}
+	return DefaultCanaryReplicas
+}
+
+// RolloutWeight returns the percentage of requests routed to the new image
+// while it is analysed. BlueGreen preview only receives requests selected by
+// the cookie.
+func (mm *Mattermost) RolloutWeight() int32 {
+	if mm.RolloutStrategy() == RolloutStrategyBlueGreen {
+		return 0
+	}
+	if mm.Spec.Rollout.Canary != nil && mm.Spec.Rollout.Canary.Weight != nil {
+		return *mm.Spec.Rollout.Canary.Weight
+	}
+	return DefaultCanaryWeight
+}
+
+// RolloutCookie returns the name of the cookie routing requests to the new
+// image or empty string if requests are not routed by a cookie.
+func (mm *Mattermost) RolloutCookie() string {
+	if mm.RolloutStrategy() == RolloutStrategyBlueGreen {
+		if mm.Spec.Rollout.BlueGreen != nil && mm.Spec.Rollout.BlueGreen.PreviewCookie != "" {
+			return mm.Spec.Rollout.BlueGreen.PreviewCookie
+		}
+		return DefaultPreviewCookie
+	}
+	if mm.Spec.Rollout.Canary != nil {
+		return mm.Spec.Rollout.Canary.Cookie
+	}
+	return ""
+}
+
+// GetAnalysisDuration returns how long the new image has to stay healthy
+// before it is promoted.
+func (ro *Rollout) GetAnalysisDuration() time.Duration {
+	if ro == nil || ro.AnalysisSeconds == nil {
+		return DefaultRolloutAnalysisSeconds * time.Second
+	}
+	return time.Duration(*ro.AnalysisSeconds) * time.Second
+}
+
+// GetProgressDeadline returns how long the new image can take to become
+// healthy.
+func (ro *Rollout) GetProgressDeadline() time.Duration {
+	if ro == nil || ro.ProgressDeadlineSeconds == nil {
+		return DefaultRolloutProgressDeadlineSeconds * time.Second
+	}
+	return time.Duration(*ro.ProgressDeadlineSeconds) * time.Second
+}
+
+// IsActive returns true if the rollout is in progress.
+func (s *RolloutStatus) IsActive() bool {
+	return s != nil && (s.Phase == RolloutProgressing || s.Phase == RolloutPromoting)
+}
+
+// IsProgressive returns true if the rollout uses the Canary or BlueGreen
+// strategy.
+func (s *RolloutStatus) IsProgressive() bool {
+	return s != nil && s.Strategy != RolloutStrategyRollingUpdate
+}
+
+// IsAbortedFor returns true if the rollout of the image was aborted and the
+// spec did not change since.
+func (s *RolloutStatus) IsAbortedFor(image string, generation int64) bool {
+	return s != nil && s.Phase == RolloutAborted && s.Image == image && s.AbortedGeneration == generation
+}
@@ -6,7 +6,11 @@ package v1beta1
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -79,6 +83,12 @@ type MattermostSpec struct {
 
 	// +optional
 	AWSLoadBalancerController *AWSLoadBalancerController `json:"awsLoadBalancerController,omitempty"`
+
+	// Gateway defines configuration for the Gateway API HTTPRoute exposing
+	// Mattermost. When enabled, it is created instead of the Ingress.
+	// +optional
+	Gateway *Gateway `json:"gateway,omitempty"`
+
 	// Volumes allows for mounting volumes from various sources into the
 	// Mattermost application pods.
 	// +optional
@@ -133,6 +143,87 @@ type MattermostSpec struct {
 	// +optional
 	JobServer *JobServer `json:"jobServer,omitempty"`
 
+	// Rollout defines the strategy used to roll out new Mattermost images.
+	// +optional
+	Rollout *Rollout `json:"rollout,omitempty"`
+
+	// MaintenanceWindow defines when changes of the Mattermost image are
+	// applied. Image changes made outside of the window are deferred until
+	// the next window, unless forced with the
+	// installation.mattermost.com/force-upgrade annotation. If not set,
+	// image changes are applied right away.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// UpgradePath defines how upgrades skipping major versions are handled.
+	// Downgrades and upgrades skipping major versions are blocked unless
+	// allowed with the installation.mattermost.com/allow-unsupported-upgrade
+	// annotation.
+	// +optional
+	UpgradePath *UpgradePath `json:"upgradePath,omitempty"`
+
+	// ImageDigestPinning resolves the image tag to a digest with the
+	// registry API and runs the Mattermost pods with the digest, so all pods
+	// run the same image. The tag is resolved again periodically, and a
+	// moved tag is rolled out like a new version.
+	// +optional
+	ImageDigestPinning *ImageDigestPinning `json:"imageDigestPinning,omitempty"`
+
+	// Plugins installed on the Mattermost pods. The plugin bundles are
+	// staged by an init container, and the states and configuration of the
+	// plugins are patched with mmctl in local mode when the pods start.
+	// Plugins not listed are left as they are.
+	// +optional
+	// +listType=map
+	// +listMapKey=id
+	Plugins []Plugin `json:"plugins,omitempty"`
+
+	// PluginStagingImage defines the image of the init container staging the
+	// plugin bundles. The image has to provide sh, curl, sha256sum and tar.
+	// Defaults to curlimages/curl with a pinned version.
+	// +optional
+	PluginStagingImage string `json:"pluginStagingImage,omitempty"`
+
+	// PodDisruptionBudget defines configuration for the PodDisruptionBudget
+	// of Mattermost pods.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+
+	// Autoscaling defines configuration for the HorizontalPodAutoscaler of
+	// the Mattermost deployment.
+	// +optional
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+
+	// NetworkPolicy defines configuration for the NetworkPolicy isolating
+	// Mattermost pods from the rest of the cluster.
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// Monitoring defines configuration of Mattermost metrics and of their
+	// scraping by Prometheus.
+	// +optional
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+
+	// DeletionPolicy defines what happens to the database and the file store
+	// managed by the operator when the Mattermost is deleted. Delete removes
+	// them together with the installation, Retain keeps them and Snapshot
+	// backs up the installation before it is removed. Defaults to Delete.
+	// +kubebuilder:validation:Enum=Delete;Retain;Snapshot
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DeletionSnapshot defines where the final backup of the installation is
+	// stored when DeletionPolicy is Snapshot.
+	// +optional
+	DeletionSnapshot *DeletionSnapshot `json:"deletionSnapshot,omitempty"`
+
+	// DeletionProtection prevents the Mattermost from being deleted. The
+	// deletion is rejected unless the protection is turned off or the
+	// installation.mattermost.com/confirm-deletion annotation is set to the
+	// UID of the Mattermost.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
 	// PodExtensions specify custom extensions for Mattermost pods.
 	// This can be used for custom readiness checks etc.
 	// These settings generally don't need to be changed.
@@ -187,6 +278,39 @@ type Ingress struct {
 	// IngressClass will be set on Ingress resource to associate it with specified IngressClass resource.
 	// +optional
 	IngressClass *string `json:"ingressClass,omitempty"`
+	// CertManager configures a cert-manager Certificate issuing the TLS
+	// certificate for all Ingress hosts. The certificate is stored in
+	// TLSSecret, or in a secret named after the host if TLSSecret is empty.
+	// +optional
+	CertManager *CertManager `json:"certManager,omitempty"`
+}
+
+// CertManager defines the cert-manager Certificate of the Mattermost hosts.
+type CertManager struct {
+	// IssuerRef references the cert-manager Issuer or ClusterIssuer
+	// issuing the certificate.
+	IssuerRef CertManagerIssuerReference `json:"issuerRef"`
+
+	// Duration is the requested lifetime of the certificate. Defaults to the
+	// duration set by the issuer.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// CertManagerIssuerReference references a cert-manager issuer.
+type CertManagerIssuerReference struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+
+	// Kind of the issuer. Defaults to `Issuer`.
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer. Defaults to `cert-manager.io`, set it for
+	// external issuers.
+	// +optional
+	Group string `json:"group,omitempty"`
 }
 
 type AWSLoadBalancerController struct {
@@ -215,6 +339,45 @@ type AWSLoadBalancerController struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// Gateway defines the HTTPRoute attaching Mattermost to existing Gateways.
+// Host names of the HTTPRoute are taken from the Ingress configuration.
+type Gateway struct {
+	// Enabled defines if Mattermost is exposed through a Gateway API
+	// HTTPRoute instead of an Ingress.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ParentRefs references the Gateways the HTTPRoute is attached to.
+	// +optional
+	ParentRefs []GatewayParentReference `json:"parentRefs,omitempty"`
+
+	// RequestTimeout is the maximum duration for the Gateway to respond to a
+	// request. Defaults to `0s`, which disables the timeout so that WebSocket
+	// connections are not interrupted.
+	// +kubebuilder:validation:Pattern=`^([0-9]{1,5}(h|m|s|ms)){1,4}$`
+	// +optional
+	RequestTimeout string `json:"requestTimeout,omitempty"`
+
+	// Annotations defines annotations passed to the HTTPRoute.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayParentReference references a Gateway, and optionally one of its
+// listeners.
+type GatewayParentReference struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. Defaults to the namespace of Mattermost.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the Gateway listener.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
 // IngressHost specifies additional hosts configuration.
 type IngressHost struct {
 	HostName string `json:"hostName,omitempty"`
@@ -295,6 +458,10 @@ type UpdateJob struct {
 	// Overrides what is set in ResourceLabels, does not override default label (app label).
 	// +optional
 	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// Determines whether the update job verifies changed Secrets and
+	// ConfigMaps used by Mattermost before the pods are restarted to use them.
+	// +optional
+	OnConfigChange bool `json:"onConfigChange,omitempty"`
 }
 
 // JobServer defines configuration for the Mattermost job server.
@@ -307,6 +474,345 @@ type JobServer struct {
 	DedicatedJobServer bool `json:"dedicatedJobServer,omitempty"`
 }
 
+// PodDisruptionBudget defines configuration for the PodDisruptionBudget of
+// Mattermost pods. The PodDisruptionBudget is not created for deployments
+// running a single replica.
+type PodDisruptionBudget struct {
+	// Determines whether to disable the Operator's creation of the
+	// PodDisruptionBudget.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// MinAvailable defines the number or percentage of Mattermost pods that
+	// must remain available during voluntary disruptions.
+	// If neither MinAvailable nor MaxUnavailable is set, half of the replicas,
+	// rounded up, must remain available.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable defines the number or percentage of Mattermost pods that
+	// can be unavailable during voluntary disruptions. Can not be set
+	// together with MinAvailable.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// Autoscaling defines configuration for the HorizontalPodAutoscaler of the
+// Mattermost deployment.
+type Autoscaling struct {
+	// Enabled determines whether the Operator creates the HorizontalPodAutoscaler.
+	// While autoscaling is enabled, the number of replicas of the Mattermost
+	// deployment is managed by the HorizontalPodAutoscaler instead of Replicas.
+	// Setting Replicas to 0 suspends autoscaling and removes the
+	// HorizontalPodAutoscaler until Replicas is changed again.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// MinReplicas defines the lower limit for the number of replicas.
+	// Defaults to Replicas.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas defines the upper limit for the number of replicas.
+	// Required when autoscaling is enabled.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// TargetCPUUtilizationPercentage defines the target average CPU
+	// utilization of Mattermost pods, relative to their CPU requests.
+	// Defaults to 80 if no other target is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// TargetMemoryUtilizationPercentage defines the target average memory
+	// utilization of Mattermost pods, relative to their memory requests.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// CustomMetric defines a target for a metric exported by Mattermost on
+	// the metrics port. The metric has to be served by the custom metrics API,
+	// for example by the Prometheus Adapter.
+	// +optional
+	CustomMetric *AutoscalingMetric `json:"customMetric,omitempty"`
+}
+
+// AutoscalingMetric defines the target for a custom metric of Mattermost pods.
+type AutoscalingMetric struct {
+	// Name of the metric in the custom metrics API.
+	Name string `json:"name"`
+	// TargetAverageValue defines the target value of the metric averaged
+	// across Mattermost pods.
+	TargetAverageValue resource.Quantity `json:"targetAverageValue"`
+}
+
+// NetworkPolicy restricts the traffic of Mattermost pods to the ingress
+// controller, monitoring, other pods of the installation and its database and
+// file store.
+// Any other egress, for example to the plugin Marketplace, the push
+// notification service, SMTP servers or outgoing webhooks, is blocked unless
+// allowed with EgressCIDRs or AdditionalEgress.
+type NetworkPolicy struct {
+	// Enabled defines if the NetworkPolicy should be created.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// IngressNamespaceSelector selects the namespaces of the ingress
+	// controller allowed to send requests to Mattermost.
+	// Defaults to the `ingress-nginx` namespace.
+	// +optional
+	IngressNamespaceSelector *metav1.LabelSelector `json:"ingressNamespaceSelector,omitempty"`
+	// IngressPodSelector selects the ingress controller pods allowed to send
+	// requests to Mattermost.
+	// +optional
+	IngressPodSelector *metav1.LabelSelector `json:"ingressPodSelector,omitempty"`
+	// MonitoringNamespaceSelector selects the namespaces allowed to scrape
+	// Mattermost metrics.
+	// Defaults to the `monitoring` namespace.
+	// +optional
+	MonitoringNamespaceSelector *metav1.LabelSelector `json:"monitoringNamespaceSelector,omitempty"`
+	// MonitoringPodSelector selects the pods allowed to scrape Mattermost
+	// metrics.
+	// +optional
+	MonitoringPodSelector *metav1.LabelSelector `json:"monitoringPodSelector,omitempty"`
+	// EgressCIDRs lists the CIDRs Mattermost pods are allowed to connect to,
+	// such as the ones of the external database or S3 compatible file store.
+	// +optional
+	EgressCIDRs []string `json:"egressCIDRs,omitempty"`
+	// AdditionalEgress lists egress rules appended to the NetworkPolicy, for
+	// example to allow HTTPS for plugin downloads and push notifications or
+	// the port of the SMTP server.
+	// +optional
+	AdditionalEgress []networkingv1.NetworkPolicyEgressRule `json:"additionalEgress,omitempty"`
+}
+
+// Monitoring defines configuration of Mattermost metrics.
+type Monitoring struct {
+	// MetricsDisabled disables the Mattermost metrics server as well as the
+	// Prometheus scrape annotations of Mattermost pods.
+	// +optional
+	MetricsDisabled bool `json:"metricsDisabled,omitempty"`
+	// ServiceMonitor defines configuration of the Prometheus Operator
+	// monitors scraping Mattermost metrics.
+	// +optional
+	ServiceMonitor *ServiceMonitor `json:"serviceMonitor,omitempty"`
+}
+
+// ServiceMonitor defines the Prometheus Operator ServiceMonitor scraping the
+// Mattermost pods and the PodMonitor scraping the dedicated job server pods.
+type ServiceMonitor struct {
+	// Enabled defines if the monitors should be created. They are only created
+	// if the Prometheus Operator CRDs are installed in the cluster.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Labels added to the monitors, for example to match the monitor
+	// selector of the Prometheus instance.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Interval at which metrics are scraped, for example `30s`. Defaults to
+	// the scrape interval of the Prometheus instance.
+	// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Relabelings applied to the scraped targets.
+	// +optional
+	Relabelings []RelabelConfig `json:"relabelings,omitempty"`
+}
+
+// RelabelConfig defines a Prometheus relabeling step.
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#relabel_config.
+type RelabelConfig struct {
+	// SourceLabels select values from existing labels.
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	// Separator placed between concatenated source label values.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+	// TargetLabel to which the resulting value is written.
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// Regex against which the extracted value is matched.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+	// Replacement value used if the regex matches.
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+	// Action to perform based on the regex matching.
+	// +kubebuilder:validation:Enum=replace;keep;drop;hashmod;labelmap;labeldrop;labelkeep
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// RolloutStrategyType defines how new Mattermost images are rolled out.
+type RolloutStrategyType string
+
+const (
+	// RolloutStrategyRollingUpdate updates the Mattermost deployment in place.
+	RolloutStrategyRollingUpdate RolloutStrategyType = "RollingUpdate"
+	// RolloutStrategyCanary routes a share of the traffic to a small canary
+	// deployment running the new image before promoting it.
+	RolloutStrategyCanary RolloutStrategyType = "Canary"
+	// RolloutStrategyBlueGreen brings up a full size preview deployment
+	// running the new image and switches all traffic to it at once.
+	RolloutStrategyBlueGreen RolloutStrategyType = "BlueGreen"
+)
+
+// Rollout defines the strategy used to roll out new Mattermost images.
+type Rollout struct {
+	// Strategy used when the Mattermost image changes.
+	// Accepted values are: "RollingUpdate", "Canary" or "BlueGreen". Default is RollingUpdate.
+	// Canary and BlueGreen strategies route the traffic with the NGINX Ingress
+	// created by the Operator.
+	// +kubebuilder:validation:Enum=RollingUpdate;Canary;BlueGreen
+	// +optional
+	Strategy RolloutStrategyType `json:"strategy,omitempty"`
+	// Canary defines the configuration of the Canary strategy.
+	// +optional
+	Canary *CanaryRollout `json:"canary,omitempty"`
+	// BlueGreen defines the configuration of the BlueGreen strategy.
+	// +optional
+	BlueGreen *BlueGreenRollout `json:"blueGreen,omitempty"`
+	// AnalysisSeconds defines how long the new image has to stay healthy
+	// before it is promoted. Default is 300.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AnalysisSeconds *int32 `json:"analysisSeconds,omitempty"`
+	// ProgressDeadlineSeconds defines how long the new image can take to
+	// become healthy before the rollout is aborted. With the RollingUpdate
+	// strategy the deployment is rolled back to the last known good image.
+	// Default is 600.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// CanaryRollout defines the configuration of the Canary strategy.
+type CanaryRollout struct {
+	// Replicas defines the number of replicas of the canary deployment. Default is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Weight defines the percentage of requests routed to the canary. Default is 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+	// Cookie defines the name of the cookie selecting the canary. Requests
+	// with the cookie set to "always" are always routed to the canary and
+	// requests with the cookie set to "never" are never routed to it,
+	// regardless of the weight.
+	// +optional
+	Cookie string `json:"cookie,omitempty"`
+}
+
+// BlueGreenRollout defines the configuration of the BlueGreen strategy.
+type BlueGreenRollout struct {
+	// PreviewCookie defines the name of the cookie routing requests to the
+	// preview deployment when set to "always". Default is "mattermost-preview".
+	// +optional
+	PreviewCookie string `json:"previewCookie,omitempty"`
+}
+
+// MaintenanceWindow defines recurring windows in which image changes are
+// applied.
+type MaintenanceWindow struct {
+	// Schedule is a cron expression for the start of the windows, in the
+	// "minute hour day-of-month month day-of-week" format.
+	// For example "0 2 * * 6" starts a window every Saturday at 2:00.
+	Schedule string `json:"schedule"`
+	// Duration of each window.
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone of the schedule as an IANA time zone name, for example
+	// "Europe/Berlin". Default is UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// UpgradePath defines how upgrades skipping major versions are handled.
+type UpgradePath struct {
+	// Enabled upgrades installations skipping major versions one major
+	// version at a time. Each intermediate version is verified by the update
+	// job and rolled out before the next one.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// IntermediateVersions lists the versions used as intermediate steps,
+	// the highest listed version of each major version is used. The first
+	// release, <major>.0.0, is used for major versions not listed.
+	// +optional
+	IntermediateVersions []string `json:"intermediateVersions,omitempty"`
+}
+
+// ImageDigestPinning defines how the Mattermost image tag is pinned to a
+// digest.
+type ImageDigestPinning struct {
+	// Enabled resolves the image tag to a digest. Registries requiring
+	// authentication are accessed with the image pull secrets.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// RefreshInterval between resolutions of the tag. Default is 1h.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// Plugin defines a Mattermost plugin installed by the Operator. Exactly one
+// source of the plugin bundle has to be set.
+type Plugin struct {
+	// ID of the plugin, as in the manifest of the plugin.
+	ID string `json:"id"`
+	// URL the plugin bundle is downloaded from.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// ConfigMap key holding the plugin bundle in its binary data.
+	// +optional
+	ConfigMap *v1.ConfigMapKeySelector `json:"configMap,omitempty"`
+	// Image holding the plugin bundle.
+	// +optional
+	Image *PluginImage `json:"image,omitempty"`
+	// Checksum is the SHA-256 checksum of the plugin bundle. The plugin is
+	// not installed if the bundle does not match it. Required for plugins
+	// downloaded from a URL.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// Enabled activates the plugin. Default is true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Config of the plugin, as in PluginSettings.Plugins of the Mattermost
+	// configuration.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Config *runtime.RawExtension `json:"config,omitempty"`
+}
+
+// PluginImage defines a container image holding a plugin bundle.
+type PluginImage struct {
+	// Name of the image. The image has to provide the cp command.
+	Name string `json:"name"`
+	// Path of the plugin bundle in the image.
+	Path string `json:"path"`
+}
+
+// DeletionPolicy defines what happens to the data of the installation when
+// the Mattermost is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the operator-managed database and file
+	// store together with the installation.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the operator-managed database and file store
+	// after the installation is deleted.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicySnapshot backs up the database and the file store before
+	// the installation is deleted.
+	DeletionPolicySnapshot DeletionPolicy = "Snapshot"
+)
+
+// DeletionSnapshot defines the final backup taken before the installation is
+// deleted.
+type DeletionSnapshot struct {
+	// Destination defines the S3-compatible bucket the backup is stored in.
+	Destination BackupDestination `json:"destination"`
+	// DatabaseImage overrides the database client image used to dump the
+	// database.
+	// +optional
+	DatabaseImage string `json:"databaseImage,omitempty"`
+}
+
 // PodExtensions specify customized extensions for a pod.
 type PodExtensions struct {
 	// Additional InitContainers injected into pods.
@@ -343,9 +849,9 @@ type Database struct {
 	// ReadinessCheck configures *how* the readiness init container is built.
 	// When nil (default), the Operator uses the legacy "external" mode
 	// (postgres:13 / appropriate/curl images). Ignored when
-	// DisableReadinessCheck is true. Currently only consulted for the
+	// DisableReadinessCheck is true. The mode is only consulted for the
 	// external-database path; operator-managed databases always use the
-	// legacy probe.
+	// legacy probe, with the image configured here.
 	// +optional
 	ReadinessCheck *DatabaseReadinessCheck `json:"readinessCheck,omitempty"`
 }
@@ -395,6 +901,12 @@ type DatabaseReadinessCheck struct {
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Image of the readiness init container of operator-managed PostgreSQL
+	// databases, which has to provide pg_isready. Defaults to the PostgreSQL
+	// image of the provisioned database.
+	// +optional
+	Image string `json:"image,omitempty"`
 }
 
 // ExternalDatabase defines the configuration of the external database that should be used by Mattermost.
@@ -412,6 +924,8 @@ type ExternalDatabase struct {
 // OperatorManagedDatabase defines the configuration of a database managed by Kubernetes Operator.
 type OperatorManagedDatabase struct {
 	// Defines the type of database to use for an Operator-Managed database.
+	// Supported types are 'mysql', provisioned with the MySQL Operator, and
+	// 'postgres', provisioned with the CloudNativePG operator.
 	Type string `json:"type,omitempty"`
 	// Defines the storage size for the database. ie 50Gi
 	// +optional
@@ -444,7 +958,8 @@ type OperatorManagedDatabase struct {
 	// Defines the secret to be used when performing a database restore.
 	// +optional
 	BackupRestoreSecretName string `json:"backupRestoreSecretName,omitempty"`
-	// Defines the cluster version for the database to use
+	// Defines the cluster version for the database to use.
+	// For 'postgres' it is the PostgreSQL major version, ie 16.
 	// +optional
 	Version string `json:"version,omitempty"`
 }
@@ -522,12 +1037,56 @@ type LocalFileStore struct {
 // ElasticSearch defines the ElasticSearch configuration for Mattermost.
 type ElasticSearch struct {
 	Host string `json:"host,omitempty"`
+	// Deprecated: Use Secret.
 	// +optional
 	UserName string `json:"username,omitempty"`
+	// Deprecated: Use Secret.
 	// +optional
 	Password string `json:"password,omitempty"`
+
+	// Secret is the name of a Kubernetes secret containing the `username`
+	// and `password` used to connect to ElasticSearch. It takes precedence
+	// over UserName and Password.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// CASecret is the name of a Kubernetes secret containing the `ca.crt`
+	// bundle used to verify the ElasticSearch certificate.
+	// +optional
+	CASecret string `json:"caSecret,omitempty"`
+
+	// Backend is the search engine used by Mattermost. Defaults to
+	// `elasticsearch`.
+	// +kubebuilder:validation:Enum=elasticsearch;opensearch
+	// +optional
+	Backend ElasticSearchBackend `json:"backend,omitempty"`
+
+	// IndexPrefix is prepended to the names of all indexes.
+	// +optional
+	IndexPrefix string `json:"indexPrefix,omitempty"`
+
+	// Shards is the number of shards of the post, channel and user indexes.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Shards *int32 `json:"shards,omitempty"`
+
+	// Replicas is the number of replicas of the post, channel and user
+	// indexes.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
 }
 
+// ElasticSearchBackend is the search engine used by Mattermost.
+type ElasticSearchBackend string
+
+const (
+	// ElasticSearchBackendElasticsearch uses Elasticsearch.
+	ElasticSearchBackendElasticsearch ElasticSearchBackend = "elasticsearch"
+	// ElasticSearchBackendOpenSearch uses OpenSearch.
+	ElasticSearchBackendOpenSearch ElasticSearchBackend = "opensearch"
+)
+
 // RunningState is the state of the Mattermost instance
 type RunningState string
 
@@ -544,6 +1103,9 @@ const (
 	Ready RunningState = "ready"
 	// Stable is the state when the Mattermost instance is fully running
 	Stable RunningState = "stable"
+	// Deleting is the state when the Mattermost instance is being deleted and
+	// its data is retained or backed up according to the deletion policy.
+	Deleting RunningState = "deleting"
 )
 
 // MattermostStatus defines the observed state of Mattermost
@@ -575,6 +1137,138 @@ type MattermostStatus struct {
 	Error string `json:"error,omitempty"`
 	// Status of specified resource patches.
 	ResourcePatch *ResourcePatchStatus `json:"resourcePatch,omitempty"`
+	// The last Mattermost image which was fully rolled out. The deployment
+	// is rolled back to this image if a new image fails to roll out.
+	// +optional
+	LastGoodImage string `json:"lastGoodImage,omitempty"`
+	// Progress of the last rollout of a new Mattermost image.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// Image change waiting for the next maintenance window.
+	// +optional
+	PendingUpgrade *PendingUpgrade `json:"pendingUpgrade,omitempty"`
+	// Start of the next maintenance window.
+	// +optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+	// Digest the image tag is pinned to.
+	// +optional
+	ImageDigest *ImageDigestStatus `json:"imageDigest,omitempty"`
+	// Status of the plugins in the spec.
+	// +optional
+	Plugins []PluginStatus `json:"plugins,omitempty"`
+	// Expiration time of the TLS certificate issued by cert-manager.
+	// +optional
+	CertificateNotAfter *metav1.Time `json:"certificateNotAfter,omitempty"`
+	// Conditions represent the latest available observations of the
+	// Mattermost instance reconciliation stages.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// RolloutPhase is the phase of a rollout.
+type RolloutPhase string
+
+const (
+	// RolloutProgressing is the phase when the new image is rolled out. Canary
+	// and BlueGreen rollouts analyse the new image next to the stable one.
+	RolloutProgressing RolloutPhase = "Progressing"
+	// RolloutPromoting is the phase when all traffic is routed to the new
+	// image while the Mattermost deployment is updated.
+	RolloutPromoting RolloutPhase = "Promoting"
+	// RolloutSucceeded is the phase when the new image was promoted.
+	RolloutSucceeded RolloutPhase = "Succeeded"
+	// RolloutAborted is the phase when the new image was not healthy and
+	// the stable image is kept. The image is not rolled out again until the
+	// spec changes.
+	RolloutAborted RolloutPhase = "Aborted"
+)
+
+// RolloutStatus defines the observed state of a rollout.
+type RolloutStatus struct {
+	// Strategy used by the rollout.
+	Strategy RolloutStrategyType `json:"strategy,omitempty"`
+	// Phase of the rollout.
+	Phase RolloutPhase `json:"phase,omitempty"`
+	// Image being rolled out.
+	Image string `json:"image,omitempty"`
+	// Image running before the rollout started.
+	StableImage string `json:"stableImage,omitempty"`
+	// Name of the deployment running the new image during Canary or
+	// BlueGreen rollout.
+	// +optional
+	Deployment string `json:"deployment,omitempty"`
+	// Percentage of requests routed to the new image.
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+	// Number of replicas of the deployment running the new image.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// Number of available replicas of the deployment running the new image.
+	// +optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+	// Time when the rollout started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time since when the new image is healthy.
+	// +optional
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+	// Time when the rollout was promoted or aborted.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Generation of the Mattermost resource when the rollout was aborted.
+	// The image is rolled out again once the generation changes.
+	// +optional
+	AbortedGeneration int64 `json:"abortedGeneration,omitempty"`
+	// Human readable description of the rollout progress.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PendingUpgrade defines an image change deferred until the next
+// maintenance window.
+type PendingUpgrade struct {
+	// Image waiting to be rolled out.
+	Image string `json:"image"`
+	// Image running until the upgrade is applied.
+	CurrentImage string `json:"currentImage"`
+	// Time when the image change was deferred.
+	Since metav1.Time `json:"since"`
+}
+
+// ImageDigestStatus defines the digest an image tag was resolved to.
+type ImageDigestStatus struct {
+	// Image resolved to the digest.
+	Image string `json:"image"`
+	// Digest of the image.
+	Digest string `json:"digest"`
+	// Time when the tag was last resolved.
+	ResolvedTime metav1.Time `json:"resolvedTime"`
+}
+
+// PluginStatus defines the observed state of a plugin.
+type PluginStatus struct {
+	// ID of the plugin.
+	ID string `json:"id"`
+	// Source of the plugin bundle.
+	// +optional
+	Source string `json:"source,omitempty"`
+	// Checksum of the plugin bundle verified when it was staged.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// Configured is true once all Mattermost pods run with the plugin bundle
+	// and its settings. The actual state of the plugin in Mattermost is not
+	// queried.
+	Configured bool `json:"configured"`
+	// ConfiguredEnabled is true once the plugin is configured and set to be
+	// enabled.
+	ConfiguredEnabled bool `json:"configuredEnabled"`
+	// Human readable description of the plugin state.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // ResourcePatchStatus defines status of ResourcePatch
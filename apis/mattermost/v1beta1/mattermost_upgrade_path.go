@@ -0,0 +1,44 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+)
+
+// AllowUnsupportedUpgradeAnnotation allows a downgrade or an upgrade skipping
+// major versions when set to the version in the spec.
+const AllowUnsupportedUpgradeAnnotation = "installation.mattermost.com/allow-unsupported-upgrade"
+
+// UnsupportedUpgradeAllowed returns true if the version in the spec is rolled
+// out even if the upgrade path to it is not supported.
+func (mm *Mattermost) UnsupportedUpgradeAllowed() bool {
+	return mm.Spec.Version != "" && mm.Annotations[AllowUnsupportedUpgradeAnnotation] == mm.Spec.Version
+}
+
+// UpgradePathEnabled returns true if upgrades skipping major versions are
+// walked one major version at a time.
+func (mm *Mattermost) UpgradePathEnabled() bool {
+	return mm.Spec.UpgradePath != nil && mm.Spec.UpgradePath.Enabled
+}
+
+// IntermediateVersion returns the version used as the intermediate step of
+// the major version.
+func (p *UpgradePath) IntermediateVersion(major int) string {
+	intermediate := fmt.Sprintf("%d.0.0", major)
+	var highest *utils.Version
+	for _, value := range p.IntermediateVersions {
+		version, err := utils.ParseVersion(value)
+		if err != nil || version.Major != major {
+			continue
+		}
+		if highest == nil || version.Compare(*highest) > 0 {
+			highest = &version
+			intermediate = value
+		}
+	}
+	return intermediate
+}
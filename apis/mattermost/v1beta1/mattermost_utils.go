@@ -23,7 +23,11 @@ const (
 	// DefaultMattermostSize is the default number of users
 	DefaultMattermostSize = "5000users"
 	// DefaultMattermostDatabaseType is the default Mattermost database
-	DefaultMattermostDatabaseType = "mysql"
+	DefaultMattermostDatabaseType = DatabaseTypeMySQL
+	// DatabaseTypeMySQL is the type of MySQL database managed by the MySQL Operator
+	DatabaseTypeMySQL = "mysql"
+	// DatabaseTypePostgres is the type of PostgreSQL database managed by CloudNativePG
+	DatabaseTypePostgres = "postgres"
 	// DefaultFilestoreStorageSize is the default Storage size for Minio or Local Storage
 	DefaultFilestoreStorageSize = "50Gi"
 	// DefaultStorageSize is the default Storage size for the Database
@@ -34,6 +38,14 @@ const (
 	DefaultLocalFilePath = "/mattermost/data"
 	// DefaultDatabaseVersion
 	DefaultDatabaseVersion = "8.0"
+	// DefaultPostgresDatabaseVersion is the default PostgreSQL major version
+	DefaultPostgresDatabaseVersion = "16"
+	// PostgresImageRepository is the repository of the PostgreSQL images
+	// maintained by the CloudNativePG project.
+	PostgresImageRepository = "ghcr.io/cloudnative-pg/postgresql"
+	// DefaultPluginStagingImage is the default image of the init container
+	// staging the plugin bundles.
+	DefaultPluginStagingImage = "curlimages/curl:8.11.1"
 
 	// ClusterLabel is the label applied across all components
 	ClusterLabel = "installation.mattermost.com/installation"
@@ -42,6 +54,14 @@ const (
 	// as well as all other resources created to support it.
 	ClusterResourceLabel = "installation.mattermost.com/resource"
 
+	// MattermostFinalizer is set on Mattermost installations whose data has
+	// to be retained or backed up before they are deleted.
+	MattermostFinalizer = "installation.mattermost.com/finalizer"
+
+	// ConfirmDeletionAnnotation confirms the deletion of a Mattermost with
+	// deletion protection when set to the UID of the Mattermost.
+	ConfirmDeletionAnnotation = "installation.mattermost.com/confirm-deletion"
+
 	// MattermostAppContainerName is the name of the container which runs the
 	// Mattermost application
 	MattermostAppContainerName = "mattermost"
@@ -104,12 +124,34 @@ func validateVolumes(volumes []corev1.Volume) error {
 
 // IngressEnabled determines whether Mattermost Ingress should be created.
 func (mm *Mattermost) IngressEnabled() bool {
+	// HTTPRoute replaces the Ingress.
+	if mm.GatewayEnabled() {
+		return false
+	}
 	if mm.Spec.Ingress != nil {
 		return mm.Spec.Ingress.Enabled
 	}
 	return true
 }
 
+// GatewayEnabled returns true if Mattermost is exposed through a Gateway API
+// HTTPRoute.
+func (mm *Mattermost) GatewayEnabled() bool {
+	return mm.Spec.Gateway != nil && mm.Spec.Gateway.Enabled
+}
+
+// CertManagerEnabled returns true if the TLS certificate of the Ingress or
+// HTTPRoute hosts is issued by cert-manager.
+func (mm *Mattermost) CertManagerEnabled() bool {
+	if mm.Spec.Ingress == nil || mm.Spec.Ingress.CertManager == nil {
+		return false
+	}
+	if mm.Spec.UseServiceLoadBalancer || mm.AWSLoadBalancerEnabled() {
+		return false
+	}
+	return mm.IngressEnabled() || mm.GatewayEnabled()
+}
+
 func (mm *Mattermost) AWSLoadBalancerEnabled() bool {
 	if mm.Spec.AWSLoadBalancerController != nil {
 		return mm.Spec.AWSLoadBalancerController.Enabled
@@ -184,6 +226,9 @@ func (mm *Mattermost) GetAWSLoadBalancerIngressAnnotations() map[string]string {
 // GetIngressTLSSecret returns Mattermost Ingress TLS secret.
 func (mm *Mattermost) GetIngressTLSSecret() string {
 	if mm.Spec.Ingress != nil {
+		if mm.Spec.Ingress.TLSSecret == "" && mm.CertManagerEnabled() {
+			return defaultTLSSecret(mm)
+		}
 		return mm.Spec.Ingress.TLSSecret
 	}
 	if mm.Spec.UseIngressTLS {
@@ -259,8 +304,14 @@ var mutableImageTags = map[string]bool{
 // configuration. It warns when mutable tags are used and recommends
 // digest-pinned references for supply-chain safety.
 // The GetImageName() method already supports digest format (image@sha256:...)
-// which is the recommended approach for production deployments.
+// which is the recommended approach for production deployments. No warnings
+// are returned if image digest pinning is enabled.
 func (mm *Mattermost) ImageTagWarnings() []string {
+	// Pinned tags are run with the same digest on all pods.
+	if mm.ImageDigestPinningEnabled() {
+		return nil
+	}
+
 	var warnings []string
 
 	version := mm.Spec.Version
@@ -279,6 +330,25 @@ func (mm *Mattermost) ImageTagWarnings() []string {
 	return warnings
 }
 
+// UpdateJobOnConfigChange returns true if the update job verifies changed
+// Secrets and ConfigMaps before the pods are restarted.
+func (mm *Mattermost) UpdateJobOnConfigChange() bool {
+	return mm.Spec.UpdateJob != nil && !mm.Spec.UpdateJob.Disabled && mm.Spec.UpdateJob.OnConfigChange
+}
+
+// ElasticSearchWarnings returns a list of warnings about deprecated
+// ElasticSearch settings.
+func (mm *Mattermost) ElasticSearchWarnings() []string {
+	es := mm.Spec.ElasticSearch
+	if es.UserName == "" && es.Password == "" {
+		return nil
+	}
+	if es.Secret != "" {
+		return []string{"spec.elasticSearch.username and spec.elasticSearch.password are ignored as spec.elasticSearch.secret is set"}
+	}
+	return []string{"spec.elasticSearch.username and spec.elasticSearch.password are deprecated; store the credentials in a secret referenced by spec.elasticSearch.secret"}
+}
+
 // GetProductionDeploymentName returns the name of the deployment that is
 // currently designated as production.
 func (mm *Mattermost) GetProductionDeploymentName() string {
@@ -367,6 +437,96 @@ func MattermostResourceLabels(name string) map[string]string {
 	return map[string]string{ClusterResourceLabel: name}
 }
 
+// PodDisruptionBudgetEnabled returns true if the PodDisruptionBudget should be
+// created for a deployment with the given number of replicas.
+func (mm *Mattermost) PodDisruptionBudgetEnabled(replicas int32) bool {
+	if mm.Spec.PodDisruptionBudget != nil && mm.Spec.PodDisruptionBudget.Disabled {
+		return false
+	}
+	return replicas > 1
+}
+
+// AutoscalingEnabled returns true if the number of replicas is managed by
+// the HorizontalPodAutoscaler. Autoscaling is suspended while Replicas is
+// explicitly set to 0, for example when Mattermost is scaled down for a
+// database migration or restore.
+func (mm *Mattermost) AutoscalingEnabled() bool {
+	if mm.Spec.Replicas != nil && *mm.Spec.Replicas == 0 {
+		return false
+	}
+	return mm.Spec.Autoscaling != nil && mm.Spec.Autoscaling.Enabled
+}
+
+// MetricsEnabled returns true if the Mattermost metrics server is enabled.
+func (mm *Mattermost) MetricsEnabled() bool {
+	return mm.Spec.Monitoring == nil || !mm.Spec.Monitoring.MetricsDisabled
+}
+
+// ServiceMonitorEnabled returns true if the Prometheus Operator monitors
+// should be created.
+func (mm *Mattermost) ServiceMonitorEnabled() bool {
+	return mm.MetricsEnabled() &&
+		mm.Spec.Monitoring != nil &&
+		mm.Spec.Monitoring.ServiceMonitor != nil &&
+		mm.Spec.Monitoring.ServiceMonitor.Enabled
+}
+
+// NetworkPolicyEnabled returns true if the NetworkPolicy should be created.
+func (mm *Mattermost) NetworkPolicyEnabled() bool {
+	return mm.Spec.NetworkPolicy != nil && mm.Spec.NetworkPolicy.Enabled
+}
+
+// GetDeletionPolicy returns the deletion policy of the installation.
+func (mm *Mattermost) GetDeletionPolicy() DeletionPolicy {
+	if mm.Spec.DeletionPolicy == "" {
+		return DeletionPolicyDelete
+	}
+	return mm.Spec.DeletionPolicy
+}
+
+// IsRolledOut returns true if the health check reports the installation
+// stable with the image and the version of its current spec.
+func (mm *Mattermost) IsRolledOut() bool {
+	return mm.Status.State == Stable &&
+		mm.Status.ObservedGeneration == mm.Generation &&
+		mm.Status.Image == mm.Spec.Image &&
+		mm.Status.Version == mm.Spec.Version
+}
+
+// DeletionAllowed returns true if the Mattermost is not protected from
+// deletion or its deletion is confirmed with the annotation.
+func (mm *Mattermost) DeletionAllowed() bool {
+	if !mm.Spec.DeletionProtection {
+		return true
+	}
+	return mm.UID != "" && mm.Annotations[ConfirmDeletionAnnotation] == string(mm.UID)
+}
+
+// DeletionProtectedMessage explains how to delete the Mattermost protected
+// from deletion.
+func (mm *Mattermost) DeletionProtectedMessage() string {
+	return fmt.Sprintf("Mattermost %s/%s is protected from deletion, set spec.deletionProtection to false or annotate it with %s=%s to delete it",
+		mm.Namespace, mm.Name, ConfirmDeletionAnnotation, mm.UID)
+}
+
+// DeletionSnapshotName returns the name of the MattermostBackup taken before
+// the installation is deleted.
+func (mm *Mattermost) DeletionSnapshotName() string {
+	return mm.Name + "-deletion-snapshot"
+}
+
+// GetAutoscalingMinReplicas returns the lower limit for the number of
+// replicas set by the HorizontalPodAutoscaler.
+func (mm *Mattermost) GetAutoscalingMinReplicas() int32 {
+	if mm.Spec.Autoscaling != nil && mm.Spec.Autoscaling.MinReplicas != nil {
+		return *mm.Spec.Autoscaling.MinReplicas
+	}
+	if mm.Spec.Replicas != nil && *mm.Spec.Replicas > 0 {
+		return *mm.Spec.Replicas
+	}
+	return 1
+}
+
 // DedicatedJobServerName returns the standard name for the optional
 // dedicated Mattermost job server.
 func (mm *Mattermost) DedicatedJobServerName() string {
@@ -2,11 +2,13 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
 	pkgUtils "github.com/mattermost/mattermost-operator/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestMattermost_SetDefaults(t *testing.T) {
@@ -352,3 +354,94 @@ func TestMattermost_GetIngressHostNames(t *testing.T) {
 		})
 	}
 }
+
+func TestMaintenanceWindow_Check(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Every Saturday from 2:00 to 6:00 Berlin time.
+	window := &MaintenanceWindow{
+		Schedule: "0 2 * * 6",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Berlin",
+	}
+	nextWindow := time.Date(2026, 3, 14, 2, 0, 0, 0, berlin)
+
+	for _, testCase := range []struct {
+		description string
+		time        time.Time
+		inWindow    bool
+		next        time.Time
+	}{
+		{
+			description: "before the window",
+			time:        time.Date(2026, 3, 6, 23, 0, 0, 0, time.UTC),
+			next:        time.Date(2026, 3, 7, 2, 0, 0, 0, berlin),
+		},
+		{
+			description: "window start",
+			time:        time.Date(2026, 3, 7, 1, 0, 0, 0, time.UTC),
+			inWindow:    true,
+			next:        nextWindow,
+		},
+		{
+			description: "within the window",
+			time:        time.Date(2026, 3, 7, 4, 59, 59, 0, time.UTC),
+			inWindow:    true,
+			next:        nextWindow,
+		},
+		{
+			description: "window end",
+			time:        time.Date(2026, 3, 7, 5, 0, 0, 0, time.UTC),
+			next:        nextWindow,
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			inWindow, next, err := window.Check(testCase.time)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.inWindow, inWindow)
+			assert.True(t, testCase.next.Equal(next), "expected next window %s, got %s", testCase.next, next)
+		})
+	}
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		_, _, err := (&MaintenanceWindow{Schedule: "0 2 * * 6", TimeZone: "Mars/Olympus"}).Check(time.Now())
+		require.Error(t, err)
+	})
+}
+
+func TestMattermost_UpgradeForced(t *testing.T) {
+	mm := &Mattermost{}
+	assert.False(t, mm.UpgradeForced("mattermost/mattermost-enterprise-edition:10.5.0"))
+
+	mm.Annotations = map[string]string{ForceUpgradeAnnotation: "mattermost/mattermost-enterprise-edition:10.5.0"}
+	assert.True(t, mm.UpgradeForced("mattermost/mattermost-enterprise-edition:10.5.0"))
+	assert.False(t, mm.UpgradeForced("mattermost/mattermost-enterprise-edition:10.6.0"))
+}
+
+func TestUpgradePath_IntermediateVersion(t *testing.T) {
+	path := &UpgradePath{IntermediateVersions: []string{"9.5.12", "9.11.6", "8.1.13"}}
+
+	assert.Equal(t, "9.11.6", path.IntermediateVersion(9))
+	assert.Equal(t, "8.1.13", path.IntermediateVersion(8))
+	assert.Equal(t, "7.0.0", path.IntermediateVersion(7))
+}
+
+func TestMattermost_GetPinnedImageName(t *testing.T) {
+	digest := "sha256:dd15a51ac7dafd213744d1ef23394e7532f71a90f477c969b94600e46da5a0cf"
+	mm := &Mattermost{Spec: MattermostSpec{Image: "mattermost/mattermost-enterprise-edition", Version: "latest"}}
+	mm.Status.ImageDigest = &ImageDigestStatus{Image: "mattermost/mattermost-enterprise-edition:latest", Digest: digest}
+	assert.Equal(t, "mattermost/mattermost-enterprise-edition:latest", mm.GetPinnedImageName())
+	assert.NotEmpty(t, mm.ImageTagWarnings())
+
+	mm.Spec.ImageDigestPinning = &ImageDigestPinning{Enabled: true}
+	assert.Equal(t, "mattermost/mattermost-enterprise-edition:latest@"+digest, mm.GetPinnedImageName())
+	assert.Empty(t, mm.ImageTagWarnings())
+	assert.Equal(t, DefaultImageDigestRefreshInterval, mm.Spec.ImageDigestPinning.GetRefreshInterval())
+
+	mm.Spec.Version = "10.5.2"
+	assert.Equal(t, "mattermost/mattermost-enterprise-edition:10.5.2", mm.GetPinnedImageName(), "digest of other image is not used")
+
+	mm.Spec.Version = digest
+	assert.False(t, mm.ImageDigestPinningEnabled())
+}
@@ -0,0 +1,511 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	mattermostv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var mattermostlog = logf.Log.WithName("mattermost-webhook")
+
+var (
+	// pluginIDRegexp matches valid plugin IDs, as enforced by Mattermost.
+	pluginIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]{3,190}$`)
+	// checksumRegexp matches hex encoded SHA-256 checksums.
+	checksumRegexp = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+)
+
+// SetupWebhookWithManager registers the defaulting and validating admission
+// webhooks for Mattermost with the manager.
+func (mm *Mattermost) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(mm).
+		WithDefaulter(&mattermostDefaulter{}).
+		WithValidator(&mattermostValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-installation-mattermost-com-v1beta1-mattermost,mutating=true,failurePolicy=fail,sideEffects=None,groups=installation.mattermost.com,resources=mattermosts,verbs=create;update,versions=v1beta1,name=mmattermost.installation.mattermost.com,admissionReviewVersions=v1
+
+// mattermostDefaulter sets default values on Mattermost objects before they
+// are persisted.
+type mattermostDefaulter struct{}
+
+var _ admission.CustomDefaulter = &mattermostDefaulter{}
+
+// Default implements admission.CustomDefaulter.
+func (d *mattermostDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	mm, ok := obj.(*Mattermost)
+	if !ok {
+		return fmt.Errorf("expected a Mattermost object but got %T", obj)
+	}
+
+	// The reconciler can not handle objects rejected by SetDefaults, report
+	// the detailed validation errors when possible.
+	if err := mm.SetDefaults(); err != nil {
+		if validationErr := mm.validate(); validationErr != nil {
+			return validationErr
+		}
+		return apierrors.NewBadRequest(err.Error())
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-installation-mattermost-com-v1beta1-mattermost,mutating=false,failurePolicy=fail,sideEffects=None,groups=installation.mattermost.com,resources=mattermosts,verbs=create;update;delete,versions=v1beta1,name=vmattermost.installation.mattermost.com,admissionReviewVersions=v1
+
+// mattermostValidator rejects Mattermost objects with invalid specs.
+type mattermostValidator struct{}
+
+var _ admission.CustomValidator = &mattermostValidator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *mattermostValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	mm, ok := obj.(*Mattermost)
+	if !ok {
+		return nil, fmt.Errorf("expected a Mattermost object but got %T", obj)
+	}
+
+	return mm.validationWarnings(), mm.validate()
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *mattermostValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	mm, ok := newObj.(*Mattermost)
+	if !ok {
+		return nil, fmt.Errorf("expected a Mattermost object but got %T", newObj)
+	}
+
+	return mm.validationWarnings(), mm.validate()
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *mattermostValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	mm, ok := obj.(*Mattermost)
+	if !ok {
+		return nil, fmt.Errorf("expected a Mattermost object but got %T", obj)
+	}
+
+	if !mm.DeletionAllowed() {
+		return nil, apierrors.NewForbidden(GroupVersion.WithResource("mattermosts").GroupResource(), mm.Name, errors.New(mm.DeletionProtectedMessage()))
+	}
+
+	return nil, nil
+}
+
+// validationWarnings returns non-blocking warnings about the Mattermost spec.
+func (mm *Mattermost) validationWarnings() admission.Warnings {
+	warnings := admission.Warnings(mm.ImageTagWarnings())
+	warnings = append(warnings, mm.ElasticSearchWarnings()...)
+
+	if mm.GatewayEnabled() && mm.GetIngressHost() == "" {
+		warnings = append(warnings, "spec.ingress.host is empty, the HTTPRoute will match requests for any host")
+	}
+
+	return warnings
+}
+
+// validate returns an Invalid API error describing every problem found in
+// the Mattermost spec, or nil if the spec is valid.
+func (mm *Mattermost) validate() error {
+	allErrs := mm.validateSpec()
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("Mattermost").GroupKind(), mm.Name, allErrs)
+}
+
+func (mm *Mattermost) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if mm.Spec.Size != "" {
+		if _, err := mattermostv1alpha1.GetClusterSize(mm.Spec.Size); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("size"), mm.Spec.Size, err.Error()))
+		}
+	}
+
+	if err := validateVolumes(mm.Spec.Volumes); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("volumes"), len(mm.Spec.Volumes), err.Error()))
+	}
+
+	if mm.AWSLoadBalancerEnabled() && len(mm.Spec.AWSLoadBalancerController.Hosts) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("awsLoadBalancerController", "hosts"), "at least one host is required"))
+	}
+	if !mm.AWSLoadBalancerEnabled() && mm.IngressEnabled() && mm.GetIngressHost() == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("ingress", "host"), "host is required when the Ingress is enabled"))
+	}
+
+	allErrs = append(allErrs, mm.Spec.ResourcePatch.validate(specPath.Child("resourcePatch"))...)
+	allErrs = append(allErrs, mm.Spec.Database.validate(specPath.Child("database"))...)
+	allErrs = append(allErrs, mm.Spec.FileStore.validate(specPath.Child("fileStore"))...)
+	allErrs = append(allErrs, mm.Spec.PodDisruptionBudget.validate(specPath.Child("podDisruptionBudget"))...)
+	allErrs = append(allErrs, mm.validateAutoscaling(specPath.Child("autoscaling"))...)
+	allErrs = append(allErrs, mm.Spec.NetworkPolicy.validate(specPath.Child("networkPolicy"))...)
+	allErrs = append(allErrs, mm.validateGateway(specPath.Child("gateway"))...)
+	allErrs = append(allErrs, mm.validateCertManager(specPath.Child("ingress", "certManager"))...)
+	allErrs = append(allErrs, mm.validateDeletionSnapshot(specPath.Child("deletionSnapshot"))...)
+	allErrs = append(allErrs, mm.validateSettings(specPath)...)
+
+	return allErrs
+}
+
+// ValidateSettings returns an error if the rollout, maintenance window,
+// upgrade path, image digest pinning or plugin settings are invalid. The
+// reconciler runs these checks as well, since the admission webhooks are not
+// enabled by default.
+func (mm *Mattermost) ValidateSettings() error {
+	return mm.validateSettings(field.NewPath("spec")).ToAggregate()
+}
+
+func (mm *Mattermost) validateSettings(specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, mm.validateRollout(specPath.Child("rollout"))...)
+	allErrs = append(allErrs, mm.validateMaintenanceWindow(specPath.Child("maintenanceWindow"))...)
+	allErrs = append(allErrs, mm.validateUpgradePath(specPath.Child("upgradePath"))...)
+	allErrs = append(allErrs, mm.validateImageDigestPinning(specPath.Child("imageDigestPinning"))...)
+	allErrs = append(allErrs, mm.validatePlugins(specPath.Child("plugins"))...)
+
+	return allErrs
+}
+
+func (rp *ResourcePatch) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if rp == nil {
+		return allErrs
+	}
+
+	if rp.Deployment != nil && !rp.Deployment.Disable && rp.Deployment.Patch != "" {
+		patchPath := fldPath.Child("deployment", "patch")
+		if err := validatePatchSyntax(rp.Deployment.Patch); err != nil {
+			allErrs = append(allErrs, field.Invalid(patchPath, rp.Deployment.Patch, err.Error()))
+		} else if err := validateDeploymentPatch(rp.Deployment.Patch); err != nil {
+			allErrs = append(allErrs, field.Forbidden(patchPath, err.Error()))
+		}
+	}
+
+	if rp.Service != nil && !rp.Service.Disable && rp.Service.Patch != "" {
+		patchPath := fldPath.Child("service", "patch")
+		if err := validatePatchSyntax(rp.Service.Patch); err != nil {
+			allErrs = append(allErrs, field.Invalid(patchPath, rp.Service.Patch, err.Error()))
+		} else if err := validateServicePatch(rp.Service.Patch); err != nil {
+			allErrs = append(allErrs, field.Forbidden(patchPath, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePatchSyntax checks that the patch is a well-formed JSON patch.
+func validatePatchSyntax(rawPatch string) error {
+	_, err := jsonpatch.DecodePatch([]byte(rawPatch))
+	return err
+}
+
+func (pdb *PodDisruptionBudget) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if pdb == nil {
+		return allErrs
+	}
+
+	if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "minAvailable and maxUnavailable can not be set together"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateAutoscaling(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !mm.AutoscalingEnabled() {
+		return allErrs
+	}
+
+	autoscaling := mm.Spec.Autoscaling
+	if autoscaling.MaxReplicas == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxReplicas"), "maxReplicas is required when autoscaling is enabled"))
+	} else if autoscaling.MaxReplicas < mm.GetAutoscalingMinReplicas() {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), autoscaling.MaxReplicas, "maxReplicas can not be lower than minReplicas"))
+	}
+
+	if autoscaling.CustomMetric != nil && autoscaling.CustomMetric.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("customMetric", "name"), "metric name is required"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateGateway(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !mm.GatewayEnabled() {
+		return allErrs
+	}
+
+	if len(mm.Spec.Gateway.ParentRefs) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("parentRefs"), "at least one Gateway is required"))
+	}
+	for i, ref := range mm.Spec.Gateway.ParentRefs {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("parentRefs").Index(i).Child("name"), "Gateway name is required"))
+		}
+	}
+
+	if mm.Spec.UseServiceLoadBalancer {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "gateway can not be used together with useServiceLoadBalancer"))
+	}
+	if mm.AWSLoadBalancerEnabled() {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "gateway can not be used together with awsLoadBalancerController"))
+	}
+	if mm.Spec.Ingress != nil && mm.Spec.Ingress.Enabled {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "gateway can not be used together with ingress"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateCertManager(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if mm.Spec.Ingress == nil || mm.Spec.Ingress.CertManager == nil {
+		return allErrs
+	}
+	certManager := mm.Spec.Ingress.CertManager
+
+	if certManager.IssuerRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("issuerRef", "name"), "issuer name is required"))
+	}
+	// The host of an enabled Ingress is validated with the rest of the spec.
+	if mm.GetIngressHost() == "" && (mm.AWSLoadBalancerEnabled() || !mm.IngressEnabled()) {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "ingress", "host"), "host is required to issue a certificate"))
+	}
+	if certManager.Duration != nil && certManager.Duration.Duration < time.Hour {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("duration"), certManager.Duration.String(), "must be at least 1h"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateDeletionSnapshot(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	snapshot := mm.Spec.DeletionSnapshot
+	if snapshot == nil {
+		if mm.GetDeletionPolicy() == DeletionPolicySnapshot {
+			allErrs = append(allErrs, field.Required(fldPath, "snapshot destination is required with Snapshot deletion policy"))
+		}
+		return allErrs
+	}
+
+	destinationPath := fldPath.Child("destination")
+	if !strings.HasPrefix(snapshot.Destination.URL, "s3://") || strings.Trim(strings.TrimPrefix(snapshot.Destination.URL, "s3://"), "/") == "" {
+		allErrs = append(allErrs, field.Invalid(destinationPath.Child("url"), snapshot.Destination.URL, "must be in the s3://bucket/prefix format"))
+	}
+	if snapshot.Destination.Secret == "" {
+		allErrs = append(allErrs, field.Required(destinationPath.Child("secret"), "secret with the bucket credentials is required"))
+	}
+
+	return allErrs
+}
+
+func (np *NetworkPolicy) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if np == nil || !np.Enabled {
+		return allErrs
+	}
+
+	for i, cidr := range np.EgressCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("egressCIDRs").Index(i), cidr, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+func (db *Database) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if db.External != nil && db.External.Secret == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("external", "secret"), "secret with database connection string is required for external database"))
+	}
+
+	if db.External != nil && db.OperatorManaged != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only one of external and operatorManaged may be configured"))
+	}
+
+	if db.OperatorManaged != nil && db.OperatorManaged.Type != "" {
+		supported := []string{DatabaseTypeMySQL, DatabaseTypePostgres}
+		if !slices.Contains(supported, db.OperatorManaged.Type) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("operatorManaged", "type"), db.OperatorManaged.Type, supported))
+		}
+	}
+
+	return allErrs
+}
+
+func (fs *FileStore) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	var configured []string
+	if fs.IsExternal() {
+		configured = append(configured, "external")
+	}
+	if fs.IsExternalVolume() {
+		configured = append(configured, "externalVolume")
+	}
+	if fs.IsLocal() {
+		configured = append(configured, "local")
+	}
+	if len(configured) > 1 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("only one file store may be configured, found: %v", configured)))
+	}
+
+	if fs.External != nil {
+		externalPath := fldPath.Child("external")
+		if fs.External.URL == "" {
+			allErrs = append(allErrs, field.Required(externalPath.Child("url"), "URL is required for external file store"))
+		}
+		if fs.External.Bucket == "" {
+			allErrs = append(allErrs, field.Required(externalPath.Child("bucket"), "bucket is required for external file store"))
+		}
+		if fs.External.Secret == "" && !fs.External.UseServiceAccount {
+			allErrs = append(allErrs, field.Required(externalPath.Child("secret"), "secret is required unless useServiceAccount is enabled"))
+		}
+	}
+
+	if fs.ExternalVolume != nil && fs.ExternalVolume.VolumeClaimName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("externalVolume", "volumeClaimName"), "volume claim name is required for external volume file store"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateRollout(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if mm.ProgressiveRolloutEnabled() && !mm.RolloutTrafficRoutingEnabled() {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("strategy"),
+			"Canary and BlueGreen strategies require the NGINX Ingress, they can not be used with useServiceLoadBalancer, awsLoadBalancerController or disabled Ingress"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateMaintenanceWindow(fldPath *field.Path) field.ErrorList {
+	window := mm.Spec.MaintenanceWindow
+	if window == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if _, err := utils.ParseCronSchedule(window.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), window.Schedule, err.Error()))
+	}
+	if window.Duration.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("duration"), window.Duration.String(), "duration has to be positive"))
+	}
+	if _, err := time.LoadLocation(window.TimeZone); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), window.TimeZone, "unknown time zone"))
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateUpgradePath(fldPath *field.Path) field.ErrorList {
+	if mm.Spec.UpgradePath == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for i, value := range mm.Spec.UpgradePath.IntermediateVersions {
+		if _, err := utils.ParseVersion(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("intermediateVersions").Index(i), value, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+func (mm *Mattermost) validateImageDigestPinning(fldPath *field.Path) field.ErrorList {
+	pinning := mm.Spec.ImageDigestPinning
+	if pinning == nil || pinning.RefreshInterval == nil {
+		return nil
+	}
+
+	if interval := pinning.RefreshInterval.Duration; interval != 0 && interval < minImageDigestRefreshInterval {
+		return field.ErrorList{field.Invalid(fldPath.Child("refreshInterval"), interval.String(), "refresh interval has to be at least 1m")}
+	}
+
+	return nil
+}
+
+func (mm *Mattermost) validatePlugins(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	ids := map[string]bool{}
+	for i, plugin := range mm.Spec.Plugins {
+		pluginPath := fldPath.Index(i)
+		if !pluginIDRegexp.MatchString(plugin.ID) {
+			allErrs = append(allErrs, field.Invalid(pluginPath.Child("id"), plugin.ID,
+				"plugin ID has to be 3 to 190 letters, digits, dots, dashes or underscores"))
+		} else if ids[plugin.ID] {
+			allErrs = append(allErrs, field.Duplicate(pluginPath.Child("id"), plugin.ID))
+		}
+		ids[plugin.ID] = true
+
+		sources := 0
+		if plugin.URL != "" {
+			sources++
+			if u, err := url.Parse(plugin.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				allErrs = append(allErrs, field.Invalid(pluginPath.Child("url"), plugin.URL, "URL has to be an http or https URL"))
+			}
+		}
+		if plugin.ConfigMap != nil {
+			sources++
+			if plugin.ConfigMap.Name == "" || plugin.ConfigMap.Key == "" {
+				allErrs = append(allErrs, field.Required(pluginPath.Child("configMap"), "name and key of the ConfigMap are required"))
+			}
+		}
+		if plugin.Image != nil {
+			sources++
+			if plugin.Image.Name == "" || plugin.Image.Path == "" {
+				allErrs = append(allErrs, field.Required(pluginPath.Child("image"), "name and path of the image are required"))
+			}
+		}
+		if sources != 1 {
+			allErrs = append(allErrs, field.Invalid(pluginPath, plugin.ID, "exactly one of url, configMap and image has to be set"))
+		}
+
+		if plugin.Checksum != "" && !checksumRegexp.MatchString(plugin.Checksum) {
+			allErrs = append(allErrs, field.Invalid(pluginPath.Child("checksum"), plugin.Checksum, "checksum has to be a hex encoded SHA-256 checksum"))
+		} else if plugin.Checksum == "" && plugin.URL != "" {
+			allErrs = append(allErrs, field.Required(pluginPath.Child("checksum"), "checksum is required for plugins downloaded from a URL"))
+		}
+		if plugin.Config != nil {
+			var config map[string]any
+			if err := json.Unmarshal(plugin.Config.Raw, &config); err != nil {
+				allErrs = append(allErrs, field.Invalid(pluginPath.Child("config"), string(plugin.Config.Raw), "config has to be an object"))
+			}
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,380 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newWebhookTestMattermost() *Mattermost {
+	return &Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: MattermostSpec{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: operatortest.LatestStableMattermostVersion,
+			Ingress: &Ingress{
+				Enabled: true,
+				Host:    "foo.mattermost.dev",
+			},
+		},
+	}
+}
+
+func TestMattermostDefaulter(t *testing.T) {
+	mm := newWebhookTestMattermost()
+	mm.Spec.Image = ""
+	mm.Spec.Version = ""
+
+	err := (&mattermostDefaulter{}).Default(context.Background(), mm)
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultMattermostImage, mm.Spec.Image)
+	assert.Equal(t, DefaultMattermostVersion, mm.Spec.Version)
+	require.NotNil(t, mm.Spec.Database.OperatorManaged)
+	require.NotNil(t, mm.Spec.FileStore.OperatorManaged)
+
+	mm = newWebhookTestMattermost()
+	mm.Spec.Ingress.Host = ""
+
+	err = (&mattermostDefaulter{}).Default(context.Background(), mm)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsInvalid(err))
+}
+
+func TestMattermostValidator(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		mutate      func(mm *Mattermost)
+		errFields   []string
+	}{
+		{
+			description: "valid",
+			mutate:      func(mm *Mattermost) {},
+		},
+		{
+			description: "invalid size",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Size = "1000000users"
+			},
+			errFields: []string{"spec.size"},
+		},
+		{
+			description: "ingress without host",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Ingress.Host = ""
+			},
+			errFields: []string{"spec.ingress.host"},
+		},
+		{
+			description: "aws load balancer without hosts",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.AWSLoadBalancerController = &AWSLoadBalancerController{Enabled: true}
+			},
+			errFields: []string{"spec.awsLoadBalancerController.hosts"},
+		},
+		{
+			description: "unsupported volume source",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}}}}
+			},
+			errFields: []string{"spec.volumes"},
+		},
+		{
+			description: "malformed deployment patch",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.ResourcePatch = &ResourcePatch{
+					Deployment: &Patch{Patch: "not a patch"},
+				}
+			},
+			errFields: []string{"spec.resourcePatch.deployment.patch"},
+		},
+		{
+			description: "disabled patch is not validated",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.ResourcePatch = &ResourcePatch{
+					Service: &Patch{Disable: true, Patch: "not a patch"},
+				}
+			},
+		},
+		{
+			description: "external database without secret",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Database.External = &ExternalDatabase{}
+			},
+			errFields: []string{"spec.database.external.secret"},
+		},
+		{
+			description: "operator managed postgres",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Database.OperatorManaged = &OperatorManagedDatabase{Type: DatabaseTypePostgres}
+			},
+		},
+		{
+			description: "unsupported operator managed database type",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Database.OperatorManaged = &OperatorManagedDatabase{Type: "oracle"}
+			},
+			errFields: []string{"spec.database.operatorManaged.type"},
+		},
+		{
+			description: "external and operator managed database",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Database.External = &ExternalDatabase{Secret: "database"}
+				mm.Spec.Database.OperatorManaged = &OperatorManagedDatabase{Type: DatabaseTypePostgres}
+			},
+			errFields: []string{"spec.database"},
+		},
+		{
+			description: "multiple file stores",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.FileStore.External = &ExternalFileStore{URL: "s3.amazonaws.com", Bucket: "bucket", Secret: "secret"}
+				mm.Spec.FileStore.Local = &LocalFileStore{Enabled: true}
+			},
+			errFields: []string{"spec.fileStore"},
+		},
+		{
+			description: "incomplete external file store",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.FileStore.External = &ExternalFileStore{URL: "s3.amazonaws.com"}
+			},
+			errFields: []string{"spec.fileStore.external.bucket", "spec.fileStore.external.secret"},
+		},
+		{
+			description: "external file store with service account",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.FileStore.External = &ExternalFileStore{URL: "s3.amazonaws.com", Bucket: "bucket", UseServiceAccount: true}
+			},
+		},
+		{
+			description: "canary rollout",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Rollout = &Rollout{Strategy: RolloutStrategyCanary}
+			},
+		},
+		{
+			description: "blue green rollout with service load balancer",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Rollout = &Rollout{Strategy: RolloutStrategyBlueGreen}
+				mm.Spec.UseServiceLoadBalancer = true
+			},
+			errFields: []string{"spec.rollout.strategy"},
+		},
+		{
+			description: "gateway",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Ingress.Enabled = false
+				mm.Spec.Gateway = &Gateway{Enabled: true, ParentRefs: []GatewayParentReference{{Name: "public"}}}
+			},
+		},
+		{
+			description: "gateway without parent refs",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Ingress.Enabled = false
+				mm.Spec.Gateway = &Gateway{Enabled: true}
+			},
+			errFields: []string{"spec.gateway.parentRefs"},
+		},
+		{
+			description: "gateway with ingress and service load balancer",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.UseServiceLoadBalancer = true
+				mm.Spec.Gateway = &Gateway{Enabled: true, ParentRefs: []GatewayParentReference{{Name: "public"}}}
+			},
+			errFields: []string{"spec.gateway.enabled", "spec.gateway.enabled"},
+		},
+		{
+			description: "cert-manager",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Ingress.CertManager = &CertManager{IssuerRef: CertManagerIssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer"}}
+			},
+		},
+		{
+			description: "incomplete cert-manager",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Ingress.Host = ""
+				mm.Spec.Ingress.CertManager = &CertManager{Duration: &metav1.Duration{Duration: time.Minute}}
+			},
+			errFields: []string{"spec.ingress.certManager.issuerRef.name", "spec.ingress.host", "spec.ingress.certManager.duration"},
+		},
+		{
+			description: "snapshot deletion policy",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.DeletionPolicy = DeletionPolicySnapshot
+				mm.Spec.DeletionSnapshot = &DeletionSnapshot{Destination: BackupDestination{URL: "s3://backups/mattermost", Secret: "backup-credentials"}}
+			},
+		},
+		{
+			description: "snapshot deletion policy without destination",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.DeletionPolicy = DeletionPolicySnapshot
+			},
+			errFields: []string{"spec.deletionSnapshot"},
+		},
+		{
+			description: "invalid deletion snapshot destination",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.DeletionPolicy = DeletionPolicySnapshot
+				mm.Spec.DeletionSnapshot = &DeletionSnapshot{Destination: BackupDestination{URL: "backups"}}
+			},
+			errFields: []string{"spec.deletionSnapshot.destination.url", "spec.deletionSnapshot.destination.secret"},
+		},
+		{
+			description: "maintenance window",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.MaintenanceWindow = &MaintenanceWindow{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: 4 * time.Hour}, TimeZone: "Europe/Berlin"}
+			},
+		},
+		{
+			description: "invalid maintenance window",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.MaintenanceWindow = &MaintenanceWindow{Schedule: "0 2 * *", TimeZone: "Mars/Olympus"}
+			},
+			errFields: []string{"spec.maintenanceWindow.schedule", "spec.maintenanceWindow.duration", "spec.maintenanceWindow.timeZone"},
+		},
+		{
+			description: "invalid intermediate version",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.UpgradePath = &UpgradePath{Enabled: true, IntermediateVersions: []string{"9.11.6", "latest"}}
+			},
+			errFields: []string{"spec.upgradePath.intermediateVersions[1]"},
+		},
+		{
+			description: "image digest refresh interval too short",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.ImageDigestPinning = &ImageDigestPinning{Enabled: true, RefreshInterval: &metav1.Duration{Duration: 10 * time.Second}}
+			},
+			errFields: []string{"spec.imageDigestPinning.refreshInterval"},
+		},
+		{
+			description: "plugins",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Plugins = []Plugin{
+					{ID: "com.mattermost.plugin-todo", URL: "https://example.com/todo.tar.gz", Checksum: strings.Repeat("a", 64)},
+					{ID: "com.example.internal", ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "plugins"}, Key: "internal.tar.gz"}},
+				}
+			},
+		},
+		{
+			description: "invalid plugins",
+			mutate: func(mm *Mattermost) {
+				mm.Spec.Plugins = []Plugin{
+					{ID: "com.mattermost.plugin-todo", URL: "https://example.com/todo.tar.gz", Checksum: "abc"},
+					{ID: "com.mattermost.plugin-todo", URL: "ftp://example.com/todo.tar.gz"},
+					{ID: "com.example.none"},
+					{ID: "com.example/invalid", URL: "https://example.com/invalid.tar.gz", Config: &runtime.RawExtension{Raw: []byte(`"value"`)}},
+				}
+			},
+			errFields: []string{
+				"spec.plugins[0].checksum",
+				"spec.plugins[1].id",
+				"spec.plugins[1].url",
+				"spec.plugins[1].checksum",
+				"spec.plugins[2]",
+				"spec.plugins[3].id",
+				"spec.plugins[3].checksum",
+				"spec.plugins[3].config",
+			},
+		},
+		{
+			description: "pod disruption budget with min available and max unavailable",
+			mutate: func(mm *Mattermost) {
+				minAvailable := intstr.FromInt32(1)
+				maxUnavailable := intstr.FromInt32(1)
+				mm.Spec.PodDisruptionBudget = &PodDisruptionBudget{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable}
+			},
+			errFields: []string{"spec.podDisruptionBudget"},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			mm := newWebhookTestMattermost()
+			testCase.mutate(mm)
+
+			validator := &mattermostValidator{}
+			_, createErr := validator.ValidateCreate(context.Background(), mm)
+			_, updateErr := validator.ValidateUpdate(context.Background(), newWebhookTestMattermost(), mm)
+
+			for _, err := range []error{createErr, updateErr} {
+				if len(testCase.errFields) == 0 {
+					assert.NoError(t, err)
+					continue
+				}
+
+				require.Error(t, err)
+				assert.True(t, apierrors.IsInvalid(err))
+				statusErr, ok := err.(*apierrors.StatusError)
+				require.True(t, ok)
+
+				var fields []string
+				for _, cause := range statusErr.ErrStatus.Details.Causes {
+					fields = append(fields, cause.Field)
+				}
+				assert.ElementsMatch(t, testCase.errFields, fields)
+			}
+		})
+	}
+}
+
+func TestMattermostValidatorWarnings(t *testing.T) {
+	mm := newWebhookTestMattermost()
+	mm.Spec.Ingress = nil
+	mm.Spec.Gateway = &Gateway{Enabled: true, ParentRefs: []GatewayParentReference{{Name: "public"}}}
+
+	warnings, err := (&mattermostValidator{}).ValidateCreate(context.Background(), mm)
+	require.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
+
+func TestMattermostValidatorElasticSearchWarnings(t *testing.T) {
+	mm := newWebhookTestMattermost()
+	mm.Spec.ElasticSearch = ElasticSearch{Host: "http://elastic", UserName: "user", Password: "password"}
+
+	warnings, err := (&mattermostValidator{}).ValidateCreate(context.Background(), mm)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "deprecated")
+
+	mm.Spec.ElasticSearch.Secret = "elastic-credentials"
+	warnings, err = (&mattermostValidator{}).ValidateCreate(context.Background(), mm)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "ignored")
+}
+
+func TestMattermostValidatorDelete(t *testing.T) {
+	validator := &mattermostValidator{}
+	mm := newWebhookTestMattermost()
+	mm.UID = "0b5b5e53-4b3a-4b4e-9f0c-6f2d0b7b4a1e"
+
+	_, err := validator.ValidateDelete(context.Background(), mm)
+	assert.NoError(t, err)
+
+	mm.Spec.DeletionProtection = true
+	_, err = validator.ValidateDelete(context.Background(), mm)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsForbidden(err))
+	assert.Contains(t, err.Error(), "Mattermost default/foo is protected from deletion")
+
+	mm.Annotations = map[string]string{ConfirmDeletionAnnotation: "wrong-uid"}
+	_, err = validator.ValidateDelete(context.Background(), mm)
+	require.Error(t, err)
+
+	mm.Annotations[ConfirmDeletionAnnotation] = string(mm.UID)
+	_, err = validator.ValidateDelete(context.Background(), mm)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MattermostBackupSpec defines the desired state of MattermostBackup
+// +k8s:openapi-gen=true
+type MattermostBackupSpec struct {
+	// MattermostName defines the name of the Mattermost installation to
+	// back up.
+	MattermostName string `json:"mattermostName"`
+	// Destination defines the S3-compatible bucket the backup is stored in.
+	Destination BackupDestination `json:"destination"`
+	// SkipDatabase disables the backup of the database.
+	// +optional
+	SkipDatabase bool `json:"skipDatabase,omitempty"`
+	// SkipFileStore disables the backup of the file store.
+	// +optional
+	SkipFileStore bool `json:"skipFileStore,omitempty"`
+	// Retention defines how many backups of the Mattermost installation are
+	// kept in the destination. Older backups are removed once the backup
+	// succeeds.
+	// +optional
+	Retention *BackupRetention `json:"retention,omitempty"`
+	// DatabaseImage overrides the database client image used to dump the
+	// database. Defaults to postgres or mysql image matching the database
+	// type. The client version has to be compatible with the server.
+	// +optional
+	DatabaseImage string `json:"databaseImage,omitempty"`
+	// Resources defines the resource requests and limits for the backup
+	// containers.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// BackupDestination defines the S3-compatible bucket the backups are stored in.
+type BackupDestination struct {
+	// URL of the backups location in the s3://bucket/prefix format. Backups
+	// are stored under <prefix>/<mattermost-name>/.
+	// +kubebuilder:validation:Pattern=`^s3://.+`
+	URL string `json:"url"`
+	// Secret with the credentials to the bucket. The secret has to contain
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys, AWS_REGION and
+	// AWS_ENDPOINT_URL keys are optional. AWS_ENDPOINT_URL has to be set for
+	// S3-compatible storage other than AWS S3, for example MinIO.
+	Secret string `json:"secret"`
+}
+
+// BackupRetention defines the retention policy of the backups.
+type BackupRetention struct {
+	// KeepLast defines the number of the most recent backups to keep.
+	// +kubebuilder:validation:Minimum=1
+	KeepLast int32 `json:"keepLast"`
+}
+
+// BackupPhase is the phase of the Mattermost backup.
+type BackupPhase string
+
+// Backup phases:
+const (
+	// BackupPending is the phase before the backup job is started.
+	BackupPending BackupPhase = "Pending"
+	// BackupRunning is the phase when the backup job is running.
+	BackupRunning BackupPhase = "Running"
+	// BackupSucceeded is the phase when the backup is stored in the destination.
+	BackupSucceeded BackupPhase = "Succeeded"
+	// BackupFailed is the phase when the backup failed.
+	BackupFailed BackupPhase = "Failed"
+)
+
+// MattermostBackupStatus defines the observed state of MattermostBackup
+// +k8s:openapi-gen=true
+type MattermostBackupStatus struct {
+	// Represents the phase of the backup.
+	// +optional
+	Phase BackupPhase `json:"phase,omitempty"`
+	// Describes the reason of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Location of the backup in the destination bucket.
+	// +optional
+	Location string `json:"location,omitempty"`
+	// Location of the database dump. Can be used as initBucketURL of
+	// MattermostRestoreDB to restore external databases.
+	// +optional
+	DatabaseLocation string `json:"databaseLocation,omitempty"`
+	// Size of the backup in bytes.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Time when the backup job was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time when the backup finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Duration of the backup.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// MattermostBackup is the Schema for the mattermostbackups API
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName="mmbackup"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:priority=0,name="Mattermost",type=string,JSONPath=".spec.mattermostName",description="Name of the Mattermost installation"
+// +kubebuilder:printcolumn:priority=0,name="Phase",type=string,JSONPath=".status.phase",description="Phase of the backup"
+// +kubebuilder:printcolumn:priority=1,name="Location",type=string,JSONPath=".status.location",description="Location of the backup"
+// +kubebuilder:printcolumn:priority=0,name="Size",type=integer,JSONPath=".status.sizeBytes",description="Size of the backup in bytes"
+// +kubebuilder:printcolumn:priority=0,name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+type MattermostBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MattermostBackupSpec   `json:"spec,omitempty"`
+	Status MattermostBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MattermostBackupList contains a list of MattermostBackup
+type MattermostBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MattermostBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MattermostBackup{}, &MattermostBackupList{})
+}
+
+// IsFinished returns true if the backup reached a terminal phase.
+func (b *MattermostBackup) IsFinished() bool {
+	return b.Status.Phase == BackupSucceeded || b.Status.Phase == BackupFailed
+}
+
+// DestinationPrefix returns the bucket and prefix under which the backups of
+// the Mattermost installation are stored, without the s3:// scheme.
+func (b *MattermostBackup) DestinationPrefix() string {
+	prefix := strings.Trim(strings.TrimPrefix(b.Spec.Destination.URL, "s3://"), "/")
+	return fmt.Sprintf("%s/%s", prefix, b.Spec.MattermostName)
+}
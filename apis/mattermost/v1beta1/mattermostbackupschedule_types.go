@@ -0,0 +1,76 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupScheduleLabel is set on backups created by a MattermostBackupSchedule
+// to the name of the schedule.
+const BackupScheduleLabel = "installation.mattermost.com/backup-schedule"
+
+// MattermostBackupScheduleSpec defines the desired state of MattermostBackupSchedule
+// +k8s:openapi-gen=true
+type MattermostBackupScheduleSpec struct {
+	// Schedule in the cron format, evaluated in UTC.
+	Schedule string `json:"schedule"`
+	// Suspend stops scheduling new backups.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Template defines the backups created by the schedule. MattermostBackup
+	// resources older than the template retention are removed as well.
+	Template MattermostBackupSpec `json:"template"`
+}
+
+// MattermostBackupScheduleStatus defines the observed state of MattermostBackupSchedule
+// +k8s:openapi-gen=true
+type MattermostBackupScheduleStatus struct {
+	// Time when the last backup was scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// Time when the last successful backup completed.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// Name of the last scheduled backup.
+	// +optional
+	LastBackup string `json:"lastBackup,omitempty"`
+	// Time when the next backup is scheduled.
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// Describes why backups can not be scheduled.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// MattermostBackupSchedule is the Schema for the mattermostbackupschedules API
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName="mmbackupschedule"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:priority=0,name="Mattermost",type=string,JSONPath=".spec.template.mattermostName",description="Name of the Mattermost installation"
+// +kubebuilder:printcolumn:priority=0,name="Schedule",type=string,JSONPath=".spec.schedule",description="Backup schedule"
+// +kubebuilder:printcolumn:priority=0,name="Suspend",type=boolean,JSONPath=".spec.suspend",description="Whether the schedule is suspended"
+// +kubebuilder:printcolumn:priority=0,name="Last Backup",type=date,JSONPath=".status.lastScheduleTime",description="Time of the last scheduled backup"
+// +kubebuilder:printcolumn:priority=0,name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+type MattermostBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MattermostBackupScheduleSpec   `json:"spec,omitempty"`
+	Status MattermostBackupScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MattermostBackupScheduleList contains a list of MattermostBackupSchedule
+type MattermostBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MattermostBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MattermostBackupSchedule{}, &MattermostBackupScheduleList{})
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultPgloaderImage is the default image used to copy the data from
+	// MySQL to PostgreSQL.
+	DefaultPgloaderImage = "dimitri/pgloader:v3.6.9"
+	// DefaultMigrationMySQLClientImage is the default image used to count
+	// the rows of the source MySQL tables.
+	DefaultMigrationMySQLClientImage = "mysql:8.0"
+	// DefaultMigrationPostgresClientImage is the default image used to
+	// compare the row counts against the target PostgreSQL database.
+	DefaultMigrationPostgresClientImage = "postgres:16"
+	// DefaultMigrationProgressDeadlineSeconds is the default time given to
+	// Mattermost to become stable after switching to the new database.
+	DefaultMigrationProgressDeadlineSeconds = int32(600)
+)
+
+// MattermostDBMigrationSpec defines the desired state of MattermostDBMigration
+// +k8s:openapi-gen=true
+type MattermostDBMigrationSpec struct {
+	// MattermostName defines the name of the Mattermost installation to
+	// migrate. The installation has to use an Operator-managed MySQL database.
+	MattermostName string `json:"mattermostName"`
+	// Target defines the PostgreSQL database the data is migrated to.
+	Target MigrationTarget `json:"target"`
+	// PgloaderImage defines the image of pgloader used to copy the data.
+	// +optional
+	PgloaderImage string `json:"pgloaderImage,omitempty"`
+	// MySQLClientImage defines the image of the mysql client used to count
+	// the rows of the source tables. It should match the version of the
+	// source MySQL server.
+	// +optional
+	MySQLClientImage string `json:"mysqlClientImage,omitempty"`
+	// PostgresClientImage defines the image of the psql client used to
+	// compare the row counts against the target database.
+	// +optional
+	PostgresClientImage string `json:"postgresClientImage,omitempty"`
+	// PgloaderCommands overrides the pgloader command file used to copy the
+	// data. The SOURCE_URL and TARGET_URL environment variables can be
+	// referenced as {{SOURCE_URL}} and {{TARGET_URL}}.
+	// By default, tables of the Playbooks and Boards plugins are not migrated.
+	// +optional
+	PgloaderCommands string `json:"pgloaderCommands,omitempty"`
+	// Resources defines the resource requests and limits for the migration
+	// containers.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// ProgressDeadlineSeconds defines the time given to Mattermost to become
+	// stable after switching to the new database. When exceeded the
+	// installation is switched back to the original database.
+	// Defaults to 600.
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// MigrationTarget defines the PostgreSQL database used as a migration target.
+// Exactly one of the fields has to be set.
+type MigrationTarget struct {
+	// External defines an external PostgreSQL database. The connection
+	// string in the secret has to use the postgres scheme.
+	// +optional
+	External *ExternalDatabase `json:"external,omitempty"`
+	// OperatorManaged defines a PostgreSQL database managed by CloudNativePG.
+	// Type defaults to 'postgres' and is the only supported type.
+	// +optional
+	OperatorManaged *OperatorManagedDatabase `json:"operatorManaged,omitempty"`
+}
+
+// MigrationPhase is the phase of the Mattermost database migration.
+type MigrationPhase string
+
+// Migration phases:
+const (
+	// MigrationPending is the phase before the migration is started.
+	MigrationPending MigrationPhase = "Pending"
+	// MigrationScalingDown is the phase when Mattermost is scaled down to stop
+	// writes to the source database.
+	MigrationScalingDown MigrationPhase = "ScalingDown"
+	// MigrationMigrating is the phase when the migration job copies and
+	// verifies the data.
+	MigrationMigrating MigrationPhase = "Migrating"
+	// MigrationSwitching is the phase when Mattermost is switched to the
+	// target database and scaled up.
+	MigrationSwitching MigrationPhase = "Switching"
+	// MigrationSucceeded is the phase when Mattermost is running with the
+	// target database.
+	MigrationSucceeded MigrationPhase = "Succeeded"
+	// MigrationRolledBack is the phase when the migration failed and
+	// Mattermost was restored to the original database.
+	MigrationRolledBack MigrationPhase = "RolledBack"
+	// MigrationFailed is the phase when the migration could not be started.
+	MigrationFailed MigrationPhase = "Failed"
+)
+
+// MattermostDBMigrationStatus defines the observed state of MattermostDBMigration
+// +k8s:openapi-gen=true
+type MattermostDBMigrationStatus struct {
+	// Represents the phase of the migration.
+	// +optional
+	Phase MigrationPhase `json:"phase,omitempty"`
+	// Describes the reason of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// The Mattermost version the database schema was migrated with.
+	// +optional
+	MattermostVersion string `json:"mattermostVersion,omitempty"`
+	// The original number of Mattermost replicas, restored after the migration.
+	// +optional
+	OriginalReplicas *int32 `json:"originalReplicas,omitempty"`
+	// Whether the dedicated job server was enabled before the migration.
+	// +optional
+	OriginalDedicatedJobServer bool `json:"originalDedicatedJobServer,omitempty"`
+	// The original database configuration, restored on rollback.
+	// +optional
+	OriginalDatabase *Database `json:"originalDatabase,omitempty"`
+	// Time when the migration job was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time when Mattermost was switched to the target database.
+	// +optional
+	SwitchTime *metav1.Time `json:"switchTime,omitempty"`
+	// Time when the migration finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// MattermostDBMigration is the Schema for the mattermostdbmigrations API
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName="mmdbm"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:priority=0,name="Mattermost",type=string,JSONPath=".spec.mattermostName",description="Name of the Mattermost installation"
+// +kubebuilder:printcolumn:priority=0,name="Phase",type=string,JSONPath=".status.phase",description="Phase of the migration"
+// +kubebuilder:printcolumn:priority=0,name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+type MattermostDBMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MattermostDBMigrationSpec   `json:"spec,omitempty"`
+	Status MattermostDBMigrationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MattermostDBMigrationList contains a list of MattermostDBMigration
+type MattermostDBMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MattermostDBMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MattermostDBMigration{}, &MattermostDBMigrationList{})
+}
+
+// IsFinished returns true if the migration reached a terminal phase.
+func (m *MattermostDBMigration) IsFinished() bool {
+	switch m.Status.Phase {
+	case MigrationSucceeded, MigrationRolledBack, MigrationFailed:
+		return true
+	}
+	return false
+}
+
+// GetPgloaderImage returns the pgloader image used by the migration job.
+func (m *MattermostDBMigration) GetPgloaderImage() string {
+	if m.Spec.PgloaderImage != "" {
+		return m.Spec.PgloaderImage
+	}
+	return DefaultPgloaderImage
+}
+
+// GetMySQLClientImage returns the image used to count the rows of the source
+// tables.
+func (m *MattermostDBMigration) GetMySQLClientImage() string {
+	if m.Spec.MySQLClientImage != "" {
+		return m.Spec.MySQLClientImage
+	}
+	return DefaultMigrationMySQLClientImage
+}
+
+// GetPostgresClientImage returns the image used to verify the row counts of
+// the target tables.
+func (m *MattermostDBMigration) GetPostgresClientImage() string {
+	if m.Spec.PostgresClientImage != "" {
+		return m.Spec.PostgresClientImage
+	}
+	return DefaultMigrationPostgresClientImage
+}
+
+// GetProgressDeadline returns the time given to Mattermost to become stable
+// after switching to the target database.
+func (m *MattermostDBMigration) GetProgressDeadline() time.Duration {
+	seconds := DefaultMigrationProgressDeadlineSeconds
+	if m.Spec.ProgressDeadlineSeconds != nil {
+		seconds = *m.Spec.ProgressDeadlineSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// TargetDatabase returns the database configuration used by Mattermost after
+// the migration.
+func (m *MattermostDBMigration) TargetDatabase(original Database) Database {
+	target := Database{
+		DisableReadinessCheck: original.DisableReadinessCheck,
+		ReadinessCheck:        original.ReadinessCheck,
+	}
+
+	if m.Spec.Target.External != nil {
+		target.External = m.Spec.Target.External.DeepCopy()
+		return target
+	}
+
+	target.OperatorManaged = &OperatorManagedDatabase{}
+	if m.Spec.Target.OperatorManaged != nil {
+		target.OperatorManaged = m.Spec.Target.OperatorManaged.DeepCopy()
+	}
+	if target.OperatorManaged.Type == "" {
+		target.OperatorManaged.Type = DatabaseTypePostgres
+	}
+	target.OperatorManaged.SetDefaults()
+
+	return target
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpgradePlanAnnotation is set on Mattermost installations upgraded by a
+// MattermostUpgradePlan to the name of the plan.
+const UpgradePlanAnnotation = "installation.mattermost.com/upgrade-plan"
+
+// DefaultUpgradeTimeout is the default time an installation has to become
+// stable after it is upgraded.
+const DefaultUpgradeTimeout = 20 * time.Minute
+
+// MattermostUpgradePlanSpec defines the desired state of MattermostUpgradePlan
+// +k8s:openapi-gen=true
+type MattermostUpgradePlanSpec struct {
+	// Selector selects the Mattermost installations upgraded by the plan by
+	// their labels. All installations are selected if not set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Namespaces limits the plan to installations in the given namespaces.
+	// Installations in all namespaces are selected if empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Image is the Mattermost image the installations are upgraded to. The
+	// image of the installations is kept if empty.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Version is the Mattermost version the installations are upgraded to.
+	Version string `json:"version"`
+	// BatchSize is the number of installations upgraded at the same time.
+	// The next batch is started once all installations of the current batch
+	// are stable. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+	// MaxFailures is the number of installations which may fail to upgrade
+	// before the plan is halted. Defaults to 0, halting the plan on the first
+	// failure. Increasing the budget resumes the halted plan.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxFailures int32 `json:"maxFailures,omitempty"`
+	// Timeout defines how long an installation has to become stable after it
+	// is upgraded before it is considered failed. Defaults to 20m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Paused stops starting new batches. Installations which are being
+	// upgraded are still followed.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Rollback restores the image and the version the upgraded installations
+	// were running before the plan.
+	// +optional
+	Rollback bool `json:"rollback,omitempty"`
+}
+
+// UpgradePlanPhase is the phase of the Mattermost upgrade plan.
+type UpgradePlanPhase string
+
+// Upgrade plan phases:
+const (
+	// UpgradePlanProgressing is the phase when the installations are upgraded.
+	UpgradePlanProgressing UpgradePlanPhase = "Progressing"
+	// UpgradePlanPaused is the phase when the plan is paused and no
+	// installation is being upgraded.
+	UpgradePlanPaused UpgradePlanPhase = "Paused"
+	// UpgradePlanHalted is the phase when more installations failed to
+	// upgrade than the failure budget allows.
+	UpgradePlanHalted UpgradePlanPhase = "Halted"
+	// UpgradePlanSucceeded is the phase when all selected installations are
+	// upgraded.
+	UpgradePlanSucceeded UpgradePlanPhase = "Succeeded"
+	// UpgradePlanRollingBack is the phase when the upgraded installations are
+	// being restored to their previous image and version.
+	UpgradePlanRollingBack UpgradePlanPhase = "RollingBack"
+	// UpgradePlanRolledBack is the phase when the upgraded installations were
+	// restored to their previous image and version.
+	UpgradePlanRolledBack UpgradePlanPhase = "RolledBack"
+)
+
+// InstallationUpgradePhase is the phase of the upgrade of a single
+// installation.
+type InstallationUpgradePhase string
+
+// Installation upgrade phases:
+const (
+	// InstallationUpgradePending is the phase before the installation is
+	// upgraded.
+	InstallationUpgradePending InstallationUpgradePhase = "Pending"
+	// InstallationUpgradeInProgress is the phase when the installation runs
+	// the new version but is not stable yet.
+	InstallationUpgradeInProgress InstallationUpgradePhase = "Upgrading"
+	// InstallationUpgradeSucceeded is the phase when the installation is
+	// stable with the new version.
+	InstallationUpgradeSucceeded InstallationUpgradePhase = "Succeeded"
+	// InstallationUpgradeFailed is the phase when the installation did not
+	// become stable with the new version.
+	InstallationUpgradeFailed InstallationUpgradePhase = "Failed"
+	// InstallationUpgradeSkipped is the phase of installations which already
+	// run the target version.
+	InstallationUpgradeSkipped InstallationUpgradePhase = "Skipped"
+	// InstallationUpgradeRollingBack is the phase when the installation is
+	// set to its previous image and version but does not run them yet.
+	InstallationUpgradeRollingBack InstallationUpgradePhase = "RollingBack"
+	// InstallationUpgradeRolledBack is the phase when the installation was
+	// restored to its previous image and version.
+	InstallationUpgradeRolledBack InstallationUpgradePhase = "RolledBack"
+)
+
+// InstallationUpgradeStatus defines the progress of the upgrade of a single
+// installation.
+type InstallationUpgradeStatus struct {
+	// Name of the Mattermost installation.
+	Name string `json:"name"`
+	// Namespace of the Mattermost installation.
+	Namespace string `json:"namespace"`
+	// Phase of the upgrade of the installation.
+	Phase InstallationUpgradePhase `json:"phase"`
+	// Batch in which the installation was upgraded, starting from 1.
+	// +optional
+	Batch int32 `json:"batch,omitempty"`
+	// Image the installation was running before the upgrade.
+	// +optional
+	PreviousImage string `json:"previousImage,omitempty"`
+	// Version the installation was running before the upgrade.
+	// +optional
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// Time when the upgrade of the installation was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time when the upgrade of the installation finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Describes the reason of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MattermostUpgradePlanStatus defines the observed state of MattermostUpgradePlan
+// +k8s:openapi-gen=true
+type MattermostUpgradePlanStatus struct {
+	// Represents the phase of the plan.
+	// +optional
+	Phase UpgradePlanPhase `json:"phase,omitempty"`
+	// Describes the reason of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Number of the last started batch.
+	// +optional
+	CurrentBatch int32 `json:"currentBatch,omitempty"`
+	// Number of installations selected by the plan.
+	// +optional
+	Total int32 `json:"total,omitempty"`
+	// Number of installations upgraded successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// Number of installations which failed to upgrade.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+	// Time when the plan was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time when the plan finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Progress of the upgrade of the selected installations. The
+	// installations are selected once, when the plan is started.
+	// +optional
+	Installations []InstallationUpgradeStatus `json:"installations,omitempty"`
+}
+
+// MattermostUpgradePlan is the Schema for the mattermostupgradeplans API
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName="mmupgradeplan",scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:priority=0,name="Version",type=string,JSONPath=".spec.version",description="Target Mattermost version"
+// +kubebuilder:printcolumn:priority=0,name="Phase",type=string,JSONPath=".status.phase",description="Phase of the plan"
+// +kubebuilder:printcolumn:priority=0,name="Batch",type=integer,JSONPath=".status.currentBatch",description="Current batch"
+// +kubebuilder:printcolumn:priority=0,name="Succeeded",type=integer,JSONPath=".status.succeeded",description="Number of upgraded installations"
+// +kubebuilder:printcolumn:priority=0,name="Failed",type=integer,JSONPath=".status.failed",description="Number of failed installations"
+// +kubebuilder:printcolumn:priority=0,name="Total",type=integer,JSONPath=".status.total",description="Number of selected installations"
+// +kubebuilder:printcolumn:priority=0,name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+type MattermostUpgradePlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MattermostUpgradePlanSpec   `json:"spec,omitempty"`
+	Status MattermostUpgradePlanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MattermostUpgradePlanList contains a list of MattermostUpgradePlan
+type MattermostUpgradePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MattermostUpgradePlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MattermostUpgradePlan{}, &MattermostUpgradePlanList{})
+}
+
+// GetBatchSize returns the number of installations upgraded at the same time.
+func (p *MattermostUpgradePlan) GetBatchSize() int {
+	if p.Spec.BatchSize < 1 {
+		return 1
+	}
+	return int(p.Spec.BatchSize)
+}
+
+// GetTimeout returns how long an installation has to become stable after
+// it is upgraded.
+func (p *MattermostUpgradePlan) GetTimeout() time.Duration {
+	if p.Spec.Timeout == nil {
+		return DefaultUpgradeTimeout
+	}
+	return p.Spec.Timeout.Duration
+}
+
+// TargetImage returns the image the installation is upgraded to.
+func (p *MattermostUpgradePlan) TargetImage(mattermost *Mattermost) string {
+	if p.Spec.Image == "" {
+		return mattermost.Spec.Image
+	}
+	return p.Spec.Image
+}
+
+// IsFinished returns true if the plan reached a terminal phase.
+func (p *MattermostUpgradePlan) IsFinished() bool {
+	return p.Status.Phase == UpgradePlanSucceeded || p.Status.Phase == UpgradePlanRolledBack
+}
@@ -136,13 +136,14 @@ func (s *MattermostStatus) SetDeploymentPatchStatus(applied bool, err error) {
 		s.ResourcePatch.DeploymentPatch = &PatchStatus{}
 	}
 	s.ResourcePatch.DeploymentPatch.set(applied, err)
+	s.updateResourcePatchCondition()
 }
 
 func (s *MattermostStatus) ClearDeploymentPatchStatus() {
-	if s.ResourcePatch == nil {
-		return
+	if s.ResourcePatch != nil {
+		s.ResourcePatch.DeploymentPatch = nil
 	}
-	s.ResourcePatch.DeploymentPatch = nil
+	s.updateResourcePatchCondition()
 }
 
 // ApplyToService applies patch and returns resulting service.
@@ -175,13 +176,14 @@ func (s *MattermostStatus) SetServicePatchStatus(applied bool, err error) {
 		s.ResourcePatch.ServicePatch = &PatchStatus{}
 	}
 	s.ResourcePatch.ServicePatch.set(applied, err)
+	s.updateResourcePatchCondition()
 }
 
 func (s *MattermostStatus) ClearServicePatchStatus() {
-	if s.ResourcePatch == nil {
-		return
+	if s.ResourcePatch != nil {
+		s.ResourcePatch.ServicePatch = nil
 	}
-	s.ResourcePatch.ServicePatch = nil
+	s.updateResourcePatchCondition()
 }
 
 func (p Patch) applyPatch(resource, destination runtime.Object, gvk *schema.GroupVersionKind) error {
@@ -608,3 +608,24 @@ func loadFile(t *testing.T, path string) string {
 func int32Ptr(i int32) *int32 {
 	return &i
 }
+
+func TestMattermostStatus_ResourcePatchCondition(t *testing.T) {
+	status := &MattermostStatus{}
+
+	status.ClearDeploymentPatchStatus()
+	condition := status.GetCondition(ConditionResourcePatchApplied)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonNoPatches, condition.Reason)
+
+	status.SetServicePatchStatus(false, fmt.Errorf("invalid patch"))
+	condition = status.GetCondition(ConditionResourcePatchApplied)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonPatchFailed, condition.Reason)
+	assert.Equal(t, "invalid patch", condition.Message)
+
+	status.SetServicePatchStatus(true, nil)
+	status.SetDeploymentPatchStatus(true, nil)
+	assert.True(t, status.IsConditionTrue(ConditionResourcePatchApplied))
+}
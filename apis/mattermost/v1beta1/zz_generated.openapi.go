@@ -17,8 +17,20 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Mattermost":     schema_mattermost_operator_apis_mattermost_v1beta1_Mattermost(ref),
-		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostSpec": schema_mattermost_operator_apis_mattermost_v1beta1_MattermostSpec(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Mattermost":                     schema_mattermost_operator_apis_mattermost_v1beta1_Mattermost(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackup":               schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackup(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupSchedule":       schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupSchedule(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupScheduleSpec":   schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupScheduleSpec(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupScheduleStatus": schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupScheduleStatus(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupSpec":           schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupSpec(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupStatus":         schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupStatus(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigration":          schema_mattermost_operator_apis_mattermost_v1beta1_MattermostDBMigration(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigrationSpec":      schema_mattermost_operator_apis_mattermost_v1beta1_MattermostDBMigrationSpec(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigrationStatus":    schema_mattermost_operator_apis_mattermost_v1beta1_MattermostDBMigrationStatus(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostSpec":                 schema_mattermost_operator_apis_mattermost_v1beta1_MattermostSpec(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlan":          schema_mattermost_operator_apis_mattermost_v1beta1_MattermostUpgradePlan(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlanSpec":      schema_mattermost_operator_apis_mattermost_v1beta1_MattermostUpgradePlanSpec(ref),
+		"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlanStatus":    schema_mattermost_operator_apis_mattermost_v1beta1_MattermostUpgradePlanStatus(ref),
 	}
 }
 
@@ -69,6 +81,512 @@ func schema_mattermost_operator_apis_mattermost_v1beta1_Mattermost(ref common.Re
 	}
 }
 
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackup(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostBackup is the Schema for the mattermostbackups API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupSpec", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupSchedule(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostBackupSchedule is the Schema for the mattermostbackupschedules API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupScheduleSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupScheduleStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupScheduleSpec", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupScheduleStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupScheduleSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostBackupScheduleSpec defines the desired state of MattermostBackupSchedule",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule in the cron format, evaluated in UTC.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Suspend stops scheduling new backups.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"template": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Template defines the backups created by the schedule. MattermostBackup resources older than the template retention are removed as well.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupSpec"),
+						},
+					},
+				},
+				Required: []string{"schedule", "template"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostBackupSpec"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupScheduleStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostBackupScheduleStatus defines the observed state of MattermostBackupSchedule",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"lastScheduleTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the last backup was scheduled.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastSuccessfulTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the last successful backup completed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastBackup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the last scheduled backup.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nextScheduleTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the next backup is scheduled.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Describes why backups can not be scheduled.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostBackupSpec defines the desired state of MattermostBackup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mattermostName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MattermostName defines the name of the Mattermost installation to back up.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"destination": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Destination defines the S3-compatible bucket the backup is stored in.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.BackupDestination"),
+						},
+					},
+					"skipDatabase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SkipDatabase disables the backup of the database.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"skipFileStore": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SkipFileStore disables the backup of the file store.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"retention": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Retention defines how many backups of the Mattermost installation are kept in the destination. Older backups are removed once the backup succeeds.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.BackupRetention"),
+						},
+					},
+					"databaseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DatabaseImage overrides the database client image used to dump the database. Defaults to postgres or mysql image matching the database type. The client version has to be compatible with the server.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources defines the resource requests and limits for the backup containers.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+				},
+				Required: []string{"mattermostName", "destination"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.BackupDestination", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.BackupRetention", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostBackupStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostBackupStatus defines the observed state of MattermostBackup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Represents the phase of the backup.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Describes the reason of the current phase.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"location": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Location of the backup in the destination bucket.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"databaseLocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Location of the database dump. Can be used as initBucketURL of MattermostRestoreDB to restore external databases.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sizeBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Size of the backup in bytes.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the backup job was started.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the backup finished.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration of the backup.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostDBMigration(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostDBMigration is the Schema for the mattermostdbmigrations API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigrationSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigrationStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigrationSpec", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostDBMigrationStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostDBMigrationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostDBMigrationSpec defines the desired state of MattermostDBMigration",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mattermostName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MattermostName defines the name of the Mattermost installation to migrate. The installation has to use an Operator-managed MySQL database.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Target defines the PostgreSQL database the data is migrated to.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MigrationTarget"),
+						},
+					},
+					"pgloaderImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PgloaderImage defines the image of pgloader used to copy the data.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mysqlClientImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MySQLClientImage defines the image of the mysql client used to count the rows of the source tables. It should match the version of the source MySQL server.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"postgresClientImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PostgresClientImage defines the image of the psql client used to compare the row counts against the target database.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pgloaderCommands": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PgloaderCommands overrides the pgloader command file used to copy the data. The SOURCE_URL and TARGET_URL environment variables can be referenced as {{SOURCE_URL}} and {{TARGET_URL}}. By default, tables of the Playbooks and Boards plugins are not migrated.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources defines the resource requests and limits for the migration containers.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+					"progressDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProgressDeadlineSeconds defines the time given to Mattermost to become stable after switching to the new database. When exceeded the installation is switched back to the original database. Defaults to 600.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"mattermostName", "target"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MigrationTarget", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostDBMigrationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostDBMigrationStatus defines the observed state of MattermostDBMigration",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Represents the phase of the migration.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Describes the reason of the current phase.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mattermostVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The Mattermost version the database schema was migrated with.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"originalReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The original number of Mattermost replicas, restored after the migration.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"originalDedicatedJobServer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the dedicated job server was enabled before the migration.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"originalDatabase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The original database configuration, restored on rollback.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Database"),
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the migration job was started.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"switchTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when Mattermost was switched to the target database.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the migration finished.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Database", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -203,6 +721,12 @@ func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostSpec(ref commo
 							Ref: ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.AWSLoadBalancerController"),
 						},
 					},
+					"gateway": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Gateway defines configuration for the Gateway API HTTPRoute exposing Mattermost. When enabled, it is created instead of the Ingress.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Gateway"),
+						},
+					},
 					"volumes": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Volumes allows for mounting volumes from various sources into the Mattermost application pods.",
@@ -322,6 +846,103 @@ func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostSpec(ref commo
 							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.JobServer"),
 						},
 					},
+					"rollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rollout defines the strategy used to roll out new Mattermost images.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Rollout"),
+						},
+					},
+					"maintenanceWindow": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaintenanceWindow defines when changes of the Mattermost image are applied. Image changes made outside of the window are deferred until the next window, unless forced with the installation.mattermost.com/force-upgrade annotation. If not set, image changes are applied right away.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MaintenanceWindow"),
+						},
+					},
+					"upgradePath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpgradePath defines how upgrades skipping major versions are handled. Downgrades and upgrades skipping major versions are blocked unless allowed with the installation.mattermost.com/allow-unsupported-upgrade annotation.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.UpgradePath"),
+						},
+					},
+					"imageDigestPinning": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImageDigestPinning resolves the image tag to a digest with the registry API and runs the Mattermost pods with the digest, so all pods run the same image. The tag is resolved again periodically, and a moved tag is rolled out like a new version.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.ImageDigestPinning"),
+						},
+					},
+					"plugins": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"id",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Plugins installed on the Mattermost pods. The plugin bundles are staged by an init container, and the states and configuration of the plugins are patched with mmctl in local mode when the pods start. Plugins not listed are left as they are.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Plugin"),
+									},
+								},
+							},
+						},
+					},
+					"pluginStagingImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PluginStagingImage defines the image of the init container staging the plugin bundles. The image has to provide sh, curl, sha256sum and tar. Defaults to curlimages/curl with a pinned version.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodDisruptionBudget defines configuration for the PodDisruptionBudget of Mattermost pods.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.PodDisruptionBudget"),
+						},
+					},
+					"autoscaling": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Autoscaling defines configuration for the HorizontalPodAutoscaler of the Mattermost deployment.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Autoscaling"),
+						},
+					},
+					"networkPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NetworkPolicy defines configuration for the NetworkPolicy isolating Mattermost pods from the rest of the cluster.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.NetworkPolicy"),
+						},
+					},
+					"monitoring": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Monitoring defines configuration of Mattermost metrics and of their scraping by Prometheus.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Monitoring"),
+						},
+					},
+					"deletionPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletionPolicy defines what happens to the database and the file store managed by the operator when the Mattermost is deleted. Delete removes them together with the installation, Retain keeps them and Snapshot backs up the installation before it is removed. Defaults to Delete.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deletionSnapshot": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletionSnapshot defines where the final backup of the installation is stored when DeletionPolicy is Snapshot.",
+							Ref:         ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.DeletionSnapshot"),
+						},
+					},
+					"deletionProtection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletionProtection prevents the Mattermost from being deleted. The deletion is rejected unless the protection is turned off or the installation.mattermost.com/confirm-deletion annotation is set to the UID of the Mattermost.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"podExtensions": {
 						SchemaProps: spec.SchemaProps{
 							Description: "PodExtensions specify custom extensions for Mattermost pods. This can be used for custom readiness checks etc. These settings generally don't need to be changed.",
@@ -339,6 +960,222 @@ func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostSpec(ref commo
 			},
 		},
 		Dependencies: []string{
-			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.AWSLoadBalancerController", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Database", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.DeploymentTemplate", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.ElasticSearch", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.FileStore", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Ingress", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.JobServer", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.PodExtensions", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.PodTemplate", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Probes", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.ResourcePatch", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Scheduling", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.UpdateJob", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodDNSConfig", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.AWSLoadBalancerController", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Autoscaling", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Database", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.DeletionSnapshot", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.DeploymentTemplate", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.ElasticSearch", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.FileStore", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Gateway", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.ImageDigestPinning", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Ingress", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.JobServer", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MaintenanceWindow", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Monitoring", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.NetworkPolicy", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Plugin", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.PodDisruptionBudget", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.PodExtensions", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.PodTemplate", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Probes", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.ResourcePatch", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Rollout", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.Scheduling", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.UpdateJob", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.UpgradePath", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodDNSConfig", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostUpgradePlan(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostUpgradePlan is the Schema for the mattermostupgradeplans API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlanSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlanStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlanSpec", "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.MattermostUpgradePlanStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostUpgradePlanSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostUpgradePlanSpec defines the desired state of MattermostUpgradePlan",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector selects the Mattermost installations upgraded by the plan by their labels. All installations are selected if not set.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"namespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespaces limits the plan to installations in the given namespaces. Installations in all namespaces are selected if empty.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image is the Mattermost image the installations are upgraded to. The image of the installations is kept if empty.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version is the Mattermost version the installations are upgraded to.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"batchSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BatchSize is the number of installations upgraded at the same time. The next batch is started once all installations of the current batch are stable. Defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxFailures": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxFailures is the number of installations which may fail to upgrade before the plan is halted. Defaults to 0, halting the plan on the first failure. Increasing the budget resumes the halted plan.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timeout defines how long an installation has to become stable after it is upgraded before it is considered failed. Defaults to 20m.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"paused": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Paused stops starting new batches. Installations which are being upgraded are still followed.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"rollback": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rollback restores the image and the version the upgraded installations were running before the plan.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"version"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_mattermost_operator_apis_mattermost_v1beta1_MattermostUpgradePlanStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MattermostUpgradePlanStatus defines the observed state of MattermostUpgradePlan",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Represents the phase of the plan.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Describes the reason of the current phase.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"currentBatch": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of the last started batch.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"total": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of installations selected by the plan.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"succeeded": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of installations upgraded successfully.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of installations which failed to upgrade.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the plan was started.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time when the plan finished.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"installations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Progress of the upgrade of the selected installations. The installations are selected once, when the plan is started.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.InstallationUpgradeStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1.InstallationUpgradeStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -74,6 +75,7 @@ func TestReconcile(t *testing.T) {
 		Log:                logger,
 		MaxReconciling:     5,
 		Resources:          resources.NewResourceHelper(c, s),
+		Recorder:           record.NewFakeRecorder(100),
 	}
 
 	err := c.Create(context.TODO(), ci)
@@ -456,6 +458,7 @@ func TestReconcilingLimit(t *testing.T) {
 		MaxReconciling:      2,
 		RequeueOnLimitDelay: requeueOnLimitDelay,
 		Resources:           resources.NewResourceHelper(c, s),
+		Recorder:            record.NewFakeRecorder(100),
 	}
 
 	assertInstallationsCount := func(t *testing.T, expectedCIs, expectedReconciling int) {
@@ -625,6 +628,7 @@ func TestMigration(t *testing.T) {
 		MaxReconciling:      2,
 		RequeueOnLimitDelay: requeueOnLimitDelay,
 		Resources:           resources.NewResourceHelper(c, s),
+		Recorder:            record.NewFakeRecorder(100),
 	}
 
 	assertMigrationStatus := func(ciName, status string) {
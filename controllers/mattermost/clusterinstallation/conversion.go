@@ -15,11 +15,11 @@ import (
 
 func (r *ClusterInstallationReconciler) IsConvertible(ci *mattermostv1alpha1.ClusterInstallation) error {
 	if ci.Spec.BlueGreen.Enable {
-		return errors.New("ClusterInstallation resource with BlueGreen enabled cannot be converted to Mattermost resource. Disable BlueGreen to enable migration.")
+		return errors.New("ClusterInstallation resource with BlueGreen enabled cannot be converted to Mattermost resource. Disable BlueGreen to enable migration and use the BlueGreen strategy in Mattermost spec.rollout instead.")
 	}
 
 	if ci.Spec.Canary.Enable {
-		return errors.New("ClusterInstallation resource with Canary enabled cannot be converted to Mattermost resource. Disable Canary to enable migration.")
+		return errors.New("ClusterInstallation resource with Canary enabled cannot be converted to Mattermost resource. Disable Canary to enable migration and use the Canary strategy in Mattermost spec.rollout instead.")
 	}
 
 	return nil
@@ -0,0 +1,10 @@
+package clusterinstallation
+
+// Reasons of the events emitted on ClusterInstallation resources.
+const (
+	eventReasonStateChanged       = "StateChanged"
+	eventReasonUpdateJobLaunched  = "UpdateJobLaunched"
+	eventReasonUpdateJobRestarted = "UpdateJobRestarted"
+	eventReasonUpdateJobFailed    = "UpdateJobFailed"
+	eventReasonUpdateJobSucceeded = "UpdateJobSucceeded"
+)
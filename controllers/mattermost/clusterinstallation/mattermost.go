@@ -384,6 +384,7 @@ func (r *ClusterInstallationReconciler) checkUpdateJob(
 			if err = r.Resources.LaunchMattermostUpdateJob(mattermost, mattermost.Namespace, desired, reqLogger, nil); err != nil {
 				return nil, errors.Wrap(err, "Launching update image job failed")
 			}
+			r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpdateJobLaunched, "Launched update job for image %s", mattermost.GetMattermostAppContainerFromDeployment(desired).Image)
 			return nil, errors.New("Began update image job")
 		}
 
@@ -407,6 +408,7 @@ func (r *ClusterInstallationReconciler) checkUpdateJob(
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to restart update job")
 		}
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpdateJobRestarted, "Restarted update job for image %s", mattermost.GetMattermostAppContainerFromDeployment(desired).Image)
 
 		return nil, errors.New("Restarted update image job")
 	}
@@ -418,10 +420,12 @@ func (r *ClusterInstallationReconciler) checkUpdateJob(
 	// Job is completed, can check completion status
 
 	if job.Status.Failed > 0 {
+		r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonUpdateJobFailed, "Update job for image %s failed", mattermost.GetMattermostAppContainerFromDeployment(desired).Image)
 		return job, errors.New("update image job failed")
 	}
 
 	reqLogger.Info("Update image job ran successfully")
+	r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpdateJobSucceeded, "Update job for image %s completed successfully", mattermost.GetMattermostAppContainerFromDeployment(desired).Image)
 
 	return job, nil
 }
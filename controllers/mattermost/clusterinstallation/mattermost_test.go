@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -62,6 +63,7 @@ func TestCheckMattermost(t *testing.T) {
 		Log:            logger,
 		MaxReconciling: 5,
 		Resources:      resources.NewResourceHelper(c, s),
+		Recorder:       record.NewFakeRecorder(100),
 	}
 
 	err := prepAllDependencyTestResources(r.Client, ci)
@@ -389,6 +391,7 @@ func TestCheckMattermostExternalDB(t *testing.T) {
 		MaxReconciling:      5,
 		RequeueOnLimitDelay: 0,
 		Resources:           resources.NewResourceHelper(c, s),
+		Recorder:            record.NewFakeRecorder(100),
 	}
 
 	err := prepAllDependencyTestResources(r.Client, ci)
@@ -162,6 +162,7 @@ func (r *ClusterInstallationReconciler) updateStatus(mattermost *mattermostv1alp
 	if !reflect.DeepEqual(mattermost.Status, status) {
 		if mattermost.Status.State != status.State {
 			reqLogger.Info(fmt.Sprintf("Updating ClusterInstallation state from '%s' to '%s'", mattermost.Status.State, status.State))
+			r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonStateChanged, "ClusterInstallation state changed from '%s' to '%s'", mattermost.Status.State, status.State)
 		}
 
 		mattermost.Status = status
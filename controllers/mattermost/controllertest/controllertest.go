@@ -0,0 +1,126 @@
+// Package controllertest provides the fixtures shared by the tests of the
+// controllers running operations on Mattermost installations.
+package controllertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	blubr "github.com/mattermost/blubr"
+	mattermostv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// MattermostName is the name of the Mattermost returned by NewMattermost.
+	MattermostName = "foo"
+	// Namespace is the namespace of the namespaced test objects.
+	Namespace = "default"
+)
+
+// Deps are the dependencies of a reconciler under test, backed by a fake
+// client.
+type Deps struct {
+	Client    client.Client
+	Scheme    *runtime.Scheme
+	Log       logr.Logger
+	Recorder  *record.FakeRecorder
+	Resources *resources.ResourceHelper
+}
+
+// NewDeps returns the dependencies of a reconciler with a fake client
+// serving the given objects. Status is a subresource of all Mattermost
+// resources, jobs, pods, stateful sets and CloudNativePG clusters.
+func NewDeps(t *testing.T, objects ...client.Object) Deps {
+	logSink := blubr.InitLogger(logrus.NewEntry(logrus.New()))
+	logger := logr.New(logSink.WithName("test.opr"))
+
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, mattermostv1alpha1.AddToScheme(s))
+	require.NoError(t, mmv1beta.AddToScheme(s))
+	require.NoError(t, cnpgv1.SchemeBuilder.AddToScheme(s))
+
+	c := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objects...).
+		WithStatusSubresource(
+			&mmv1beta.Mattermost{},
+			&mmv1beta.MattermostDBMigration{},
+			&mmv1beta.MattermostBackup{},
+			&mmv1beta.MattermostBackupSchedule{},
+			&mmv1beta.MattermostUpgradePlan{},
+			&mattermostv1alpha1.MattermostRestoreDB{},
+			&cnpgv1.Cluster{},
+			&batchv1.Job{},
+			&corev1.Pod{},
+			&appsv1.StatefulSet{},
+		).
+		Build()
+
+	return Deps{
+		Client:    c,
+		Scheme:    s,
+		Log:       logger,
+		Recorder:  record.NewFakeRecorder(100),
+		Resources: resources.NewResourceHelper(c, s),
+	}
+}
+
+// NewMattermost returns a Mattermost installation with 3 replicas named
+// MattermostName.
+func NewMattermost() *mmv1beta.Mattermost {
+	replicas := int32(3)
+	return &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MattermostName,
+			Namespace: Namespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas: &replicas,
+			Image:    "mattermost/mattermost-enterprise-edition",
+			Version:  operatortest.LatestStableMattermostVersion,
+		},
+	}
+}
+
+// Reconcile reconciles the object and requires the reconciliation to
+// succeed.
+func Reconcile(t *testing.T, r reconcile.Reconciler, name, namespace string) reconcile.Result {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+	res, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	return res
+}
+
+// Get reads the object into obj and requires it to exist.
+func Get[T client.Object](t *testing.T, c client.Reader, name, namespace string, obj T) T {
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, obj)
+	require.NoError(t, err)
+	return obj
+}
+
+// SetJobStatus sets the status of the job, like the job controller does.
+func SetJobStatus(t *testing.T, c client.Client, name, namespace string, status batchv1.JobStatus) {
+	job := Get(t, c, name, namespace, &batchv1.Job{})
+	job.Status = status
+	require.NoError(t, c.Status().Update(context.TODO(), job))
+}
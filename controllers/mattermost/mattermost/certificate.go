@@ -0,0 +1,117 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkMattermostCertificate creates or updates the cert-manager Certificate
+// of the Mattermost hosts and reports whether it is issued. The Certificate
+// is deleted if cert-manager is not used.
+func (r *MattermostReconciler) checkMattermostCertificate(mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, reqLogger logr.Logger) error {
+	desired := mattermostApp.GenerateCertificateV1Beta(mattermost)
+
+	installed, err := r.certManagerInstalled()
+	if err != nil {
+		return errors.Wrap(err, "failed to check if cert-manager is installed")
+	}
+
+	if !mattermost.CertManagerEnabled() {
+		status.RemoveCondition(mmv1beta.ConditionCertificateReady)
+		status.CertificateNotAfter = nil
+		if !installed {
+			return nil
+		}
+		err = r.Resources.DeleteCertificate(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete Certificate")
+		}
+		return nil
+	}
+
+	if !installed {
+		return errors.New("cert-manager Certificate CRD is not installed")
+	}
+
+	err = r.Resources.CreateCertificateIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &certmanagerv1.Certificate{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	err = r.Resources.Update(current, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	status.CertificateNotAfter = current.Status.NotAfter
+	setCertificateReadyCondition(status, current)
+	return nil
+}
+
+// setCertificateReadyCondition reports whether the certificate is issued, and
+// the reason of the last issuance failure.
+func setCertificateReadyCondition(status *mmv1beta.MattermostStatus, certificate *certmanagerv1.Certificate) {
+	ready := getCertificateCondition(certificate, certmanagerv1.CertificateConditionReady)
+	if ready != nil && ready.Status == metav1.ConditionTrue {
+		message := "Certificate is issued"
+		if certificate.Status.NotAfter != nil {
+			message = fmt.Sprintf("Certificate is valid until %s", certificate.Status.NotAfter.UTC().Format(time.RFC3339))
+		}
+		status.SetCondition(mmv1beta.ConditionCertificateReady, metav1.ConditionTrue, mmv1beta.ReasonCertificateIssued, message)
+		return
+	}
+
+	if certificate.Status.LastFailureTime != nil {
+		message := "Certificate issuance failed"
+		if issuing := getCertificateCondition(certificate, certmanagerv1.CertificateConditionIssuing); issuing != nil && issuing.Message != "" {
+			message = issuing.Message
+		} else if ready != nil && ready.Message != "" {
+			message = ready.Message
+		}
+		status.SetCondition(mmv1beta.ConditionCertificateReady, metav1.ConditionFalse, mmv1beta.ReasonCertificateFailed, message)
+		return
+	}
+
+	status.SetCondition(mmv1beta.ConditionCertificateReady, metav1.ConditionFalse, mmv1beta.ReasonCertificatePending, "Waiting for certificate to be issued")
+}
+
+func getCertificateCondition(certificate *certmanagerv1.Certificate, conditionType certmanagerv1.CertificateConditionType) *certmanagerv1.CertificateCondition {
+	for i := range certificate.Status.Conditions {
+		if certificate.Status.Conditions[i].Type == conditionType {
+			return &certificate.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// certManagerInstalled returns true if the Certificate CRD of cert-manager is
+// installed in the cluster.
+func (r *MattermostReconciler) certManagerInstalled() (bool, error) {
+	gk := schema.GroupKind{Group: certmanagerv1.SchemeGroupVersion.Group, Kind: certmanagerv1.CertificateKind}
+	_, err := r.Client.RESTMapper().RESTMapping(gk, certmanagerv1.SchemeGroupVersion.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
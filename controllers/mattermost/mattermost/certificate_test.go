@@ -0,0 +1,139 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckMattermostCertificate(t *testing.T) {
+	mmName := "foo"
+	mmNamespace := "default"
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: operatortest.LatestStableMattermostVersion,
+			Ingress: &mmv1beta.Ingress{
+				Enabled: true,
+				Host:    "foo.mattermost.dev",
+				CertManager: &mmv1beta.CertManager{
+					IssuerRef: mmv1beta.CertManagerIssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+				},
+			},
+		},
+	}
+
+	t.Run("fails without cert-manager", func(t *testing.T) {
+		logger, _, reconciler := setupTestDeps(t)
+
+		err := reconciler.checkMattermostCertificate(mm, &mmv1beta.MattermostStatus{}, logger)
+		require.Error(t, err)
+	})
+
+	logger, _, reconciler := setupTestDeps(t)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(certmanagerv1.SchemeGroupVersion.WithKind(certmanagerv1.CertificateKind), meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithScheme(reconciler.Scheme).WithRESTMapper(mapper).WithStatusSubresource(&certmanagerv1.Certificate{}).Build()
+	reconciler.Client = c
+	reconciler.Resources = resources.NewResourceHelper(c, reconciler.Scheme)
+
+	getCertificate := func() (*certmanagerv1.Certificate, error) {
+		certificate := &certmanagerv1.Certificate{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, certificate)
+		return certificate, err
+	}
+	setCertificateStatus := func(t *testing.T, certificateStatus certmanagerv1.CertificateStatus) {
+		certificate, err := getCertificate()
+		require.NoError(t, err)
+		certificate.Status = certificateStatus
+		require.NoError(t, c.Status().Update(context.TODO(), certificate))
+	}
+
+	t.Run("created and pending", func(t *testing.T) {
+		status := &mmv1beta.MattermostStatus{}
+		err := reconciler.checkMattermostCertificate(mm, status, logger)
+		require.NoError(t, err)
+
+		certificate, err := getCertificate()
+		require.NoError(t, err)
+		require.Len(t, certificate.OwnerReferences, 1)
+		assert.Equal(t, mmName, certificate.OwnerReferences[0].Name)
+		assert.Equal(t, []string{"foo.mattermost.dev"}, certificate.Spec.DNSNames)
+		assert.Equal(t, "foo-mattermost-dev-tls-cert", certificate.Spec.SecretName)
+
+		condition := status.GetCondition(mmv1beta.ConditionCertificateReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, mmv1beta.ReasonCertificatePending, condition.Reason)
+	})
+
+	t.Run("issuance failed", func(t *testing.T) {
+		failureTime := metav1.Now()
+		setCertificateStatus(t, certmanagerv1.CertificateStatus{
+			LastFailureTime: &failureTime,
+			Conditions: []certmanagerv1.CertificateCondition{
+				{Type: certmanagerv1.CertificateConditionReady, Status: metav1.ConditionFalse, Reason: "DoesNotExist"},
+				{Type: certmanagerv1.CertificateConditionIssuing, Status: metav1.ConditionFalse, Reason: "Failed", Message: "rate limited"},
+			},
+		})
+
+		status := &mmv1beta.MattermostStatus{}
+		err := reconciler.checkMattermostCertificate(mm, status, logger)
+		require.NoError(t, err)
+
+		condition := status.GetCondition(mmv1beta.ConditionCertificateReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, mmv1beta.ReasonCertificateFailed, condition.Reason)
+		assert.Equal(t, "rate limited", condition.Message)
+	})
+
+	t.Run("issued", func(t *testing.T) {
+		notAfter := metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+		setCertificateStatus(t, certmanagerv1.CertificateStatus{
+			NotAfter: &notAfter,
+			Conditions: []certmanagerv1.CertificateCondition{
+				{Type: certmanagerv1.CertificateConditionReady, Status: metav1.ConditionTrue, Reason: "Ready"},
+			},
+		})
+
+		status := &mmv1beta.MattermostStatus{}
+		err := reconciler.checkMattermostCertificate(mm, status, logger)
+		require.NoError(t, err)
+
+		assert.True(t, status.IsConditionTrue(mmv1beta.ConditionCertificateReady))
+		require.NotNil(t, status.CertificateNotAfter)
+		assert.True(t, notAfter.Equal(status.CertificateNotAfter))
+	})
+
+	t.Run("deleted when disabled", func(t *testing.T) {
+		mm.Spec.Ingress.CertManager = nil
+
+		status := &mmv1beta.MattermostStatus{}
+		status.SetCondition(mmv1beta.ConditionCertificateReady, metav1.ConditionTrue, mmv1beta.ReasonCertificateIssued, "")
+		err := reconciler.checkMattermostCertificate(mm, status, logger)
+		require.NoError(t, err)
+
+		_, err = getCertificate()
+		assert.True(t, k8sErrors.IsNotFound(err))
+		assert.Nil(t, status.GetCondition(mmv1beta.ConditionCertificateReady))
+		assert.Nil(t, status.CertificateNotAfter)
+	})
+}
@@ -0,0 +1,122 @@
+package mattermost
+
+import (
+	"context"
+	"slices"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// setConfigHash annotates the pod template of the deployment with the hash of
+// the Secrets and ConfigMaps used by the pods. Missing Secrets and ConfigMaps
+// are skipped, the hash changes once they are created. ConfigMaps are read
+// from the API server as only their metadata is cached.
+func (r *MattermostReconciler) setConfigHash(deployment *appsv1.Deployment) error {
+	secretNames, configMapNames := mattermostApp.ReferencedConfig(&deployment.Spec.Template.Spec)
+
+	secrets := make([]corev1.Secret, 0, len(secretNames))
+	for _, name := range secretNames {
+		secret := corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: deployment.Namespace}, &secret)
+		if k8sErrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get secret %s", name)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	configMaps := make([]corev1.ConfigMap, 0, len(configMapNames))
+	for _, name := range configMapNames {
+		configMap := corev1.ConfigMap{}
+		err := r.NonCachedAPIReader.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: deployment.Namespace}, &configMap)
+		if k8sErrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get config map %s", name)
+		}
+		configMaps = append(configMaps, configMap)
+	}
+
+	mattermostApp.SetConfigHash(deployment, mattermostApp.ConfigHash(secrets, configMaps))
+	return nil
+}
+
+// configChanged returns true if the Secrets or ConfigMaps used by the pods of
+// the current deployment changed.
+func configChanged(current, desired *appsv1.Deployment) bool {
+	currentHash := current.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]
+	return currentHash != "" && currentHash != desired.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]
+}
+
+// configVerified returns false while the update job verifies changed Secrets
+// or ConfigMaps and the Mattermost deployment still uses the previous ones.
+func (r *MattermostReconciler) configVerified(mattermost *mmv1beta.Mattermost) (bool, error) {
+	current := &appsv1.Deployment{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: mattermost.Name, Namespace: mattermost.Namespace}, current)
+	if k8sErrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get mattermost deployment")
+	}
+
+	desired := current.DeepCopy()
+	err = r.setConfigHash(desired)
+	if err != nil {
+		return false, err
+	}
+	return !configChanged(current, desired), nil
+}
+
+// mattermostsUsingSecret maps a Secret to the Mattermosts whose deployments
+// use it.
+func (r *MattermostReconciler) mattermostsUsingSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.mattermostsUsingConfig(ctx, obj, true)
+}
+
+// mattermostsUsingConfigMap maps a ConfigMap to the Mattermosts whose
+// deployments use it.
+func (r *MattermostReconciler) mattermostsUsingConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.mattermostsUsingConfig(ctx, obj, false)
+}
+
+func (r *MattermostReconciler) mattermostsUsingConfig(ctx context.Context, obj client.Object, isSecret bool) []reconcile.Request {
+	var deployments appsv1.DeploymentList
+	err := r.Client.List(ctx, &deployments, client.InNamespace(obj.GetNamespace()), client.HasLabels{mmv1beta.ClusterLabel})
+	if err != nil {
+		r.Log.Error(err, "Failed to list deployments using config", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := map[reconcile.Request]struct{}{}
+	for _, deployment := range deployments.Items {
+		owner := metav1.GetControllerOf(&deployment)
+		if owner == nil || owner.Kind != "Mattermost" {
+			continue
+		}
+
+		secretNames, configMapNames := mattermostApp.ReferencedConfig(&deployment.Spec.Template.Spec)
+		names := configMapNames
+		if isSecret {
+			names = secretNames
+		}
+		if slices.Contains(names, obj.GetName()) {
+			requests[reconcile.Request{NamespacedName: types.NamespacedName{Name: owner.Name, Namespace: obj.GetNamespace()}}] = struct{}{}
+		}
+	}
+
+	result := make([]reconcile.Request, 0, len(requests))
+	for request := range requests {
+		result = append(result, request)
+	}
+	return result
+}
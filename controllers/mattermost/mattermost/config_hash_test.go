@@ -0,0 +1,190 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestCheckMattermostConfigHash(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(2)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:      &replicas,
+			Image:         "mattermost/mattermost-enterprise-edition",
+			Version:       operatortest.LatestStableMattermostVersion,
+			IngressName:   "foo.mattermost.dev",
+			LicenseSecret: "license",
+		},
+	}
+
+	licenseSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "license", Namespace: mmNamespace},
+		Data:       map[string][]byte{"license": []byte("license-1")},
+	}
+	require.NoError(t, reconciler.Client.Create(context.TODO(), licenseSecret))
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	getConfigHash := func(t *testing.T) string {
+		deployment := &appsv1.Deployment{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, deployment)
+		require.NoError(t, err)
+		return deployment.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]
+	}
+	rotateLicense := func(t *testing.T, license string) {
+		licenseSecret.Data["license"] = []byte(license)
+		require.NoError(t, reconciler.Client.Update(context.TODO(), licenseSecret))
+	}
+
+	recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+	require.NoError(t, err)
+	require.True(t, recStatus.ResourcesReady)
+	initialHash := getConfigHash(t)
+	require.NotEmpty(t, initialHash)
+
+	t.Run("referenced secret is mapped to Mattermost", func(t *testing.T) {
+		requests := reconciler.mattermostsUsingSecret(context.TODO(), licenseSecret)
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: mmName, Namespace: mmNamespace}}}, requests)
+
+		unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: mmNamespace}}
+		assert.Empty(t, reconciler.mattermostsUsingSecret(context.TODO(), unrelated))
+		configMap := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "license", Namespace: mmNamespace}}
+		assert.Empty(t, reconciler.mattermostsUsingConfigMap(context.TODO(), configMap))
+	})
+
+	t.Run("pods restarted when secret changes", func(t *testing.T) {
+		rotateLicense(t, "license-2")
+
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.NotEqual(t, initialHash, getConfigHash(t))
+	})
+
+	t.Run("update job verifies changed secret", func(t *testing.T) {
+		mm.Spec.UpdateJob = &mmv1beta.UpdateJob{OnConfigChange: true}
+		previousHash := getConfigHash(t)
+		rotateLicense(t, "license-3")
+
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+		assert.False(t, recStatus.ResourcesReady)
+		assert.Equal(t, previousHash, getConfigHash(t))
+
+		job := &batchv1.Job{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "mattermost-update-check", Namespace: mmNamespace}, job)
+		require.NoError(t, err)
+		assert.NotEqual(t, previousHash, job.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation])
+	})
+
+	t.Run("update job restarted when secret changes again", func(t *testing.T) {
+		job := &batchv1.Job{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "mattermost-update-check", Namespace: mmNamespace}, job)
+		require.NoError(t, err)
+		verifiedHash := job.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]
+		rotateLicense(t, "license-4")
+
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+		assert.False(t, recStatus.ResourcesReady)
+
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "mattermost-update-check", Namespace: mmNamespace}, job)
+		require.NoError(t, err)
+		assert.NotEqual(t, verifiedHash, job.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation])
+	})
+}
+
+func TestCheckMattermostJobServerConfigHash(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(2)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:      &replicas,
+			Image:         "mattermost/mattermost-enterprise-edition",
+			Version:       operatortest.LatestStableMattermostVersion,
+			IngressName:   "foo.mattermost.dev",
+			LicenseSecret: "license",
+			JobServer:     &mmv1beta.JobServer{DedicatedJobServer: true},
+			UpdateJob:     &mmv1beta.UpdateJob{OnConfigChange: true},
+		},
+	}
+
+	licenseSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "license", Namespace: mmNamespace},
+		Data:       map[string][]byte{"license": []byte("license-1")},
+	}
+	require.NoError(t, reconciler.Client.Create(context.TODO(), licenseSecret))
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	getConfigHash := func(t *testing.T, name string) string {
+		deployment := &appsv1.Deployment{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mmNamespace}, deployment)
+		require.NoError(t, err)
+		return deployment.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]
+	}
+	reconcileDeployments := func(t *testing.T) reconcileStatus {
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+		err = reconciler.checkMattermostJobServerDeployment(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+		return recStatus
+	}
+
+	require.True(t, reconcileDeployments(t).ResourcesReady)
+	initialHash := getConfigHash(t, mm.DedicatedJobServerName())
+	require.NotEmpty(t, initialHash)
+
+	licenseSecret.Data["license"] = []byte("license-2")
+	require.NoError(t, reconciler.Client.Update(context.TODO(), licenseSecret))
+
+	t.Run("job server waits for update job", func(t *testing.T) {
+		assert.False(t, reconcileDeployments(t).ResourcesReady)
+		assert.Equal(t, initialHash, getConfigHash(t, mmName))
+		assert.Equal(t, initialHash, getConfigHash(t, mm.DedicatedJobServerName()))
+	})
+
+	t.Run("job server restarted once configuration is verified", func(t *testing.T) {
+		job := &batchv1.Job{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "mattermost-update-check", Namespace: mmNamespace}, job)
+		require.NoError(t, err)
+		now := metav1.Now()
+		job.Status = batchv1.JobStatus{Succeeded: 1, StartTime: &now, CompletionTime: &now}
+		require.NoError(t, reconciler.Client.Status().Update(context.TODO(), job))
+
+		assert.True(t, reconcileDeployments(t).ResourcesReady)
+		assert.NotEqual(t, initialHash, getConfigHash(t, mmName))
+		assert.Equal(t, getConfigHash(t, mmName), getConfigHash(t, mm.DedicatedJobServerName()))
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
+	"github.com/mattermost/mattermost-operator/pkg/metrics"
+	"github.com/mattermost/mattermost-operator/pkg/registry"
 	"github.com/mattermost/mattermost-operator/pkg/resources"
 
 	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
@@ -18,12 +20,19 @@ import (
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -39,6 +48,8 @@ type MattermostReconciler struct {
 	MaxReconciling         int
 	RequeueOnLimitDelay    time.Duration
 	Resources              *resources.ResourceHelper
+	Recorder               record.EventRecorder
+	Registry               *registry.Client
 	reconcilingRateLimiter unstableInstallationsRateLimiter
 }
 
@@ -51,6 +62,8 @@ func NewMattermostReconciler(mgr ctrl.Manager, maxReconciling int, requeueOnLimi
 		MaxReconciling:      maxReconciling,
 		RequeueOnLimitDelay: requeueOnLimitDelay,
 		Resources:           resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
+		Recorder:            mgr.GetEventRecorderFor("mattermost-operator"),
+		Registry:            registry.NewClient(nil),
 		reconcilingRateLimiter: unstableInstallationsRateLimiter{
 			nonReconcilingBeingProcessed: 0,
 			Mutex:                        sync.Mutex{},
@@ -64,8 +77,16 @@ func (r *MattermostReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrency
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
 		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
 		Owns(&batchv1.Job{}).
+		// Secrets are cached already as owned objects. ConfigMaps are watched
+		// by their metadata only, so that their content is not cached for the
+		// whole cluster.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mattermostsUsingSecret)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mattermostsUsingConfigMap), builder.OnlyMetadata).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
@@ -89,14 +110,25 @@ func (r *MattermostReconciler) Reconcile(ctx context.Context, request ctrl.Reque
 	if err != nil && k8sErrors.IsNotFound(err) {
 		// Request object not found, could have been deleted after reconcile
 		// request. Owned objects are automatically garbage collected.
+		metrics.DeleteInstallation(request.Namespace, request.Name)
+		r.reconcilingRateLimiter.unblock(request.NamespacedName)
 		return reconcile.Result{}, nil
 	} else if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if !mattermost.DeletionTimestamp.IsZero() {
+		return r.finalizeMattermost(ctx, mattermost, reqLogger)
+	}
+
+	err = r.checkFinalizer(ctx, mattermost, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if mattermost.Status.State != mmv1beta.Reconciling && mattermost.Status.State != mmv1beta.Ready {
 		var canProcess bool
-		canProcess, err = r.startNonReconcilingMMProcessing(ctx, reqLogger)
+		canProcess, err = r.startNonReconcilingMMProcessing(ctx, request.NamespacedName, reqLogger)
 		if err != nil {
 			return reconcile.Result{}, errors.Wrap(err, "failed to verify reconciliation limit")
 		}
@@ -136,10 +168,21 @@ func (r *MattermostReconciler) Reconcile(ctx context.Context, request ctrl.Reque
 		return reconcile.Result{}, err
 	}
 
-	// Log warnings for mutable image tags (e.g. "latest")
+	// The admission webhooks are optional, invalid settings which would
+	// otherwise be rejected by them are reported here.
+	err = mattermost.ValidateSettings()
+	if err != nil {
+		r.updateStatusReconcilingAndLogError(mattermost, status, reqLogger, err)
+		return reconcile.Result{}, err
+	}
+
+	// Log warnings for mutable image tags (e.g. "latest") and deprecated settings
 	for _, w := range mattermost.ImageTagWarnings() {
 		reqLogger.Info(fmt.Sprintf("WARNING: %s", w))
 	}
+	for _, w := range mattermost.ElasticSearchWarnings() {
+		reqLogger.Info(fmt.Sprintf("WARNING: %s", w))
+	}
 
 	softError := mattermost.SetReplicasAndResourcesFromSize()
 	if softError != nil {
@@ -155,20 +198,39 @@ func (r *MattermostReconciler) Reconcile(ctx context.Context, request ctrl.Reque
 		}
 	}
 
+	// The version is only replaced for this reconciliation, the spec keeps
+	// the version requested by the user.
+	targetVersion := mattermost.Spec.Version
+	mattermost.Spec.Version = r.checkUpgradePath(mattermost, &status, reqLogger)
+	intermediateUpgrade := mattermost.Spec.Version != targetVersion && status.IsConditionTrue(mmv1beta.ConditionUpgradeAllowed)
+
+	mattermost.Spec.Version, err = r.pinImageDigest(ctx, mattermost, &status, reqLogger)
+	if err != nil {
+		r.updateStatusReconcilingAndLogError(mattermost, status, reqLogger, err)
+		return reconcile.Result{}, err
+	}
+
 	dbConfig, err := r.checkDatabase(mattermost, reqLogger)
 	if err != nil {
+		metrics.IncReconcileError(metrics.StepDatabase)
+		status.SetCondition(mmv1beta.ConditionDatabaseReady, metav1.ConditionFalse, mmv1beta.ReasonDatabaseCheckFailed, err.Error())
 		r.updateStatusReconcilingAndLogError(mattermost, status, reqLogger, err)
 		return reconcile.Result{}, err
 	}
+	status.SetCondition(mmv1beta.ConditionDatabaseReady, metav1.ConditionTrue, mmv1beta.ReasonDatabaseConfigured, "Database configuration is available")
 
 	fileStoreConfig, err := r.checkFileStore(mattermost, reqLogger)
 	if err != nil {
+		metrics.IncReconcileError(metrics.StepFileStore)
+		status.SetCondition(mmv1beta.ConditionFileStoreReady, metav1.ConditionFalse, mmv1beta.ReasonFileStoreCheckFailed, err.Error())
 		r.updateStatusReconcilingAndLogError(mattermost, status, reqLogger, err)
 		return reconcile.Result{}, err
 	}
+	status.SetCondition(mmv1beta.ConditionFileStoreReady, metav1.ConditionTrue, mmv1beta.ReasonFileStoreConfigured, "File store configuration is available")
 
 	recStatus, err := r.checkMattermost(mattermost, dbConfig, fileStoreConfig, &status, reqLogger)
 	if err != nil {
+		metrics.IncReconcileError(metrics.StepDeployment)
 		r.updateStatusReconcilingAndLogError(mattermost, status, reqLogger, err)
 		return reconcile.Result{}, err
 	}
@@ -184,12 +246,14 @@ func (r *MattermostReconciler) Reconcile(ctx context.Context, request ctrl.Reque
 
 	err = r.checkMattermostJobServer(mattermost, dbConfig, fileStoreConfig, &status, reqLogger)
 	if err != nil {
+		metrics.IncReconcileError(metrics.StepJobServer)
 		r.updateStatusReconcilingAndLogError(mattermost, status, reqLogger, err)
 		return reconcile.Result{}, err
 	}
 
 	status, err = r.checkMattermostHealth(mattermost, status, reqLogger)
 	if err != nil {
+		metrics.IncReconcileError(metrics.StepHealth)
 		statusErr := r.updateStatus(mattermost, status, reqLogger)
 		if statusErr != nil {
 			reqLogger.Error(statusErr, "Error updating status")
@@ -204,7 +268,18 @@ func (r *MattermostReconciler) Reconcile(ctx context.Context, request ctrl.Reque
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, nil
+	// Deferred image change is applied once the maintenance window starts,
+	// the next intermediate version is rolled out right away. The pinned
+	// image tag is resolved again once the refresh interval passed.
+	requeueAfter := maintenanceWindowDelay(status)
+	if requeueAfter == 0 && intermediateUpgrade {
+		requeueAfter = resourcesReadyDelay
+	}
+	if delay := imageDigestRefreshDelay(mattermost, status); delay > 0 && (requeueAfter == 0 || delay < requeueAfter) {
+		requeueAfter = delay
+	}
+
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func (r *MattermostReconciler) updateSpec(ctx context.Context, reqLogger logr.Logger, updated *mmv1beta.Mattermost) error {
@@ -216,7 +291,9 @@ func (r *MattermostReconciler) updateSpec(ctx context.Context, reqLogger logr.Lo
 // non-reconciling state can be currently processed by the Operator by checking
 // if the rate limit has been reached.
 // Returns false when rate limit is reached and processing cannot be started.
-func (r *MattermostReconciler) startNonReconcilingMMProcessing(ctx context.Context, reqLogger logr.Logger) (bool, error) {
+// Installations which cannot be processed are counted as blocked until they
+// are picked up or deleted.
+func (r *MattermostReconciler) startNonReconcilingMMProcessing(ctx context.Context, key types.NamespacedName, reqLogger logr.Logger) (bool, error) {
 	r.reconcilingRateLimiter.Lock()
 	defer r.reconcilingRateLimiter.Unlock()
 
@@ -233,10 +310,12 @@ func (r *MattermostReconciler) startNonReconcilingMMProcessing(ctx context.Conte
 	)
 	// Check if limit of Mattermosts reconciling at the same time is reached.
 	if rCount+r.reconcilingRateLimiter.nonReconcilingBeingProcessed >= r.MaxReconciling {
+		r.reconcilingRateLimiter.setBlocked(key, true)
 		return false, nil
 	}
 
 	r.reconcilingRateLimiter.nonReconcilingBeingProcessed += 1
+	r.reconcilingRateLimiter.setBlocked(key, false)
 
 	return true, nil
 }
@@ -246,9 +325,31 @@ type unstableInstallationsRateLimiter struct {
 	// not (yet) in Reconciling state. To respect the rate limit with multiple
 	// reconcilers we need to sync with mutex.
 	nonReconcilingBeingProcessed int
+	// Installations waiting because the limit was reached.
+	blocked map[types.NamespacedName]struct{}
 	sync.Mutex
 }
 
+// setBlocked marks the installation as blocked or not and updates the
+// metric. It has to be called with the lock held.
+func (rl *unstableInstallationsRateLimiter) setBlocked(key types.NamespacedName, blocked bool) {
+	if blocked {
+		if rl.blocked == nil {
+			rl.blocked = map[types.NamespacedName]struct{}{}
+		}
+		rl.blocked[key] = struct{}{}
+	} else {
+		delete(rl.blocked, key)
+	}
+	metrics.ReconcileLimited.Set(float64(len(rl.blocked)))
+}
+
+func (rl *unstableInstallationsRateLimiter) unblock(key types.NamespacedName) {
+	rl.Lock()
+	defer rl.Unlock()
+	rl.setBlocked(key, false)
+}
+
 func (rl *unstableInstallationsRateLimiter) decrementProcessing() {
 	rl.Lock()
 	defer rl.Unlock()
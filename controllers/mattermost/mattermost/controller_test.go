@@ -1,12 +1,19 @@
 package mattermost
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
 	mysqlv1alpha1 "github.com/mattermost/mattermost-operator/pkg/database/mysql_operator/v1alpha1"
+	"github.com/mattermost/mattermost-operator/pkg/metrics"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
 	"github.com/mattermost/mattermost-operator/pkg/resources"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 
 	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
@@ -21,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -78,6 +86,7 @@ func TestReconcile(t *testing.T) {
 		Log:                logger,
 		MaxReconciling:     5,
 		Resources:          resources.NewResourceHelper(c, s),
+		Recorder:           record.NewFakeRecorder(100),
 	}
 
 	err := c.Create(context.TODO(), mm)
@@ -291,6 +300,17 @@ func TestReconcile(t *testing.T) {
 			// Patch status preserved
 			assert.True(t, mm.Status.ResourcePatch.DeploymentPatch.Applied)
 			assert.Empty(t, mm.Status.ResourcePatch.DeploymentPatch.Error)
+
+			for _, conditionType := range []string{
+				mmv1beta.ConditionDatabaseReady,
+				mmv1beta.ConditionFileStoreReady,
+				mmv1beta.ConditionDeploymentRolledOut,
+				mmv1beta.ConditionIngressReady,
+				mmv1beta.ConditionJobServerReady,
+				mmv1beta.ConditionResourcePatchApplied,
+			} {
+				assert.True(t, mm.Status.IsConditionTrue(conditionType), "condition %s should be true", conditionType)
+			}
 		})
 	})
 
@@ -316,6 +336,52 @@ func TestReconcile(t *testing.T) {
 		err = c.Get(context.Background(), mmKey, mm)
 		require.NoError(t, err)
 		assert.NotEmpty(t, mm.Status.Error)
+
+		recorder, ok := r.Recorder.(*record.FakeRecorder)
+		require.True(t, ok)
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		assert.Contains(t, events, fmt.Sprintf("Warning %s %s", eventReasonReconcileFailed, mm.Status.Error))
+	})
+
+	t.Run("check invalid settings set in status", func(t *testing.T) {
+		mm := &mmv1beta.Mattermost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mm-invalid-settings",
+				Namespace: "default",
+			},
+			Spec: mmv1beta.MattermostSpec{
+				IngressName:            "foo.mattermost.dev",
+				Rollout:                &mmv1beta.Rollout{Strategy: mmv1beta.RolloutStrategyCanary},
+				UseServiceLoadBalancer: true,
+			},
+		}
+		mmKey := types.NamespacedName{Name: mm.Name, Namespace: mm.Namespace}
+
+		err = c.Create(context.TODO(), mm)
+		require.NoError(t, err)
+
+		req := reconcile.Request{NamespacedName: mmKey}
+		_, err = r.Reconcile(context.Background(), req)
+		require.Error(t, err)
+
+		err = c.Get(context.Background(), mmKey, mm)
+		require.NoError(t, err)
+		assert.Contains(t, mm.Status.Error, "spec.rollout.strategy")
+
+		recorder, ok := r.Recorder.(*record.FakeRecorder)
+		require.True(t, ok)
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		assert.Contains(t, events, fmt.Sprintf("Warning %s %s", eventReasonReconcileFailed, mm.Status.Error))
+
+		_, err = r.Reconcile(context.Background(), req)
+		require.Error(t, err)
+		assert.Empty(t, recorder.Events)
 	})
 
 	t.Run("check external file store", func(t *testing.T) {
@@ -374,6 +440,102 @@ func TestReconcile(t *testing.T) {
 	})
 }
 
+func TestReconcileOperatorManagedPostgres(t *testing.T) {
+	logSink := blubr.InitLogger(logrus.NewEntry(logrus.New()))
+	logSink = logSink.WithName("test.opr")
+	logger := logr.New(logSink)
+	logf.SetLogger(logger)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(1)
+	dbReplicas := int32(3)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mmName,
+			Namespace:  mmNamespace,
+			UID:        types.UID("test"),
+			Generation: 1,
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:    &replicas,
+			Image:       "mattermost/mattermost-enterprise-edition",
+			Version:     operatortest.LatestStableMattermostVersion,
+			IngressName: "foo.mattermost.dev",
+			Database: mmv1beta.Database{
+				OperatorManaged: &mmv1beta.OperatorManagedDatabase{
+					Type:     mmv1beta.DatabaseTypePostgres,
+					Replicas: &dbReplicas,
+				},
+			},
+		},
+	}
+
+	s := prepareSchema(t, scheme.Scheme)
+	s.AddKnownTypes(mmv1beta.GroupVersion, mm)
+	c := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&mmv1beta.Mattermost{}).Build()
+	r := &MattermostReconciler{
+		Client:             c,
+		NonCachedAPIReader: c,
+		Scheme:             s,
+		Log:                logger,
+		MaxReconciling:     5,
+		Resources:          resources.NewResourceHelper(c, s),
+		Recorder:           record.NewFakeRecorder(100),
+	}
+
+	err := c.Create(context.TODO(), mm)
+	require.NoError(t, err)
+	err = prepAllDependencyTestResources(r.Client, mm)
+	require.NoError(t, err)
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: mmName, Namespace: mmNamespace}}
+	_, err = r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+
+	mmKey := types.NamespacedName{Name: mmName, Namespace: mmNamespace}
+	clusterKey := types.NamespacedName{Name: utils.HashWithPrefix("db", mmName), Namespace: mmNamespace}
+
+	t.Run("cluster", func(t *testing.T) {
+		cluster := &cnpgv1.Cluster{}
+		err = c.Get(context.TODO(), clusterKey, cluster)
+		require.NoError(t, err)
+		assert.Equal(t, 3, cluster.Spec.Instances)
+		assert.Equal(t, "ghcr.io/cloudnative-pg/postgresql:"+mmv1beta.DefaultPostgresDatabaseVersion, cluster.Spec.ImageName)
+		assert.Equal(t, mmv1beta.DefaultStorageSize, cluster.Spec.StorageConfiguration.Size)
+		require.NotNil(t, cluster.Spec.Bootstrap)
+		require.NotNil(t, cluster.Spec.Bootstrap.InitDB)
+		assert.Equal(t, mmName+"-postgres-credentials", cluster.Spec.Bootstrap.InitDB.Secret.Name)
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		secret := &corev1.Secret{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: mmName + "-postgres-credentials", Namespace: mmNamespace}, secret)
+		require.NoError(t, err)
+		assert.Equal(t, corev1.SecretTypeBasicAuth, secret.Type)
+		assert.Equal(t, "mmuser", string(secret.Data[corev1.BasicAuthUsernameKey]))
+		assert.NotEmpty(t, secret.Data[corev1.BasicAuthPasswordKey])
+	})
+
+	t.Run("deployment", func(t *testing.T) {
+		deployment := &appsv1.Deployment{}
+		err = c.Get(context.TODO(), mmKey, deployment)
+		require.NoError(t, err)
+
+		initContainers := deployment.Spec.Template.Spec.InitContainers
+		require.NotEmpty(t, initContainers)
+		assert.Equal(t, "init-check-operator-postgres", initContainers[0].Name)
+
+		var mmConfig string
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == "MM_CONFIG" {
+				mmConfig = env.Value
+			}
+		}
+		assert.Contains(t, mmConfig, fmt.Sprintf("postgres://$(POSTGRES_USERNAME):$(POSTGRES_PASSWORD)@%s-rw.", clusterKey.Name))
+	})
+}
+
 func TestReconcilingLimit(t *testing.T) {
 	// Setup logging for the reconciler so we can see what happened on failure.
 	logSink := blubr.InitLogger(logrus.NewEntry(logrus.New()))
@@ -416,6 +578,7 @@ func TestReconcilingLimit(t *testing.T) {
 		MaxReconciling:      2,
 		RequeueOnLimitDelay: requeueOnLimitDelay,
 		Resources:           resources.NewResourceHelper(c, s),
+		Recorder:            record.NewFakeRecorder(100),
 	}
 
 	assertInstallationsCount := func(t *testing.T, expectedCIs, expectedReconcilingOrReady int) {
@@ -484,6 +647,7 @@ func TestReconcilingLimit(t *testing.T) {
 		result, err = r.Reconcile(context.Background(), req5)
 		require.NoError(t, err)
 		assert.Equal(t, requeueOnLimitDelay, result.RequeueAfter)
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.ReconcileLimited))
 	})
 
 	err = c.Delete(context.TODO(), mm1)
@@ -503,6 +667,7 @@ func TestReconcilingLimit(t *testing.T) {
 		_, err = r.Reconcile(context.Background(), req4)
 		require.Error(t, err)
 		assertInstallationsCount(t, 3, 2)
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ReconcileLimited))
 	})
 
 	err = c.Delete(context.TODO(), mm4)
@@ -525,6 +690,7 @@ func TestReconcilingLimit(t *testing.T) {
 	_, err = r.Reconcile(context.Background(), req5)
 	require.NoError(t, err)
 	assertInstallationsCount(t, 1, 1)
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.ReconcileLimited))
 
 	t.Run("should add new installations to cache", func(t *testing.T) {
 		// Pick up first for reconciling
@@ -605,6 +771,14 @@ func prepareSchema(t *testing.T, scheme *runtime.Scheme) *runtime.Scheme {
 	require.NoError(t, err)
 	err = mysqlv1alpha1.SchemeBuilder.AddToScheme(scheme)
 	require.NoError(t, err)
+	err = cnpgv1.SchemeBuilder.AddToScheme(scheme)
+	require.NoError(t, err)
+	err = monitoringv1.SchemeBuilder.AddToScheme(scheme)
+	require.NoError(t, err)
+	err = gatewayv1.SchemeBuilder.AddToScheme(scheme)
+	require.NoError(t, err)
+	err = certmanagerv1.SchemeBuilder.AddToScheme(scheme)
+	require.NoError(t, err)
 
 	return scheme
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	mattermostpostgres "github.com/mattermost/mattermost-operator/pkg/components/postgres"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
 	mysqlv1alpha1 "github.com/mattermost/mattermost-operator/pkg/database/mysql_operator/v1alpha1"
 
 	"github.com/go-logr/logr"
@@ -43,10 +45,10 @@ func (r *MattermostReconciler) checkOperatorManagedDB(mattermost *mmv1beta.Matte
 	}
 
 	switch mattermost.Spec.Database.OperatorManaged.Type {
-	case "mysql":
+	case mmv1beta.DatabaseTypeMySQL:
 		return r.checkOperatorManagedMySQL(mattermost, reqLogger)
-	case "postgres":
-		return nil, errors.New("database type 'postgres' not yet implemented")
+	case mmv1beta.DatabaseTypePostgres:
+		return r.checkOperatorManagedPostgres(mattermost, reqLogger)
 	}
 
 	return nil, fmt.Errorf("database of type '%s' is not supported", mattermost.Spec.Database.OperatorManaged.Type)
@@ -85,3 +87,39 @@ func (r *MattermostReconciler) checkMySQLCluster(mattermost *mmv1beta.Mattermost
 
 	return r.Resources.Update(current, desired, reqLogger)
 }
+
+func (r *MattermostReconciler) checkOperatorManagedPostgres(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (mattermostApp.DatabaseConfig, error) {
+	reqLogger = reqLogger.WithValues("Reconcile", "postgres")
+
+	// The secret has to exist before the cluster is bootstrapped, as
+	// CloudNativePG reads the database owner credentials from it.
+	dbSecretName := mattermostpostgres.DefaultDatabaseSecretName(mattermost.Name)
+
+	dbSecret, err := r.Resources.GetOrCreatePostgresSecrets(mattermost, dbSecretName, reqLogger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get or create Postgres database secret")
+	}
+
+	err = r.checkPostgresCluster(mattermost, reqLogger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while checking Postgres cluster")
+	}
+
+	return mattermostApp.NewPostgresDBConfig(*dbSecret)
+}
+
+func (r *MattermostReconciler) checkPostgresCluster(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	desired := mattermostpostgres.ClusterV1Beta(mattermost)
+
+	err := r.Resources.CreatePostgresClusterIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &cnpgv1.Cluster{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current); err != nil {
+		return err
+	}
+
+	return r.Resources.Update(current, desired, reqLogger)
+}
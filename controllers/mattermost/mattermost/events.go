@@ -0,0 +1,26 @@
+package mattermost
+
+// Reasons of the events emitted on Mattermost resources.
+const (
+	eventReasonStateChanged        = "StateChanged"
+	eventReasonReconcileFailed     = "ReconcileFailed"
+	eventReasonUpdateJobLaunched   = "UpdateJobLaunched"
+	eventReasonUpdateJobRestarted  = "UpdateJobRestarted"
+	eventReasonUpdateJobFailed     = "UpdateJobFailed"
+	eventReasonUpdateJobSucceeded  = "UpdateJobSucceeded"
+	eventReasonServiceRecreated    = "ServiceRecreated"
+	eventReasonResourcePatchFailed = "ResourcePatchFailed"
+	eventReasonIngressDeleted      = "IngressDeleted"
+	eventReasonIngressClassDeleted = "IngressClassDeleted"
+	eventReasonRolloutStarted      = "RolloutStarted"
+	eventReasonRolloutPromoting    = "RolloutPromoting"
+	eventReasonRolloutSucceeded    = "RolloutSucceeded"
+	eventReasonRolloutAborted      = "RolloutAborted"
+	eventReasonRolledBack          = "RolledBack"
+	eventReasonDataRetained        = "DataRetained"
+	eventReasonSnapshotStarted     = "SnapshotStarted"
+	eventReasonDeletionBlocked     = "DeletionBlocked"
+	eventReasonUpgradeDeferred     = "UpgradeDeferred"
+	eventReasonUpgradeBlocked      = "UpgradeBlocked"
+	eventReasonImageTagMoved       = "ImageTagMoved"
+)
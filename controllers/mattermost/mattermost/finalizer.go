@@ -0,0 +1,221 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostMinio "github.com/mattermost/mattermost-operator/pkg/components/minio"
+	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	mattermostpostgres "github.com/mattermost/mattermost-operator/pkg/components/postgres"
+	"github.com/mattermost/mattermost-operator/pkg/components/utils"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
+	mysqlv1alpha1 "github.com/mattermost/mattermost-operator/pkg/database/mysql_operator/v1alpha1"
+	minioOperator "github.com/minio/minio-operator/pkg/apis/miniocontroller/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// checkFinalizer adds the finalizer to Mattermost installations whose data
+// has to be handled before they are deleted and removes it from the others.
+func (r *MattermostReconciler) checkFinalizer(ctx context.Context, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	needed := needsFinalizer(mattermost)
+	if needed == controllerutil.ContainsFinalizer(mattermost, mmv1beta.MattermostFinalizer) {
+		return nil
+	}
+
+	if needed {
+		reqLogger.Info("Adding finalizer")
+		controllerutil.AddFinalizer(mattermost, mmv1beta.MattermostFinalizer)
+	} else {
+		reqLogger.Info("Removing finalizer")
+		controllerutil.RemoveFinalizer(mattermost, mmv1beta.MattermostFinalizer)
+	}
+
+	err := r.Client.Update(ctx, mattermost)
+	if err != nil {
+		return errors.Wrap(err, "failed to update Mattermost finalizers")
+	}
+
+	return nil
+}
+
+// needsFinalizer returns true if the data of the Mattermost has to be handled
+// before it is deleted or if the Mattermost is protected from deletion. The
+// finalizer stops the deletion when the validating webhook is bypassed.
+func needsFinalizer(mattermost *mmv1beta.Mattermost) bool {
+	return mattermost.GetDeletionPolicy() != mmv1beta.DeletionPolicyDelete || mattermost.Spec.DeletionProtection
+}
+
+// finalizeMattermost handles the data of the deleted Mattermost according to
+// its deletion policy and removes the finalizer once it is done.
+func (r *MattermostReconciler) finalizeMattermost(ctx context.Context, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(mattermost, mmv1beta.MattermostFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	reqLogger = reqLogger.WithValues("Reconcile", "finalizer")
+
+	status := *mattermost.Status.DeepCopy()
+	status.State = mmv1beta.Deleting
+
+	// The protection is enforced by the validating webhook, the deletion is
+	// only blocked here if the webhook was bypassed.
+	if !mattermost.DeletionAllowed() {
+		message := mattermost.DeletionProtectedMessage()
+		if condition := status.GetCondition(mmv1beta.ConditionCleanupComplete); condition == nil || condition.Reason != mmv1beta.ReasonDeletionProtected {
+			r.Recorder.Event(mattermost, corev1.EventTypeWarning, eventReasonDeletionBlocked, message)
+		}
+		status.SetCondition(mmv1beta.ConditionCleanupComplete, metav1.ConditionFalse, mmv1beta.ReasonDeletionProtected, message)
+		return reconcile.Result{}, r.updateStatus(mattermost, status, reqLogger)
+	}
+
+	var done bool
+	var err error
+	switch mattermost.GetDeletionPolicy() {
+	case mmv1beta.DeletionPolicyRetain:
+		done, err = r.retainData(mattermost, &status, reqLogger)
+	case mmv1beta.DeletionPolicySnapshot:
+		done, err = r.snapshotData(ctx, mattermost, &status, reqLogger)
+	default:
+		done = true
+	}
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionCleanupComplete, metav1.ConditionFalse, mmv1beta.ReasonCleanupFailed, err.Error())
+		statusErr := r.updateStatus(mattermost, status, reqLogger)
+		if statusErr != nil {
+			reqLogger.Error(statusErr, "Error updating status")
+		}
+		return reconcile.Result{}, err
+	}
+
+	err = r.updateStatus(mattermost, status, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !done {
+		return reconcile.Result{RequeueAfter: resourcesReadyDelay}, nil
+	}
+
+	reqLogger.Info("Removing finalizer")
+	controllerutil.RemoveFinalizer(mattermost, mmv1beta.MattermostFinalizer)
+	err = r.Client.Update(ctx, mattermost)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to remove Mattermost finalizer")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// dataResource is a resource holding the data of the installation.
+type dataResource struct {
+	kind   string
+	name   string
+	object client.Object
+}
+
+// dataResources returns the resources managed by the operator which hold the
+// data of the installation. Resources not used by the installation are
+// skipped when they are retained.
+func dataResources(mattermost *mmv1beta.Mattermost) []dataResource {
+	dbName := utils.HashWithPrefix("db", mattermost.Name)
+
+	return []dataResource{
+		{kind: "MysqlCluster", name: dbName, object: &mysqlv1alpha1.MysqlCluster{}},
+		{kind: "Secret", name: mattermostmysql.DefaultDatabaseSecretName(mattermost.Name), object: &corev1.Secret{}},
+		{kind: "Cluster", name: dbName, object: &cnpgv1.Cluster{}},
+		{kind: "Secret", name: mattermostpostgres.DefaultDatabaseSecretName(mattermost.Name), object: &corev1.Secret{}},
+		{kind: "MinIOInstance", name: fmt.Sprintf("%s-minio", mattermost.Name), object: &minioOperator.MinIOInstance{}},
+		{kind: "Secret", name: mattermostMinio.DefaultMinioSecretName(mattermost.Name), object: &corev1.Secret{}},
+		{kind: "PersistentVolumeClaim", name: mattermost.Name, object: &corev1.PersistentVolumeClaim{}},
+	}
+}
+
+// retainData removes the owner references from the operator-managed database
+// and file store, so that they are not deleted together with the installation.
+func (r *MattermostReconciler) retainData(mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, reqLogger logr.Logger) (bool, error) {
+	var retained []string
+	for _, resource := range dataResources(mattermost) {
+		key := types.NamespacedName{Name: resource.name, Namespace: mattermost.Namespace}
+		found, err := r.Resources.RemoveOwnerReference(mattermost, key, resource.object, reqLogger)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to retain %s %s", resource.kind, resource.name)
+		}
+		if found {
+			retained = append(retained, fmt.Sprintf("%s %s", resource.kind, resource.name))
+		}
+	}
+
+	message := "No operator-managed data to retain"
+	if len(retained) > 0 {
+		message = fmt.Sprintf("Retained %s", strings.Join(retained, ", "))
+		r.Recorder.Event(mattermost, corev1.EventTypeNormal, eventReasonDataRetained, message)
+	}
+	status.SetCondition(mmv1beta.ConditionCleanupComplete, metav1.ConditionTrue, mmv1beta.ReasonDataRetained, message)
+
+	return true, nil
+}
+
+// snapshotData backs up the installation with a MattermostBackup and reports
+// whether the backup succeeded. The backup is not owned by the installation,
+// so it is kept after the installation is deleted.
+func (r *MattermostReconciler) snapshotData(ctx context.Context, mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, reqLogger logr.Logger) (bool, error) {
+	if mattermost.Spec.DeletionSnapshot == nil {
+		return false, errors.New("deletionSnapshot has to be set with Snapshot deletion policy")
+	}
+
+	snapshot := &mmv1beta.MattermostBackup{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: mattermost.DeletionSnapshotName(), Namespace: mattermost.Namespace}, snapshot)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		snapshot = deletionSnapshot(mattermost)
+		reqLogger.Info("Creating final snapshot", "name", snapshot.Name)
+		err = r.Client.Create(ctx, snapshot)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to create final snapshot")
+		}
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonSnapshotStarted, "Final snapshot %s started", snapshot.Name)
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to check if final snapshot exists")
+	}
+
+	if snapshot.CreationTimestamp.Before(&mattermost.CreationTimestamp) {
+		return false, fmt.Errorf("MattermostBackup %s was created before the installation, delete it to take the final snapshot", snapshot.Name)
+	}
+
+	switch snapshot.Status.Phase {
+	case mmv1beta.BackupSucceeded:
+		status.SetCondition(mmv1beta.ConditionCleanupComplete, metav1.ConditionTrue, mmv1beta.ReasonSnapshotSucceeded,
+			fmt.Sprintf("Final snapshot %s stored in %s", snapshot.Name, snapshot.Status.Location))
+		return true, nil
+	case mmv1beta.BackupFailed:
+		status.SetCondition(mmv1beta.ConditionCleanupComplete, metav1.ConditionFalse, mmv1beta.ReasonSnapshotFailed,
+			fmt.Sprintf("Final snapshot %s failed: %s. Delete the MattermostBackup to retry or change the deletion policy to finish the deletion", snapshot.Name, snapshot.Status.Message))
+		return false, nil
+	}
+
+	status.SetCondition(mmv1beta.ConditionCleanupComplete, metav1.ConditionFalse, mmv1beta.ReasonSnapshotRunning,
+		fmt.Sprintf("Waiting for final snapshot %s", snapshot.Name))
+	return false, nil
+}
+
+func deletionSnapshot(mattermost *mmv1beta.Mattermost) *mmv1beta.MattermostBackup {
+	return &mmv1beta.MattermostBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mattermost.DeletionSnapshotName(),
+			Namespace: mattermost.Namespace,
+			Labels:    mmv1beta.MattermostResourceLabels(mattermost.Name),
+		},
+		Spec: mmv1beta.MattermostBackupSpec{
+			MattermostName: mattermost.Name,
+			Destination:    mattermost.Spec.DeletionSnapshot.Destination,
+			DatabaseImage:  mattermost.Spec.DeletionSnapshot.DatabaseImage,
+		},
+	}
+}
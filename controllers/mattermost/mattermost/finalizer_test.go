@@ -0,0 +1,197 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	"github.com/mattermost/mattermost-operator/pkg/components/utils"
+	mysqlv1alpha1 "github.com/mattermost/mattermost-operator/pkg/database/mysql_operator/v1alpha1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func newFinalizerTestMattermost(policy mmv1beta.DeletionPolicy) *mmv1beta.Mattermost {
+	return &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Image:          "mattermost/mattermost-enterprise-edition",
+			Version:        operatortest.LatestStableMattermostVersion,
+			DeletionPolicy: policy,
+		},
+	}
+}
+
+func setupFinalizerTestDeps(t *testing.T) (logr.Logger, client.Client, *MattermostReconciler) {
+	logger, _, reconciler := setupTestDeps(t)
+	c := fake.NewClientBuilder().WithScheme(reconciler.Scheme).WithStatusSubresource(&mmv1beta.Mattermost{}, &mmv1beta.MattermostBackup{}).Build()
+	reconciler.Client = c
+	reconciler.Resources = resources.NewResourceHelper(c, reconciler.Scheme)
+	return logger, c, reconciler
+}
+
+// deleteMattermost marks the Mattermost as deleted. The Mattermost is kept
+// by the fake client until its finalizers are removed.
+func deleteMattermost(t *testing.T, c client.Client, mm *mmv1beta.Mattermost) *mmv1beta.Mattermost {
+	require.NoError(t, c.Delete(context.TODO(), mm))
+
+	deleted := &mmv1beta.Mattermost{}
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(mm), deleted))
+	require.False(t, deleted.DeletionTimestamp.IsZero())
+	return deleted
+}
+
+func TestCheckFinalizer(t *testing.T) {
+	logger, c, reconciler := setupFinalizerTestDeps(t)
+
+	mm := newFinalizerTestMattermost("")
+	require.NoError(t, c.Create(context.TODO(), mm))
+
+	err := reconciler.checkFinalizer(context.TODO(), mm, logger)
+	require.NoError(t, err)
+	assert.Empty(t, mm.Finalizers)
+
+	mm.Spec.DeletionPolicy = mmv1beta.DeletionPolicyRetain
+	err = reconciler.checkFinalizer(context.TODO(), mm, logger)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mmv1beta.MattermostFinalizer}, mm.Finalizers)
+
+	mm.Spec.DeletionPolicy = mmv1beta.DeletionPolicyDelete
+	err = reconciler.checkFinalizer(context.TODO(), mm, logger)
+	require.NoError(t, err)
+	assert.Empty(t, mm.Finalizers)
+}
+
+func TestFinalizeMattermostRetain(t *testing.T) {
+	logger, c, reconciler := setupFinalizerTestDeps(t)
+
+	mm := newFinalizerTestMattermost(mmv1beta.DeletionPolicyRetain)
+	controllerutil.AddFinalizer(mm, mmv1beta.MattermostFinalizer)
+	require.NoError(t, c.Create(context.TODO(), mm))
+
+	otherOwner := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: types.UID("other")}
+	cluster := &mysqlv1alpha1.MysqlCluster{ObjectMeta: metav1.ObjectMeta{Name: utils.HashWithPrefix("db", mm.Name), Namespace: mm.Namespace}}
+	dbSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: mattermostmysql.DefaultDatabaseSecretName(mm.Name), Namespace: mm.Namespace}}
+	for _, obj := range []client.Object{cluster, dbSecret} {
+		require.NoError(t, reconciler.Resources.Create(mm, obj, logger))
+	}
+	dbSecret.OwnerReferences = append(dbSecret.OwnerReferences, otherOwner)
+	require.NoError(t, c.Update(context.TODO(), dbSecret))
+
+	mm = deleteMattermost(t, c, mm)
+	_, err := reconciler.finalizeMattermost(context.TODO(), mm, logger)
+	require.NoError(t, err)
+
+	condition := mm.Status.GetCondition(mmv1beta.ConditionCleanupComplete)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, mmv1beta.ReasonDataRetained, condition.Reason)
+	assert.Contains(t, condition.Message, cluster.Name)
+
+	err = c.Get(context.TODO(), client.ObjectKeyFromObject(mm), &mmv1beta.Mattermost{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(cluster), cluster))
+	assert.Empty(t, cluster.OwnerReferences)
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(dbSecret), dbSecret))
+	assert.Equal(t, []metav1.OwnerReference{otherOwner}, dbSecret.OwnerReferences)
+}
+
+func TestFinalizeMattermostSnapshot(t *testing.T) {
+	logger, c, reconciler := setupFinalizerTestDeps(t)
+
+	mm := newFinalizerTestMattermost(mmv1beta.DeletionPolicySnapshot)
+	mm.Spec.DeletionSnapshot = &mmv1beta.DeletionSnapshot{
+		Destination: mmv1beta.BackupDestination{URL: "s3://backups/mattermost", Secret: "backup-credentials"},
+	}
+	controllerutil.AddFinalizer(mm, mmv1beta.MattermostFinalizer)
+	require.NoError(t, c.Create(context.TODO(), mm))
+	mm = deleteMattermost(t, c, mm)
+
+	result, err := reconciler.finalizeMattermost(context.TODO(), mm, logger)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+	assert.Equal(t, mmv1beta.Deleting, mm.Status.State)
+	condition := mm.Status.GetCondition(mmv1beta.ConditionCleanupComplete)
+	require.NotNil(t, condition)
+	assert.Equal(t, mmv1beta.ReasonSnapshotRunning, condition.Reason)
+
+	snapshot := &mmv1beta.MattermostBackup{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mm.DeletionSnapshotName(), Namespace: mm.Namespace}, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, mm.Name, snapshot.Spec.MattermostName)
+	assert.Equal(t, mm.Spec.DeletionSnapshot.Destination, snapshot.Spec.Destination)
+	assert.Empty(t, snapshot.OwnerReferences)
+
+	t.Run("failed snapshot keeps the finalizer", func(t *testing.T) {
+		snapshot.Status.Phase = mmv1beta.BackupFailed
+		require.NoError(t, c.Status().Update(context.TODO(), snapshot))
+
+		_, err := reconciler.finalizeMattermost(context.TODO(), mm, logger)
+		require.NoError(t, err)
+		condition := mm.Status.GetCondition(mmv1beta.ConditionCleanupComplete)
+		require.NotNil(t, condition)
+		assert.Equal(t, mmv1beta.ReasonSnapshotFailed, condition.Reason)
+		require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(mm), &mmv1beta.Mattermost{}))
+	})
+
+	t.Run("finalizer removed after snapshot", func(t *testing.T) {
+		snapshot.Status.Phase = mmv1beta.BackupSucceeded
+		snapshot.Status.Location = "s3://backups/mattermost/foo/1"
+		require.NoError(t, c.Status().Update(context.TODO(), snapshot))
+
+		_, err := reconciler.finalizeMattermost(context.TODO(), mm, logger)
+		require.NoError(t, err)
+		condition := mm.Status.GetCondition(mmv1beta.ConditionCleanupComplete)
+		require.NotNil(t, condition)
+		assert.Equal(t, mmv1beta.ReasonSnapshotSucceeded, condition.Reason)
+
+		err = c.Get(context.TODO(), client.ObjectKeyFromObject(mm), &mmv1beta.Mattermost{})
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+}
+
+func TestFinalizeMattermostDeletionProtection(t *testing.T) {
+	logger, c, reconciler := setupFinalizerTestDeps(t)
+
+	mm := newFinalizerTestMattermost("")
+	mm.Spec.DeletionProtection = true
+	require.NoError(t, c.Create(context.TODO(), mm))
+
+	err := reconciler.checkFinalizer(context.TODO(), mm, logger)
+	require.NoError(t, err)
+	require.Equal(t, []string{mmv1beta.MattermostFinalizer}, mm.Finalizers)
+
+	mm = deleteMattermost(t, c, mm)
+	_, err = reconciler.finalizeMattermost(context.TODO(), mm, logger)
+	require.NoError(t, err)
+
+	condition := mm.Status.GetCondition(mmv1beta.ConditionCleanupComplete)
+	require.NotNil(t, condition)
+	assert.Equal(t, mmv1beta.ReasonDeletionProtected, condition.Reason)
+	assert.Contains(t, condition.Message, "Mattermost default/foo is protected from deletion")
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(mm), mm))
+
+	mm.Annotations = map[string]string{mmv1beta.ConfirmDeletionAnnotation: string(mm.UID)}
+	require.NoError(t, c.Update(context.TODO(), mm))
+
+	_, err = reconciler.finalizeMattermost(context.TODO(), mm, logger)
+	require.NoError(t, err)
+	err = c.Get(context.TODO(), client.ObjectKeyFromObject(mm), &mmv1beta.Mattermost{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+}
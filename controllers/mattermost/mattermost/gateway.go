@@ -0,0 +1,107 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkMattermostHTTPRoute creates or updates the Gateway API HTTPRoute of
+// Mattermost and reports whether it is accepted by its Gateways. The
+// HTTPRoute is deleted if the Gateway API is not used.
+func (r *MattermostReconciler) checkMattermostHTTPRoute(mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, reqLogger logr.Logger) error {
+	desired := mattermostApp.GenerateHTTPRouteV1Beta(mattermost)
+
+	installed, err := r.gatewayAPIInstalled()
+	if err != nil {
+		return errors.Wrap(err, "failed to check if Gateway API is installed")
+	}
+
+	if !mattermost.GatewayEnabled() {
+		status.RemoveCondition(mmv1beta.ConditionHTTPRouteAccepted)
+		if !installed {
+			return nil
+		}
+		err = r.Resources.DeleteHTTPRoute(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete HTTPRoute")
+		}
+		return nil
+	}
+
+	if !installed {
+		return errors.New("Gateway API HTTPRoute CRD is not installed")
+	}
+
+	err = r.Resources.CreateHTTPRouteIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &gatewayv1.HTTPRoute{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	err = r.Resources.Update(current, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	setHTTPRouteAcceptedCondition(status, current, len(desired.Spec.ParentRefs))
+	return nil
+}
+
+// setHTTPRouteAcceptedCondition reports whether the route is accepted by all
+// of its Gateways, and whether all of its references are resolved.
+func setHTTPRouteAcceptedCondition(status *mmv1beta.MattermostStatus, route *gatewayv1.HTTPRoute, parents int) {
+	for _, parent := range route.Status.Parents {
+		for _, conditionType := range []string{gatewayv1.RouteConditionAccepted, gatewayv1.RouteConditionResolvedRefs} {
+			condition := meta.FindStatusCondition(parent.Conditions, conditionType)
+			if condition != nil && condition.Status == metav1.ConditionFalse {
+				message := fmt.Sprintf("Gateway %s: %s", parent.ParentRef.Name, condition.Message)
+				status.SetCondition(mmv1beta.ConditionHTTPRouteAccepted, metav1.ConditionFalse, mmv1beta.ReasonHTTPRouteNotAccepted, message)
+				return
+			}
+		}
+	}
+
+	accepted := 0
+	for _, parent := range route.Status.Parents {
+		if meta.IsStatusConditionTrue(parent.Conditions, gatewayv1.RouteConditionAccepted) {
+			accepted++
+		}
+	}
+	if accepted < parents {
+		message := fmt.Sprintf("HTTPRoute accepted by %d of %d Gateways", accepted, parents)
+		status.SetCondition(mmv1beta.ConditionHTTPRouteAccepted, metav1.ConditionFalse, mmv1beta.ReasonHTTPRoutePending, message)
+		return
+	}
+
+	status.SetCondition(mmv1beta.ConditionHTTPRouteAccepted, metav1.ConditionTrue, mmv1beta.ReasonHTTPRouteAccepted, "HTTPRoute is accepted by all Gateways")
+}
+
+// gatewayAPIInstalled returns true if the HTTPRoute CRD of the Gateway API is
+// installed in the cluster.
+func (r *MattermostReconciler) gatewayAPIInstalled() (bool, error) {
+	gk := schema.GroupKind{Group: gatewayv1.SchemeGroupVersion.Group, Kind: gatewayv1.HTTPRouteKind}
+	_, err := r.Client.RESTMapper().RESTMapping(gk, gatewayv1.SchemeGroupVersion.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,127 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckMattermostHTTPRoute(t *testing.T) {
+	mmName := "foo"
+	mmNamespace := "default"
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: operatortest.LatestStableMattermostVersion,
+			Ingress: &mmv1beta.Ingress{Host: "foo.mattermost.dev"},
+			Gateway: &mmv1beta.Gateway{
+				Enabled:    true,
+				ParentRefs: []mmv1beta.GatewayParentReference{{Name: "public"}},
+			},
+		},
+	}
+
+	t.Run("fails without Gateway API", func(t *testing.T) {
+		logger, _, reconciler := setupTestDeps(t)
+
+		err := reconciler.checkMattermostHTTPRoute(mm, &mmv1beta.MattermostStatus{}, logger)
+		require.Error(t, err)
+	})
+
+	logger, _, reconciler := setupTestDeps(t)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(gatewayv1.SchemeGroupVersion.WithKind(gatewayv1.HTTPRouteKind), meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithScheme(reconciler.Scheme).WithRESTMapper(mapper).WithStatusSubresource(&gatewayv1.HTTPRoute{}).Build()
+	reconciler.Client = c
+	reconciler.Resources = resources.NewResourceHelper(c, reconciler.Scheme)
+
+	getHTTPRoute := func() (*gatewayv1.HTTPRoute, error) {
+		route := &gatewayv1.HTTPRoute{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, route)
+		return route, err
+	}
+	setParentCondition := func(t *testing.T, status metav1.ConditionStatus, message string) {
+		route, err := getHTTPRoute()
+		require.NoError(t, err)
+		route.Status.Parents = []gatewayv1.RouteParentStatus{
+			{
+				ParentRef:      route.Spec.ParentRefs[0],
+				ControllerName: "example.com/gateway-controller",
+				Conditions: []metav1.Condition{
+					{Type: gatewayv1.RouteConditionAccepted, Status: status, Reason: "Test", Message: message},
+				},
+			},
+		}
+		require.NoError(t, c.Status().Update(context.TODO(), route))
+	}
+
+	t.Run("created and pending", func(t *testing.T) {
+		status := &mmv1beta.MattermostStatus{}
+		err := reconciler.checkMattermostHTTPRoute(mm, status, logger)
+		require.NoError(t, err)
+
+		route, err := getHTTPRoute()
+		require.NoError(t, err)
+		require.Len(t, route.OwnerReferences, 1)
+		assert.Equal(t, mmName, route.OwnerReferences[0].Name)
+		assert.Equal(t, []gatewayv1.Hostname{"foo.mattermost.dev"}, route.Spec.Hostnames)
+
+		condition := status.GetCondition(mmv1beta.ConditionHTTPRouteAccepted)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, mmv1beta.ReasonHTTPRoutePending, condition.Reason)
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		setParentCondition(t, metav1.ConditionFalse, "hostname not allowed")
+
+		status := &mmv1beta.MattermostStatus{}
+		err := reconciler.checkMattermostHTTPRoute(mm, status, logger)
+		require.NoError(t, err)
+
+		condition := status.GetCondition(mmv1beta.ConditionHTTPRouteAccepted)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, mmv1beta.ReasonHTTPRouteNotAccepted, condition.Reason)
+		assert.Equal(t, "Gateway public: hostname not allowed", condition.Message)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		setParentCondition(t, metav1.ConditionTrue, "")
+
+		status := &mmv1beta.MattermostStatus{}
+		err := reconciler.checkMattermostHTTPRoute(mm, status, logger)
+		require.NoError(t, err)
+
+		assert.True(t, status.IsConditionTrue(mmv1beta.ConditionHTTPRouteAccepted))
+	})
+
+	t.Run("deleted when disabled", func(t *testing.T) {
+		mm.Spec.Gateway.Enabled = false
+
+		status := &mmv1beta.MattermostStatus{}
+		status.SetCondition(mmv1beta.ConditionHTTPRouteAccepted, metav1.ConditionTrue, mmv1beta.ReasonHTTPRouteAccepted, "")
+		err := reconciler.checkMattermostHTTPRoute(mm, status, logger)
+		require.NoError(t, err)
+
+		_, err = getHTTPRoute()
+		assert.True(t, k8sErrors.IsNotFound(err))
+		assert.Nil(t, status.GetCondition(mmv1beta.ConditionHTTPRouteAccepted))
+	})
+}
@@ -1,12 +1,18 @@
 package mattermost
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
 	"github.com/mattermost/mattermost-operator/pkg/mattermost/healthcheck"
+	"github.com/mattermost/mattermost-operator/pkg/metrics"
 	"github.com/pkg/errors"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -24,7 +30,15 @@ func (r *MattermostReconciler) checkMattermostHealth(mattermost *mmv1beta.Matter
 		UpdatedReplicas:    0,
 		// Rewrite Resource Patch status to not lose it.
 		// It is cleared when appropriate by resource patch logic.
-		ResourcePatch: currentStatus.ResourcePatch,
+		ResourcePatch:         currentStatus.ResourcePatch,
+		Conditions:            currentStatus.Conditions,
+		LastGoodImage:         currentStatus.LastGoodImage,
+		Rollout:               currentStatus.Rollout,
+		CertificateNotAfter:   currentStatus.CertificateNotAfter,
+		PendingUpgrade:        currentStatus.PendingUpgrade,
+		NextMaintenanceWindow: currentStatus.NextMaintenanceWindow,
+		ImageDigest:           currentStatus.ImageDigest,
+		Plugins:               currentStatus.Plugins,
 	}
 
 	labels := mattermost.MattermostPodLabels(mattermost.Name)
@@ -37,23 +51,36 @@ func (r *MattermostReconciler) checkMattermostHealth(mattermost *mmv1beta.Matter
 
 	podsStatus, err := healthChecker.CheckReplicaSetRollout(mattermost.Name, mattermost.Namespace)
 	if err != nil {
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, "Rollout not yet started")
 		return status, errors.Wrap(err, "rollout not yet started")
 	}
 
 	status.UpdatedReplicas = podsStatus.UpdatedReplicas
 	status.Replicas = podsStatus.Replicas
 
-	var replicas int32 = 1
-	if mattermost.Spec.Replicas != nil {
-		replicas = *mattermost.Spec.Replicas
-	}
+	replicas := r.getMattermostReplicas(mattermost)
+
+	metrics.SetInstallationReplicas(mattermost.Namespace, mattermost.Name, replicas, podsStatus.UpdatedReplicas)
+	status.Plugins = pluginStatuses(mattermost, currentStatus.Plugins,
+		replicas > 0 && podsStatus.UpdatedReplicas == replicas && podsStatus.Replicas == replicas)
 
 	if replicas > 0 && podsStatus.UpdatedReplicas == 0 {
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, "Mattermost pods not yet updated")
 		return status, fmt.Errorf("mattermost pods not yet updated")
 	}
 
 	status.Image = mattermost.Spec.Image
 	status.Version = mattermost.Spec.Version
+	if currentStatus.ImageDigest != nil {
+		// The digest the version is pinned to is reported separately.
+		status.Version = strings.TrimSuffix(status.Version, "@"+currentStatus.ImageDigest.Digest)
+	}
+	if currentStatus.Rollout.IsAbortedFor(mattermost.GetImageName(), mattermost.Generation) || currentStatus.PendingUpgrade != nil {
+		// The stable image keeps running after the aborted rollout and
+		// until the maintenance window.
+		status.Image = currentStatus.Image
+		status.Version = currentStatus.Version
+	}
 
 	status.Endpoint = "not available"
 	var endpoint string
@@ -66,8 +93,20 @@ func (r *MattermostReconciler) checkMattermostHealth(mattermost *mmv1beta.Matter
 	} else if mattermost.IngressEnabled() {
 		endpoint, err = healthChecker.CheckIngressLoadBalancer()
 		if err != nil {
+			status.SetCondition(mmv1beta.ConditionIngressReady, metav1.ConditionFalse, mmv1beta.ReasonLoadBalancerPending, err.Error())
 			return status, errors.Wrap(err, "failed to check ingress load balancer")
 		}
+	} else if mattermost.GatewayEnabled() {
+		if !status.IsConditionTrue(mmv1beta.ConditionHTTPRouteAccepted) {
+			return status, errors.New("HTTPRoute not yet accepted by the Gateways")
+		}
+		endpoint = mattermost.GetIngressHost()
+	}
+	if mattermost.CertManagerEnabled() && !status.IsConditionTrue(mmv1beta.ConditionCertificateReady) {
+		return status, errors.New("TLS certificate not yet ready")
+	}
+	if !mattermost.Spec.UseServiceLoadBalancer && (mattermost.IngressEnabled() || mattermost.AWSLoadBalancerEnabled()) {
+		status.SetCondition(mmv1beta.ConditionIngressReady, metav1.ConditionTrue, mmv1beta.ReasonIngressReconciled, "Ingress is ready")
 	}
 
 	if endpoint != "" {
@@ -81,25 +120,54 @@ func (r *MattermostReconciler) checkMattermostHealth(mattermost *mmv1beta.Matter
 	}
 
 	if podsStatus.UpdatedReplicas != replicas {
-		return status, fmt.Errorf("found %d updated replicas, but wanted %d", podsStatus.UpdatedReplicas, replicas)
+		err = fmt.Errorf("found %d updated replicas, but wanted %d", podsStatus.UpdatedReplicas, replicas)
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, err.Error())
+		return status, err
 	}
 	if podsStatus.Replicas != replicas {
-		return status, fmt.Errorf("found %d pods, but wanted %d", podsStatus.Replicas, replicas)
+		err = fmt.Errorf("found %d pods, but wanted %d", podsStatus.Replicas, replicas)
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, err.Error())
+		return status, err
 	}
+	status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionTrue, mmv1beta.ReasonRolloutComplete, "All Mattermost pods are updated")
 
 	if mattermost.Spec.JobServer != nil && mattermost.Spec.JobServer.DedicatedJobServer {
 		err = r.checkMattermostJobServerHealth(mattermost, logger)
 		if err != nil {
+			status.SetCondition(mmv1beta.ConditionJobServerReady, metav1.ConditionFalse, mmv1beta.ReasonJobServerRolloutPending, err.Error())
 			return status, errors.Wrap(err, "failed to check job server health")
 		}
+		status.SetCondition(mmv1beta.ConditionJobServerReady, metav1.ConditionTrue, mmv1beta.ReasonJobServerRolloutComplete, "Dedicated job server is rolled out")
 	}
 
 	// Everything checks out. The installation is stable.
 	status.State = mmv1beta.Stable
+	if !currentStatus.Rollout.IsAbortedFor(mattermost.GetImageName(), mattermost.Generation) && currentStatus.PendingUpgrade == nil {
+		status.LastGoodImage = mattermost.GetImageName()
+	}
 
 	return status, nil
 }
 
+// getMattermostReplicas returns the number of replicas the Mattermost
+// deployment is expected to run. When autoscaling is enabled it is the
+// number of replicas desired by the HorizontalPodAutoscaler.
+func (r *MattermostReconciler) getMattermostReplicas(mattermost *mmv1beta.Mattermost) int32 {
+	if mattermost.AutoscalingEnabled() {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: mattermost.Name, Namespace: mattermost.Namespace}, hpa)
+		if err == nil && hpa.Status.DesiredReplicas > 0 {
+			return hpa.Status.DesiredReplicas
+		}
+		return mattermost.GetAutoscalingMinReplicas()
+	}
+
+	if mattermost.Spec.Replicas != nil {
+		return *mattermost.Spec.Replicas
+	}
+	return 1
+}
+
 func (r *MattermostReconciler) checkMattermostJobServerHealth(mattermost *mmv1beta.Mattermost, logger logr.Logger) error {
 	labels := mattermost.MattermostJobServerPodLabels(mattermost.Name)
 	listOptions := []client.ListOption{
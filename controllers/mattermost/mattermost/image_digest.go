@@ -0,0 +1,100 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/registry"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// imageDigestRetryDelay is the minimum delay before the tag is resolved
+// again, also after the registry failed to resolve it.
+const imageDigestRetryDelay = time.Minute
+
+// pinImageDigest returns the version the installation is reconciled with,
+// pinned to the digest of the image if digest pinning is enabled. The tag is
+// resolved again once the refresh interval passed, and a moved tag is rolled
+// out like a new version.
+func (r *MattermostReconciler) pinImageDigest(ctx context.Context, mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, reqLogger logr.Logger) (string, error) {
+	version := mattermost.Spec.Version
+	if !mattermost.ImageDigestPinningEnabled() {
+		status.ImageDigest = nil
+		status.RemoveCondition(mmv1beta.ConditionImageDigestResolved)
+		return version, nil
+	}
+
+	image := mattermost.GetImageName()
+	current := status.ImageDigest
+	if current != nil && current.Image == image &&
+		time.Since(current.ResolvedTime.Time) < mattermost.Spec.ImageDigestPinning.GetRefreshInterval() {
+		return version + "@" + current.Digest, nil
+	}
+
+	digest, err := r.resolveImageDigest(ctx, mattermost, image)
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionImageDigestResolved, metav1.ConditionFalse, mmv1beta.ReasonImageDigestResolveFailed, err.Error())
+		if current == nil || current.Image != image {
+			return "", errors.Wrapf(err, "failed to resolve digest of image %s", image)
+		}
+		// The digest resolved before keeps running until the registry is
+		// available again.
+		reqLogger.Error(err, "Failed to resolve image digest, keeping the current digest", "image", image, "digest", current.Digest)
+		return version + "@" + current.Digest, nil
+	}
+
+	if current != nil && current.Image == image && current.Digest != digest {
+		reqLogger.Info("Image tag moved to a new digest", "image", image, "digest", digest, "previousDigest", current.Digest)
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonImageTagMoved,
+			"Tag of image %s moved from %s to %s", image, current.Digest, digest)
+	}
+	status.ImageDigest = &mmv1beta.ImageDigestStatus{
+		Image:        image,
+		Digest:       digest,
+		ResolvedTime: metav1.Now(),
+	}
+	status.SetCondition(mmv1beta.ConditionImageDigestResolved, metav1.ConditionTrue, mmv1beta.ReasonImageDigestResolved,
+		fmt.Sprintf("Image %s resolved to %s", image, digest))
+
+	return version + "@" + digest, nil
+}
+
+// resolveImageDigest resolves the image with the credentials of the image
+// pull secrets. Missing secrets are skipped, like by the kubelet.
+func (r *MattermostReconciler) resolveImageDigest(ctx context.Context, mattermost *mmv1beta.Mattermost, image string) (string, error) {
+	var secrets []corev1.Secret
+	for _, ref := range mattermost.Spec.ImagePullSecrets {
+		secret := corev1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: mattermost.Namespace, Name: ref.Name}, &secret)
+		if err != nil {
+			if k8sErrors.IsNotFound(err) {
+				continue
+			}
+			return "", errors.Wrapf(err, "failed to get image pull secret %s", ref.Name)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	credentials, err := registry.CredentialsFromSecrets(secrets)
+	if err != nil {
+		return "", err
+	}
+	return r.Registry.ResolveDigest(ctx, image, credentials)
+}
+
+// imageDigestRefreshDelay returns the time until the pinned image tag has to
+// be resolved again.
+func imageDigestRefreshDelay(mattermost *mmv1beta.Mattermost, status mmv1beta.MattermostStatus) time.Duration {
+	if mattermost.Spec.ImageDigestPinning == nil || status.ImageDigest == nil {
+		return 0
+	}
+	refreshAt := status.ImageDigest.ResolvedTime.Add(mattermost.Spec.ImageDigestPinning.GetRefreshInterval())
+	return max(time.Until(refreshAt), imageDigestRetryDelay)
+}
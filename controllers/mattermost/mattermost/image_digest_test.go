@@ -0,0 +1,145 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/registry"
+	"github.com/mattermost/mattermost-operator/pkg/registry/registrytest"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const testRepository = "mattermost/mattermost-enterprise-edition"
+
+func TestPinImageDigest(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+	server := registrytest.NewServer()
+	defer server.Close()
+	server.Username, server.Password = "robot", "secret"
+	reconciler.Registry = registry.NewClient(server.Client())
+
+	err := reconciler.Client.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"` + server.Host() + `":{"username":"robot","password":"secret"}}}`),
+		},
+	})
+	require.NoError(t, err)
+
+	digest := server.Push(testRepository, "10.5.2", "first")
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyRollingUpdate)
+	mm.Spec.Image = server.Host() + "/" + testRepository
+	mm.Spec.Version = "10.5.2"
+	mm.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "missing"}, {Name: "registry"}}
+	mm.Spec.ImageDigestPinning = &mmv1beta.ImageDigestPinning{Enabled: true}
+	status := &mmv1beta.MattermostStatus{}
+
+	version, err := reconciler.pinImageDigest(context.TODO(), mm, status, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "10.5.2@"+digest, version)
+	require.NotNil(t, status.ImageDigest)
+	assert.Equal(t, server.Image(testRepository, "10.5.2"), status.ImageDigest.Image)
+	assert.Equal(t, digest, status.ImageDigest.Digest)
+	assert.True(t, status.IsConditionTrue(mmv1beta.ConditionImageDigestResolved))
+	assert.Greater(t, imageDigestRefreshDelay(mm, *status), 59*time.Minute)
+
+	t.Run("digest is kept within the refresh interval", func(t *testing.T) {
+		requests := server.Requests()
+		server.Push(testRepository, "10.5.2", "second")
+
+		version, err := reconciler.pinImageDigest(context.TODO(), mm, status, logger)
+		require.NoError(t, err)
+		assert.Equal(t, "10.5.2@"+digest, version)
+		assert.Equal(t, requests, server.Requests())
+	})
+
+	t.Run("moved tag is resolved after the refresh interval", func(t *testing.T) {
+		status.ImageDigest.ResolvedTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+
+		version, err := reconciler.pinImageDigest(context.TODO(), mm, status, logger)
+		require.NoError(t, err)
+		digest = registrytest.Digest("second")
+		assert.Equal(t, "10.5.2@"+digest, version)
+		assert.Equal(t, digest, status.ImageDigest.Digest)
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		unknown := mm.DeepCopy()
+		unknown.Spec.Version = "10.6.0"
+		unknownStatus := status.DeepCopy()
+
+		_, err := reconciler.pinImageDigest(context.TODO(), unknown, unknownStatus, logger)
+		assert.Error(t, err)
+		assert.False(t, unknownStatus.IsConditionTrue(mmv1beta.ConditionImageDigestResolved))
+	})
+
+	t.Run("digest is kept if the registry is unavailable", func(t *testing.T) {
+		unavailable := status.DeepCopy()
+		unavailable.ImageDigest.ResolvedTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		reconciler.Registry = registry.NewClient(nil)
+
+		version, err := reconciler.pinImageDigest(context.TODO(), mm, unavailable, logger)
+		require.NoError(t, err)
+		assert.Equal(t, "10.5.2@"+digest, version)
+		assert.False(t, unavailable.IsConditionTrue(mmv1beta.ConditionImageDigestResolved))
+		assert.Equal(t, imageDigestRetryDelay, imageDigestRefreshDelay(mm, *unavailable))
+	})
+
+	t.Run("pinning disabled", func(t *testing.T) {
+		mm.Spec.ImageDigestPinning.Enabled = false
+
+		version, err := reconciler.pinImageDigest(context.TODO(), mm, status, logger)
+		require.NoError(t, err)
+		assert.Equal(t, "10.5.2", version)
+		assert.Nil(t, status.ImageDigest)
+		assert.Nil(t, status.GetCondition(mmv1beta.ConditionImageDigestResolved))
+	})
+}
+
+func TestCheckMattermostPinnedImage(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+	server := registrytest.NewServer()
+	defer server.Close()
+	reconciler.Registry = registry.NewClient(server.Client())
+
+	digest := server.Push(testRepository, "10.5.2", "first")
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyRollingUpdate)
+	mm.Spec.Image = server.Host() + "/" + testRepository
+	mm.Spec.UpdateJob = nil
+	mm.Spec.ImageDigestPinning = &mmv1beta.ImageDigestPinning{Enabled: true}
+	status := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	pinned := mm.DeepCopy()
+	pinned.Spec.Version = "10.5.2"
+	pinned.Spec.Version, _ = reconciler.pinImageDigest(context.TODO(), pinned, status, logger)
+	_, err := reconciler.checkMattermostDeployment(pinned, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	assert.Equal(t, server.Image(testRepository, "10.5.2")+"@"+digest, getAppImage(t, reconciler, mm.Name))
+
+	// The moved tag is verified by the update job with the new digest.
+	moved := server.Push(testRepository, "10.5.2", "second")
+	status.ImageDigest.ResolvedTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	pinned.Spec.Version = "10.5.2"
+	pinned.Spec.Version, err = reconciler.pinImageDigest(context.TODO(), pinned, status, logger)
+	require.NoError(t, err)
+
+	recStatus, err := reconciler.checkMattermostDeployment(pinned, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	assert.False(t, recStatus.ResourcesReady)
+	assert.Equal(t, server.Image(testRepository, "10.5.2")+"@"+digest, getAppImage(t, reconciler, mm.Name))
+
+	job := &batchv1.Job{}
+	err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: resources.UpdateJobName, Namespace: "default"}, job)
+	require.NoError(t, err)
+	assert.Equal(t, server.Image(testRepository, "10.5.2")+"@"+moved, job.Spec.Template.Spec.Containers[0].Image)
+}
@@ -0,0 +1,77 @@
+package mattermost
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkMaintenanceWindow returns true if the image change has to wait for the
+// next maintenance window. The deferred image change and the start of the
+// next window are recorded in the status.
+func (r *MattermostReconciler) checkMaintenanceWindow(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	sameImage bool,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (bool, error) {
+	window := mattermost.Spec.MaintenanceWindow
+	if window == nil {
+		status.PendingUpgrade = nil
+		status.NextMaintenanceWindow = nil
+		return false, nil
+	}
+
+	inWindow, next, err := window.Check(time.Now())
+	if err != nil {
+		return false, errors.Wrap(err, "invalid maintenance window")
+	}
+	status.NextMaintenanceWindow = &metav1.Time{Time: next}
+
+	targetImage := mmv1beta.GetMattermostAppContainerFromDeployment(desired).Image
+	if sameImage || inWindow || upgradeStarted(status) ||
+		status.Rollout.IsAbortedFor(targetImage, mattermost.Generation) ||
+		mattermost.UpgradeForced(targetImage) {
+		status.PendingUpgrade = nil
+		return false, nil
+	}
+
+	if status.PendingUpgrade == nil || status.PendingUpgrade.Image != targetImage {
+		reqLogger.Info("Deferring image change until the next maintenance window", "image", targetImage, "window", next)
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpgradeDeferred,
+			"Upgrade to %s deferred until the maintenance window starting at %s", targetImage, next.Format(time.RFC3339))
+		status.PendingUpgrade = &mmv1beta.PendingUpgrade{
+			Image:        targetImage,
+			CurrentImage: mmv1beta.GetMattermostAppContainerFromDeployment(current).Image,
+			Since:        metav1.Now(),
+		}
+	}
+
+	return true, nil
+}
+
+// upgradeStarted returns true if the new image is being verified or rolled
+// out. Started upgrades are finished even after the window ends.
+func upgradeStarted(status *mmv1beta.MattermostStatus) bool {
+	if status.Rollout.IsActive() {
+		return true
+	}
+	condition := status.GetCondition(mmv1beta.ConditionUpdateJobSucceeded)
+	return condition != nil && condition.Reason == mmv1beta.ReasonUpdateJobRunning
+}
+
+// maintenanceWindowDelay returns the time until the deferred image change
+// can be applied.
+func maintenanceWindowDelay(status mmv1beta.MattermostStatus) time.Duration {
+	if status.PendingUpgrade == nil || status.NextMaintenanceWindow == nil {
+		return 0
+	}
+	return max(time.Until(status.NextMaintenanceWindow.Time), time.Second)
+}
@@ -0,0 +1,92 @@
+package mattermost
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newMaintenanceWindow returns a daily window starting at the given hour
+// offset from now.
+func newMaintenanceWindow(startOffset, duration time.Duration) *mmv1beta.MaintenanceWindow {
+	start := time.Now().UTC().Add(startOffset)
+	return &mmv1beta.MaintenanceWindow{
+		Schedule: fmt.Sprintf("0 %d * * *", start.Hour()),
+		Duration: metav1.Duration{Duration: duration},
+	}
+}
+
+func TestCheckMattermostMaintenanceWindow(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyRollingUpdate)
+	mm.Spec.MaintenanceWindow = newMaintenanceWindow(2*time.Hour, time.Hour)
+	status := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	_, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	assert.Nil(t, status.PendingUpgrade)
+	require.NotNil(t, status.NextMaintenanceWindow)
+	assert.True(t, status.NextMaintenanceWindow.After(time.Now()))
+
+	currentImage := getAppImage(t, reconciler, mm.Name)
+	mm.Spec.Version = "10.0.0"
+	targetImage := mm.GetImageName()
+
+	t.Run("image change deferred outside of the window", func(t *testing.T) {
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Nil(t, status.Rollout)
+		assert.Equal(t, currentImage, getAppImage(t, reconciler, mm.Name))
+
+		require.NotNil(t, status.PendingUpgrade)
+		assert.Equal(t, targetImage, status.PendingUpgrade.Image)
+		assert.Equal(t, currentImage, status.PendingUpgrade.CurrentImage)
+		assert.Greater(t, maintenanceWindowDelay(*status), time.Hour)
+	})
+
+	t.Run("forced image change", func(t *testing.T) {
+		mm.Annotations = map[string]string{mmv1beta.ForceUpgradeAnnotation: targetImage}
+
+		_, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.Nil(t, status.PendingUpgrade)
+		assert.Zero(t, maintenanceWindowDelay(*status))
+		require.NotNil(t, status.Rollout)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+	})
+
+	t.Run("started upgrade is not deferred", func(t *testing.T) {
+		mm.Annotations = nil
+
+		_, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.Nil(t, status.PendingUpgrade)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+	})
+}
+
+func TestCheckMattermostWithinMaintenanceWindow(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyRollingUpdate)
+	mm.Spec.MaintenanceWindow = newMaintenanceWindow(0, 2*time.Hour)
+	status := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	_, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+
+	mm.Spec.Version = "10.0.0"
+	_, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	assert.Nil(t, status.PendingUpgrade)
+	assert.Equal(t, mm.GetImageName(), getAppImage(t, reconciler, mm.Name))
+}
@@ -3,6 +3,7 @@ package mattermost
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/mattermost/mattermost-operator/pkg/resources"
 
@@ -10,11 +11,14 @@ import (
 	"github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
 	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
 	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	"github.com/mattermost/mattermost-operator/pkg/metrics"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +43,11 @@ func (r *MattermostReconciler) checkMattermost(
 		return reconcileStatus{}, errors.Wrap(err, "failed to check mattermost license secret.")
 	}
 
+	err = r.checkElasticSearch(mattermost)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to check elasticsearch secrets")
+	}
+
 	err = r.checkMattermostService(mattermost, status, reqLogger)
 	if err != nil {
 		return reconcileStatus{}, err
@@ -52,14 +61,60 @@ func (r *MattermostReconciler) checkMattermost(
 	if !mattermost.Spec.UseServiceLoadBalancer {
 		err = r.checkMattermostIngressClass(mattermost, reqLogger)
 		if err != nil {
+			status.SetCondition(mmv1beta.ConditionIngressReady, metav1.ConditionFalse, mmv1beta.ReasonIngressCheckFailed, err.Error())
 			return reconcileStatus{}, err
 		}
 
 		err = r.checkMattermostIngress(mattermost, reqLogger)
 		if err != nil {
+			status.SetCondition(mmv1beta.ConditionIngressReady, metav1.ConditionFalse, mmv1beta.ReasonIngressCheckFailed, err.Error())
 			return reconcileStatus{}, err
 		}
 	}
+	if mattermost.Spec.UseServiceLoadBalancer || (!mattermost.IngressEnabled() && !mattermost.AWSLoadBalancerEnabled()) {
+		status.SetCondition(mmv1beta.ConditionIngressReady, metav1.ConditionTrue, mmv1beta.ReasonIngressDisabled, "Ingress is not used")
+	} else if !status.IsConditionTrue(mmv1beta.ConditionIngressReady) {
+		// Readiness of the load balancer is confirmed by the health check.
+		status.SetCondition(mmv1beta.ConditionIngressReady, metav1.ConditionFalse, mmv1beta.ReasonLoadBalancerPending, "Ingress reconciled, waiting for load balancer")
+	}
+
+	err = r.checkMattermostHTTPRoute(mattermost, status, reqLogger)
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionHTTPRouteAccepted, metav1.ConditionFalse, mmv1beta.ReasonHTTPRouteCheckFailed, err.Error())
+		return reconcileStatus{}, err
+	}
+
+	err = r.checkMattermostCertificate(mattermost, status, reqLogger)
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionCertificateReady, metav1.ConditionFalse, mmv1beta.ReasonCertificateCheckFailed, err.Error())
+		return reconcileStatus{}, err
+	}
+
+	err = r.checkMattermostHorizontalPodAutoscaler(mattermost, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, err
+	}
+
+	err = r.checkMattermostNetworkPolicy(mattermost, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, err
+	}
+
+	err = r.checkMattermostMonitoring(mattermost, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, err
+	}
+
+	replicas := int32(1)
+	if mattermost.AutoscalingEnabled() {
+		replicas = mattermost.GetAutoscalingMinReplicas()
+	} else if mattermost.Spec.Replicas != nil {
+		replicas = *mattermost.Spec.Replicas
+	}
+	err = r.checkMattermostPodDisruptionBudget(mattermost, mattermost.Name, mmv1beta.MattermostSelectorLabels(mattermost.Name), replicas, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, err
+	}
 
 	return r.checkMattermostDeployment(mattermost, dbInfo, fsConfig, status, reqLogger)
 }
@@ -71,6 +126,25 @@ func (r *MattermostReconciler) checkLicence(mattermost *mmv1beta.Mattermost) err
 	return r.assertSecretContains(mattermost.Spec.LicenseSecret, "license", mattermost.Namespace)
 }
 
+func (r *MattermostReconciler) checkElasticSearch(mattermost *mmv1beta.Mattermost) error {
+	es := mattermost.Spec.ElasticSearch
+	if es.Host == "" {
+		return nil
+	}
+	if es.Secret != "" {
+		for _, key := range []string{mattermostApp.ElasticSearchSecretUsernameKey, mattermostApp.ElasticSearchSecretPasswordKey} {
+			err := r.assertSecretContains(es.Secret, key, mattermost.Namespace)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if es.CASecret != "" {
+		return r.assertSecretContains(es.CASecret, mattermostApp.ElasticSearchSecretCAKey, mattermost.Namespace)
+	}
+	return nil
+}
+
 func (r *MattermostReconciler) checkMattermostService(
 	mattermost *mmv1beta.Mattermost,
 	status *mmv1beta.MattermostStatus,
@@ -80,6 +154,7 @@ func (r *MattermostReconciler) checkMattermostService(
 	patchedObj, applied, err := mattermost.Spec.ResourcePatch.ApplyToService(desired)
 	if err != nil {
 		reqLogger.Error(err, "Failed to patch service")
+		r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonResourcePatchFailed, "Failed to apply patch to Service: %s", err)
 		status.SetServicePatchStatus(false, errors.Wrap(err, "failed to apply patch to Service"))
 	} else if applied {
 		reqLogger.Info("Applied patch to service")
@@ -107,6 +182,7 @@ func (r *MattermostReconciler) checkMattermostService(
 			return errors.Wrap(err, "failed to delete service")
 		}
 		reqLogger.Info("Creating service", "name", desired.Name)
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonServiceRecreated, "Recreated Service %s due to type change from %s to %s", desired.Name, current.Spec.Type, desired.Spec.Type)
 
 		return r.Resources.Create(mattermost, desired, reqLogger)
 	}
@@ -195,10 +271,16 @@ func (r *MattermostReconciler) checkMattermostIngress(mattermost *mmv1beta.Matte
 	}
 
 	if !mattermost.IngressEnabled() && !mattermost.AWSLoadBalancerEnabled() {
-		err := r.Resources.DeleteIngress(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		key := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
+		err := r.Client.Get(context.TODO(), key, &networkingv1.Ingress{})
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		err = r.Resources.DeleteIngress(key, reqLogger)
 		if err != nil {
 			return errors.Wrap(err, "failed to delete disabled ingress")
 		}
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonIngressDeleted, "Deleted disabled Ingress %s", desired.Name)
 		return nil
 	}
 
@@ -220,10 +302,16 @@ func (r *MattermostReconciler) checkMattermostIngressClass(mattermost *mmv1beta.
 	desired := mattermostApp.GenerateALBIngressClassV1Beta(mattermost)
 
 	if !mattermost.AWSLoadBalancerEnabled() || mattermost.Spec.AWSLoadBalancerController.IngressClassName != "" {
-		err := r.Resources.DeleteIngressClass(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		key := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
+		err := r.Client.Get(context.TODO(), key, &networkingv1.IngressClass{})
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		err = r.Resources.DeleteIngressClass(key, reqLogger)
 		if err != nil {
 			return errors.Wrap(err, "failed to delete disabled ingressClass")
 		}
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonIngressClassDeleted, "Deleted disabled IngressClass %s", desired.Name)
 		return nil
 	}
 
@@ -241,6 +329,88 @@ func (r *MattermostReconciler) checkMattermostIngressClass(mattermost *mmv1beta.
 	return r.Resources.Update(current, desired, reqLogger)
 }
 
+// checkMattermostHorizontalPodAutoscaler creates or updates the
+// HorizontalPodAutoscaler of the Mattermost deployment, or deletes it if
+// autoscaling is disabled.
+func (r *MattermostReconciler) checkMattermostHorizontalPodAutoscaler(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	desired := mattermostApp.GenerateHorizontalPodAutoscalerV1Beta(mattermost)
+
+	if !mattermost.AutoscalingEnabled() {
+		err := r.Resources.DeleteHorizontalPodAutoscaler(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete horizontal pod autoscaler")
+		}
+		return nil
+	}
+
+	err := r.Resources.CreateHorizontalPodAutoscalerIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &autoscalingv2.HorizontalPodAutoscaler{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	return r.Resources.Update(current, desired, reqLogger)
+}
+
+// checkMattermostNetworkPolicy creates or updates the NetworkPolicy isolating
+// Mattermost pods, or deletes it if it is disabled.
+func (r *MattermostReconciler) checkMattermostNetworkPolicy(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	desired := mattermostApp.GenerateNetworkPolicyV1Beta(mattermost)
+
+	if !mattermost.NetworkPolicyEnabled() {
+		err := r.Resources.DeleteNetworkPolicy(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete network policy")
+		}
+		return nil
+	}
+
+	err := r.Resources.CreateNetworkPolicyIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &networkingv1.NetworkPolicy{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	return r.Resources.Update(current, desired, reqLogger)
+}
+
+// checkMattermostPodDisruptionBudget creates or updates the PodDisruptionBudget
+// for the pods of the deployment, or deletes it if it is not needed.
+func (r *MattermostReconciler) checkMattermostPodDisruptionBudget(mattermost *mmv1beta.Mattermost, name string, selector map[string]string, replicas int32, reqLogger logr.Logger) error {
+	desired := mattermostApp.GeneratePodDisruptionBudgetV1Beta(mattermost, name, selector, replicas)
+
+	if !mattermost.PodDisruptionBudgetEnabled(replicas) {
+		err := r.Resources.DeletePodDisruptionBudget(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete pod disruption budget")
+		}
+		return nil
+	}
+
+	err := r.Resources.CreatePodDisruptionBudgetIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &policyv1.PodDisruptionBudget{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	return r.Resources.Update(current, desired, reqLogger)
+}
+
 func (r *MattermostReconciler) checkMattermostJobServer(
 	mattermost *mmv1beta.Mattermost,
 	dbInfo mattermostApp.DatabaseConfig,
@@ -249,7 +419,17 @@ func (r *MattermostReconciler) checkMattermostJobServer(
 	reqLogger logr.Logger) error {
 	reqLogger = reqLogger.WithValues("Reconcile", "mattermost-jobserver")
 
-	return r.checkMattermostJobServerDeployment(mattermost, dbInfo, fsConfig, status, reqLogger)
+	err := r.checkMattermostJobServerDeployment(mattermost, dbInfo, fsConfig, status, reqLogger)
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionJobServerReady, metav1.ConditionFalse, mmv1beta.ReasonJobServerCheckFailed, err.Error())
+		return err
+	}
+
+	if mattermost.Spec.JobServer == nil || !mattermost.Spec.JobServer.DedicatedJobServer {
+		status.SetCondition(mmv1beta.ConditionJobServerReady, metav1.ConditionTrue, mmv1beta.ReasonJobServerDisabled, "Dedicated job server is not enabled")
+	}
+
+	return nil
 }
 
 func (r *MattermostReconciler) checkMattermostJobServerDeployment(
@@ -258,6 +438,14 @@ func (r *MattermostReconciler) checkMattermostJobServerDeployment(
 	fsConfig mattermostApp.FileStoreConfig,
 	status *mmv1beta.MattermostStatus,
 	reqLogger logr.Logger) error {
+	image := mattermost.GetImageName()
+	if status.Rollout.IsAbortedFor(image, mattermost.Generation) {
+		// Job server keeps running the stable image as well.
+		image = status.Rollout.StableImage
+	} else if status.PendingUpgrade != nil {
+		image = status.PendingUpgrade.CurrentImage
+	}
+
 	desired := mattermostApp.GenerateJobServerDeploymentV1Beta(
 		mattermost,
 		dbConfig,
@@ -265,7 +453,7 @@ func (r *MattermostReconciler) checkMattermostJobServerDeployment(
 		mattermost.Name,
 		mattermost.GetIngressHost(),
 		mattermost.Name,
-		mattermost.GetImageName(),
+		image,
 	)
 
 	if mattermost.Spec.JobServer == nil || !mattermost.Spec.JobServer.DedicatedJobServer {
@@ -274,12 +462,20 @@ func (r *MattermostReconciler) checkMattermostJobServerDeployment(
 		if err != nil {
 			return errors.Wrap(err, "failed to delete job server deployment")
 		}
-		return nil
+		return r.checkMattermostPodDisruptionBudget(mattermost, desired.Name, desired.Spec.Selector.MatchLabels, 0, reqLogger)
+	}
+
+	// The job server runs a single replica, the PodDisruptionBudget is only
+	// created if it is scaled up.
+	err := r.checkMattermostPodDisruptionBudget(mattermost, desired.Name, desired.Spec.Selector.MatchLabels, *desired.Spec.Replicas, reqLogger)
+	if err != nil {
+		return err
 	}
 
 	patchedObj, applied, err := mattermost.Spec.ResourcePatch.ApplyToDeployment(desired)
 	if err != nil {
 		reqLogger.Error(err, "Failed to patch deployment", "patch", mattermost.Status.ResourcePatch)
+		r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonResourcePatchFailed, "Failed to apply patch to job server Deployment: %s", err)
 		status.SetDeploymentPatchStatus(false, errors.Wrap(err, "failed to apply patch to Deployment"))
 	} else if applied {
 		reqLogger.Info("Applied patch to deployment")
@@ -289,6 +485,11 @@ func (r *MattermostReconciler) checkMattermostJobServerDeployment(
 		status.ClearDeploymentPatchStatus()
 	}
 
+	err = r.setConfigHash(desired)
+	if err != nil {
+		return err
+	}
+
 	err = r.Resources.CreateDeploymentIfNotExists(mattermost, desired, reqLogger)
 	if err != nil {
 		return errors.Wrap(err, "failed to create mattermost job server deployment")
@@ -300,6 +501,17 @@ func (r *MattermostReconciler) checkMattermostJobServerDeployment(
 		return errors.Wrap(err, "failed to get mattermost job server deployment")
 	}
 
+	if configChanged(current, desired) && mattermost.UpdateJobOnConfigChange() {
+		verified, err := r.configVerified(mattermost)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			reqLogger.Info("Waiting for update job to verify the configuration before restarting the job server")
+			mattermostApp.SetConfigHash(desired, current.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation])
+		}
+	}
+
 	return r.Resources.Update(current, desired, reqLogger)
 }
 
@@ -322,6 +534,7 @@ func (r *MattermostReconciler) checkMattermostDeployment(
 	patchedObj, applied, err := mattermost.Spec.ResourcePatch.ApplyToDeployment(desired)
 	if err != nil {
 		reqLogger.Error(err, "Failed to patch deployment")
+		r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonResourcePatchFailed, "Failed to apply patch to Deployment: %s", err)
 		status.SetDeploymentPatchStatus(false, errors.Wrap(err, "failed to apply patch to Deployment"))
 	} else if applied {
 		reqLogger.Info("Applied patch to deployment")
@@ -331,6 +544,12 @@ func (r *MattermostReconciler) checkMattermostDeployment(
 		status.ClearDeploymentPatchStatus()
 	}
 
+	err = r.setConfigHash(desired)
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonDeploymentCheckFailed, err.Error())
+		return reconcileStatus{}, err
+	}
+
 	// TODO: DB setup job is temporarily disabled as `mattermost version` command
 	// does not account for the custom configuration
 	//err = r.checkMattermostDBSetupJob(mattermost, desired, reqLogger)
@@ -340,17 +559,32 @@ func (r *MattermostReconciler) checkMattermostDeployment(
 
 	err = r.Resources.CreateDeploymentIfNotExists(mattermost, desired, reqLogger)
 	if err != nil {
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonDeploymentCheckFailed, err.Error())
 		return reconcileStatus{}, errors.Wrap(err, "failed to create mattermost deployment")
 	}
 
 	current := &appsv1.Deployment{}
 	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
 	if err != nil {
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonDeploymentCheckFailed, err.Error())
 		return reconcileStatus{}, errors.Wrap(err, "failed to get mattermost deployment")
 	}
 
-	recStatus, err := r.updateMattermostDeployment(mattermost, current, desired, reqLogger)
+	if mattermost.AutoscalingEnabled() {
+		// Replicas are managed by the HorizontalPodAutoscaler. It does not
+		// scale deployments with no replicas, so those start with the
+		// minimum instead.
+		if current.Spec.Replicas != nil && *current.Spec.Replicas > 0 {
+			desired.Spec.Replicas = current.Spec.Replicas
+		} else {
+			minReplicas := mattermost.GetAutoscalingMinReplicas()
+			desired.Spec.Replicas = &minReplicas
+		}
+	}
+
+	recStatus, err := r.updateMattermostDeployment(mattermost, current, desired, status, reqLogger)
 	if err != nil {
+		status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonDeploymentCheckFailed, err.Error())
 		return reconcileStatus{}, errors.Wrap(err, "failed to update mattermost deployment")
 	}
 
@@ -403,6 +637,7 @@ func (r *MattermostReconciler) updateMattermostDeployment(
 	mattermost *mmv1beta.Mattermost,
 	current *appsv1.Deployment,
 	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
 	reqLogger logr.Logger,
 ) (reconcileStatus, error) {
 	sameImage, err := r.isMainDeploymentContainerImageSame(current, desired)
@@ -414,17 +649,52 @@ func (r *MattermostReconciler) updateMattermostDeployment(
 		return reconcileStatus{}, err
 	}
 
+	deferred, err := r.checkMaintenanceWindow(mattermost, current, desired, sameImage, status, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, err
+	}
+	if deferred {
+		return recStatus, r.Resources.Update(current, deploymentWithImage(desired, status.PendingUpgrade.CurrentImage), reqLogger)
+	}
+
+	progressiveRollout := status.Rollout.IsActive() && status.Rollout.IsProgressive()
+	if mattermost.ProgressiveRolloutEnabled() && (!sameImage || progressiveRollout) {
+		return r.checkRollout(mattermost, current, desired, status, reqLogger)
+	}
+	if progressiveRollout {
+		// Strategy was changed during the rollout, continue with the rolling update.
+		err = r.abortRollout(mattermost, status, "Rollout strategy was changed", reqLogger)
+		if err != nil {
+			return reconcileStatus{}, err
+		}
+	}
+
 	if sameImage {
+		if status.Rollout.IsActive() {
+			return r.checkRollingUpdate(mattermost, current, desired, status, reqLogger)
+		}
+		if configChanged(current, desired) && mattermost.UpdateJobOnConfigChange() {
+			return r.checkConfigUpdateJob(mattermost, current, desired, status, reqLogger)
+		}
 		// Need to update other fields only, update job is not required
 		return recStatus, r.Resources.Update(current, desired, reqLogger)
 	}
 
 	// Image is not the same
 
+	targetImage := mmv1beta.GetMattermostAppContainerFromDeployment(desired).Image
+	if status.Rollout.IsAbortedFor(targetImage, mattermost.Generation) {
+		reqLogger.Info("Image was rolled back, keeping the stable image until the spec changes", "image", targetImage)
+		currentImage := mmv1beta.GetMattermostAppContainerFromDeployment(current).Image
+		return recStatus, r.Resources.Update(current, deploymentWithImage(desired, currentImage), reqLogger)
+	}
+
 	reqLogger.Info("Current image is not the same as the requested, will upgrade the Mattermost installation")
 
 	if mattermost.Spec.UpdateJob != nil && mattermost.Spec.UpdateJob.Disabled {
 		reqLogger.Info("Update job is disabled, new image will rollout without being verified")
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionTrue, mmv1beta.ReasonUpdateJobDisabled, "Update job is disabled, image was not verified")
+		r.startRollingUpdate(mattermost, current, desired, status, reqLogger)
 		return recStatus, r.Resources.Update(current, desired, reqLogger)
 	}
 
@@ -439,14 +709,49 @@ func (r *MattermostReconciler) updateMattermostDeployment(
 		defer r.cleanupUpdateJob(job, reqLogger)
 	}
 	if err != nil {
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionFalse, mmv1beta.ReasonUpdateJobFailed, err.Error())
 		return recStatus, err
 	}
 
 	// Job completed successfully
 	if recStatus.ResourcesReady {
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionTrue, mmv1beta.ReasonUpdateJobCompleted, "Update job verified the new image")
+		r.startRollingUpdate(mattermost, current, desired, status, reqLogger)
+		return recStatus, r.Resources.Update(current, desired, reqLogger)
+	}
+
+	status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionUnknown, mmv1beta.ReasonUpdateJobRunning, "Update job is verifying the new image")
+	status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, "Waiting for update job to complete")
+
+	return recStatus, nil
+}
+
+// checkConfigUpdateJob runs the update job with the changed Secrets and
+// ConfigMaps before the pods are restarted to use them.
+func (r *MattermostReconciler) checkConfigUpdateJob(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (reconcileStatus, error) {
+	reqLogger.Info("Configuration used by Mattermost changed, verifying it before restarting the pods")
+
+	job, recStatus, err := r.checkUpdateJob(mattermost, mattermost.Namespace, desired, reqLogger)
+	if job != nil {
+		defer r.cleanupUpdateJob(job, reqLogger)
+	}
+	if err != nil {
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionFalse, mmv1beta.ReasonUpdateJobFailed, err.Error())
+		return recStatus, err
+	}
+
+	if recStatus.ResourcesReady {
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionTrue, mmv1beta.ReasonUpdateJobCompleted, "Update job verified the new configuration")
 		return recStatus, r.Resources.Update(current, desired, reqLogger)
 	}
 
+	status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionUnknown, mmv1beta.ReasonUpdateJobRunning, "Update job is verifying the new configuration")
 	return recStatus, nil
 }
 
@@ -468,6 +773,7 @@ func (r *MattermostReconciler) checkUpdateJob(
 			if err = r.Resources.LaunchMattermostUpdateJob(mattermost, jobNamespace, baseDeployment, reqLogger, mattermost.Spec.UpdateJob); err != nil {
 				return nil, reconcileStatus{}, errors.Wrap(err, "Launching update image job failed")
 			}
+			r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpdateJobLaunched, "Launched update job for image %s", mmv1beta.GetMattermostAppContainerFromDeployment(baseDeployment).Image)
 			recStatus.ResourcesReady = false
 			return nil, recStatus, nil
 		}
@@ -477,7 +783,8 @@ func (r *MattermostReconciler) checkUpdateJob(
 
 	// Job is either running or completed
 
-	// If desired deployment image does not match the one used by update job, restart it.
+	// If desired deployment image or configuration does not match the one
+	// used by update job, restart it.
 	isSameImage, err := r.isMainContainerImageSame(
 		baseDeployment.Spec.Template.Spec.Containers,
 		job.Spec.Template.Spec.Containers,
@@ -485,8 +792,9 @@ func (r *MattermostReconciler) checkUpdateJob(
 	if err != nil {
 		return nil, reconcileStatus{}, errors.Wrap(err, "failed to compare image of update job and desired deployment")
 	}
-	if !isSameImage {
-		reqLogger.Info("Mattermost image changed, restarting update job")
+	isSameConfig := job.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation] == baseDeployment.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]
+	if !isSameImage || !isSameConfig {
+		reqLogger.Info("Mattermost image or configuration changed, restarting update job")
 		err = r.Resources.RestartMattermostUpdateJob(mattermost, job, baseDeployment, reqLogger, mattermost.Spec.UpdateJob)
 		if err != nil {
 			recStatus.ResourcesReady = false
@@ -495,6 +803,7 @@ func (r *MattermostReconciler) checkUpdateJob(
 
 		recStatus.ResourcesReady = false
 		reqLogger.Info("Restarted update image job")
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpdateJobRestarted, "Restarted update job for image %s", mmv1beta.GetMattermostAppContainerFromDeployment(baseDeployment).Image)
 		return nil, recStatus, nil
 	}
 
@@ -508,14 +817,26 @@ func (r *MattermostReconciler) checkUpdateJob(
 
 	if job.Status.Failed > 0 {
 		recStatus.ResourcesReady = false
+		metrics.ObserveUpdateJob(metrics.UpdateJobFailed, jobDuration(job))
+		r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonUpdateJobFailed, "Update job for image %s failed", mmv1beta.GetMattermostAppContainerFromDeployment(baseDeployment).Image)
 		return job, recStatus, errors.New("update image job failed")
 	}
 
 	reqLogger.Info("Update image job ran successfully")
+	metrics.ObserveUpdateJob(metrics.UpdateJobSucceeded, jobDuration(job))
+	r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonUpdateJobSucceeded, "Update job for image %s completed successfully", mmv1beta.GetMattermostAppContainerFromDeployment(baseDeployment).Image)
 
 	return job, recStatus, nil
 }
 
+// jobDuration returns how long the job was running or 0 if it is unknown.
+func jobDuration(job *batchv1.Job) time.Duration {
+	if job.Status.StartTime == nil || job.Status.CompletionTime == nil {
+		return 0
+	}
+	return job.Status.CompletionTime.Sub(job.Status.StartTime.Time)
+}
+
 // cleanupUpdateJob deletes update job and all pods of the job
 func (r *MattermostReconciler) cleanupUpdateJob(job *batchv1.Job, reqLogger logr.Logger) {
 	reqLogger.Info(fmt.Sprintf("Deleting update image job %s/%s", job.GetNamespace(), job.GetName()))
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/operation"
 	pkgUtils "github.com/mattermost/mattermost-operator/pkg/utils"
 
 	"github.com/go-logr/logr"
@@ -25,13 +26,17 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1beta1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -781,6 +786,379 @@ func TestCheckMattermostUpdateJobDisabled(t *testing.T) {
 	})
 }
 
+func TestCheckMattermostPodDisruptionBudget(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(4)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:    &replicas,
+			Image:       "mattermost/mattermost-enterprise-edition",
+			Version:     operatortest.LatestStableMattermostVersion,
+			IngressName: "foo.mattermost.dev",
+			JobServer:   &mmv1beta.JobServer{DedicatedJobServer: true},
+		},
+	}
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	getPDB := func(name string) (*policyv1.PodDisruptionBudget, error) {
+		pdb := &policyv1.PodDisruptionBudget{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mmNamespace}, pdb)
+		return pdb, err
+	}
+
+	t.Run("created for multiple replicas", func(t *testing.T) {
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		pdb, err := getPDB(mmName)
+		require.NoError(t, err)
+		assert.Equal(t, mmv1beta.MattermostSelectorLabels(mmName), pdb.Spec.Selector.MatchLabels)
+		assert.Equal(t, int32(2), pdb.Spec.MinAvailable.IntVal)
+		require.Len(t, pdb.OwnerReferences, 1)
+		assert.Equal(t, mmName, pdb.OwnerReferences[0].Name)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		maxUnavailable := intstr.FromString("25%")
+		mm.Spec.PodDisruptionBudget = &mmv1beta.PodDisruptionBudget{MaxUnavailable: &maxUnavailable}
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		pdb, err := getPDB(mmName)
+		require.NoError(t, err)
+		assert.Nil(t, pdb.Spec.MinAvailable)
+		assert.Equal(t, maxUnavailable, *pdb.Spec.MaxUnavailable)
+	})
+
+	t.Run("not created for job server with single replica", func(t *testing.T) {
+		err := reconciler.checkMattermostJobServer(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		_, err = getPDB(mm.DedicatedJobServerName())
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+
+	t.Run("deleted for single replica", func(t *testing.T) {
+		replicas = 1
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		_, err = getPDB(mmName)
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+
+	t.Run("deleted when disabled", func(t *testing.T) {
+		replicas = 4
+		mm.Spec.PodDisruptionBudget = nil
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+		_, err = getPDB(mmName)
+		require.NoError(t, err)
+
+		mm.Spec.PodDisruptionBudget = &mmv1beta.PodDisruptionBudget{Disabled: true}
+
+		_, err = reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		_, err = getPDB(mmName)
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+}
+
+func TestCheckMattermostHorizontalPodAutoscaler(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(2)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:    &replicas,
+			Image:       "mattermost/mattermost-enterprise-edition",
+			Version:     operatortest.LatestStableMattermostVersion,
+			IngressName: "foo.mattermost.dev",
+			Autoscaling: &mmv1beta.Autoscaling{
+				Enabled:     true,
+				MaxReplicas: 6,
+			},
+		},
+	}
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	getHPA := func() (*autoscalingv2.HorizontalPodAutoscaler, error) {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, hpa)
+		return hpa, err
+	}
+
+	t.Run("created", func(t *testing.T) {
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		hpa, err := getHPA()
+		require.NoError(t, err)
+		assert.Equal(t, mmName, hpa.Spec.ScaleTargetRef.Name)
+		assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+		assert.Equal(t, int32(6), hpa.Spec.MaxReplicas)
+		require.Len(t, hpa.OwnerReferences, 1)
+		assert.Equal(t, mmName, hpa.OwnerReferences[0].Name)
+	})
+
+	t.Run("deployment replicas are not overwritten", func(t *testing.T) {
+		deployment := &appsv1.Deployment{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, deployment)
+		require.NoError(t, err)
+
+		scaledReplicas := int32(5)
+		deployment.Spec.Replicas = &scaledReplicas
+		err = reconciler.Client.Update(context.TODO(), deployment)
+		require.NoError(t, err)
+
+		_, err = reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, deployment)
+		require.NoError(t, err)
+		assert.Equal(t, scaledReplicas, *deployment.Spec.Replicas)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		mm.Spec.Autoscaling.MaxReplicas = 10
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		hpa, err := getHPA()
+		require.NoError(t, err)
+		assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	})
+
+	t.Run("deleted when disabled", func(t *testing.T) {
+		mm.Spec.Autoscaling.Enabled = false
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		_, err = getHPA()
+		assert.True(t, k8sErrors.IsNotFound(err))
+
+		deployment := &appsv1.Deployment{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, deployment)
+		require.NoError(t, err)
+		assert.Equal(t, replicas, *deployment.Spec.Replicas)
+	})
+}
+
+func TestCheckMattermostAutoscalingScaledDown(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(2)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:    &replicas,
+			Image:       "mattermost/mattermost-enterprise-edition",
+			Version:     operatortest.LatestStableMattermostVersion,
+			IngressName: "foo.mattermost.dev",
+			Autoscaling: &mmv1beta.Autoscaling{
+				Enabled:     true,
+				MaxReplicas: 6,
+			},
+		},
+	}
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	getHPA := func() (*autoscalingv2.HorizontalPodAutoscaler, error) {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, hpa)
+		return hpa, err
+	}
+	getDeployment := func() *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, deployment)
+		require.NoError(t, err)
+		return deployment
+	}
+
+	_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+	require.NoError(t, err)
+	_, err = getHPA()
+	require.NoError(t, err)
+
+	t.Run("scaled down for operation", func(t *testing.T) {
+		require.True(t, operation.ScaleDown(mm))
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		_, err = getHPA()
+		assert.True(t, k8sErrors.IsNotFound(err))
+		assert.Equal(t, int32(0), *getDeployment().Spec.Replicas)
+
+		scaledDown, err := operation.IsScaledDown(context.TODO(), reconciler.Client, mm, logger)
+		require.NoError(t, err)
+		assert.True(t, scaledDown)
+	})
+
+	t.Run("autoscaling resumed after operation", func(t *testing.T) {
+		operation.SetReplicas(mm, &replicas, false)
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		hpa, err := getHPA()
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+		assert.Equal(t, int32(2), *getDeployment().Spec.Replicas)
+	})
+}
+
+func TestCheckMattermostNetworkPolicy(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	replicas := int32(2)
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:      &replicas,
+			Image:         "mattermost/mattermost-enterprise-edition",
+			Version:       operatortest.LatestStableMattermostVersion,
+			IngressName:   "foo.mattermost.dev",
+			NetworkPolicy: &mmv1beta.NetworkPolicy{Enabled: true},
+		},
+	}
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	getNetworkPolicy := func() (*v1beta1.NetworkPolicy, error) {
+		networkPolicy := &v1beta1.NetworkPolicy{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, networkPolicy)
+		return networkPolicy, err
+	}
+
+	t.Run("created", func(t *testing.T) {
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		networkPolicy, err := getNetworkPolicy()
+		require.NoError(t, err)
+		assert.Len(t, networkPolicy.Spec.Ingress, 3)
+		require.Len(t, networkPolicy.OwnerReferences, 1)
+		assert.Equal(t, mmName, networkPolicy.OwnerReferences[0].Name)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		mm.Spec.NetworkPolicy.EgressCIDRs = []string{"10.0.0.0/16"}
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		networkPolicy, err := getNetworkPolicy()
+		require.NoError(t, err)
+		lastRule := networkPolicy.Spec.Egress[len(networkPolicy.Spec.Egress)-1]
+		require.Len(t, lastRule.To, 1)
+		assert.Equal(t, "10.0.0.0/16", lastRule.To[0].IPBlock.CIDR)
+	})
+
+	t.Run("deleted when disabled", func(t *testing.T) {
+		mm.Spec.NetworkPolicy.Enabled = false
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+
+		_, err = getNetworkPolicy()
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+}
+
+func TestCheckMattermostElasticSearchSecret(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mmName := "foo"
+	mmNamespace := "default"
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Image:       "mattermost/mattermost-enterprise-edition",
+			Version:     operatortest.LatestStableMattermostVersion,
+			IngressName: "foo.mattermost.dev",
+			ElasticSearch: mmv1beta.ElasticSearch{
+				Host:   "http://elastic",
+				Secret: "elastic-credentials",
+			},
+		},
+	}
+
+	currentMMStatus := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.Error(t, err)
+	})
+
+	t.Run("secret without password", func(t *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "elastic-credentials", Namespace: mmNamespace},
+			Data:       map[string][]byte{"username": []byte("user")},
+		}
+		require.NoError(t, reconciler.Client.Create(context.TODO(), secret))
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "password")
+	})
+
+	t.Run("valid secret", func(t *testing.T) {
+		secret := &corev1.Secret{}
+		require.NoError(t, reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "elastic-credentials", Namespace: mmNamespace}, secret))
+		secret.Data["password"] = []byte("password")
+		require.NoError(t, reconciler.Client.Update(context.TODO(), secret))
+
+		_, err := reconciler.checkMattermost(mm, dbInfo, fileStoreInfo, currentMMStatus, logger)
+		require.NoError(t, err)
+	})
+}
+
 func TestCheckMattermostExternalDBAndFileStore(t *testing.T) {
 	logger, _, reconciler := setupTestDeps(t)
 
@@ -939,6 +1317,13 @@ func TestCheckMattermostExternalDBAndFileStore(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, found)
 
+		// Update job verified the configuration used by the deployment.
+		job.Spec.Template.Annotations = map[string]string{
+			mattermostApp.ConfigHashAnnotation: found.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation],
+		}
+		err = reconciler.Client.Update(context.TODO(), job)
+		require.NoError(t, err)
+
 		original := found.DeepCopy()
 		modified := found.DeepCopy()
 		modified.Labels = nil
@@ -1522,11 +1907,13 @@ func setupTestDeps(t *testing.T) (logr.Logger, client.Client, *MattermostReconci
 	s.AddKnownTypes(mmv1beta.GroupVersion, &mmv1beta.Mattermost{})
 	c := fake.NewClientBuilder().Build()
 	r := &MattermostReconciler{
-		Client:         c,
-		Scheme:         s,
-		Log:            logger,
-		MaxReconciling: 5,
-		Resources:      resources.NewResourceHelper(c, s),
+		Client:             c,
+		NonCachedAPIReader: c,
+		Scheme:             s,
+		Log:                logger,
+		MaxReconciling:     5,
+		Resources:          resources.NewResourceHelper(c, s),
+		Recorder:           record.NewFakeRecorder(100),
 	}
 	return logger, c, r
 }
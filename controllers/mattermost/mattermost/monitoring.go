@@ -0,0 +1,104 @@
+package mattermost
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkMattermostMonitoring creates or updates the Prometheus Operator
+// ServiceMonitor of the Mattermost pods and PodMonitor of the dedicated job
+// server pods, or deletes them if they are disabled. Nothing is done if the
+// Prometheus Operator CRDs are not installed in the cluster.
+func (r *MattermostReconciler) checkMattermostMonitoring(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	installed, err := r.prometheusOperatorInstalled()
+	if err != nil {
+		return errors.Wrap(err, "failed to check if Prometheus Operator is installed")
+	}
+	if !installed {
+		if mattermost.ServiceMonitorEnabled() {
+			reqLogger.Info("Prometheus Operator CRDs are not installed, skipping ServiceMonitor")
+		}
+		return nil
+	}
+
+	err = r.checkMattermostServiceMonitor(mattermost, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	return r.checkMattermostJobServerPodMonitor(mattermost, reqLogger)
+}
+
+func (r *MattermostReconciler) checkMattermostServiceMonitor(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	desired := mattermostApp.GenerateServiceMonitorV1Beta(mattermost)
+
+	if !mattermost.ServiceMonitorEnabled() {
+		err := r.Resources.DeleteServiceMonitor(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete service monitor")
+		}
+		return nil
+	}
+
+	err := r.Resources.CreateServiceMonitorIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &monitoringv1.ServiceMonitor{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	return r.Resources.Update(current, desired, reqLogger)
+}
+
+func (r *MattermostReconciler) checkMattermostJobServerPodMonitor(mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	desired := mattermostApp.GenerateJobServerPodMonitorV1Beta(mattermost)
+
+	dedicatedJobServer := mattermost.Spec.JobServer != nil && mattermost.Spec.JobServer.DedicatedJobServer
+	if !mattermost.ServiceMonitorEnabled() || !dedicatedJobServer {
+		err := r.Resources.DeletePodMonitor(types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, reqLogger)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete job server pod monitor")
+		}
+		return nil
+	}
+
+	err := r.Resources.CreatePodMonitorIfNotExists(mattermost, desired, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	current := &monitoringv1.PodMonitor{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		return err
+	}
+
+	return r.Resources.Update(current, desired, reqLogger)
+}
+
+// prometheusOperatorInstalled returns true if the ServiceMonitor CRD of the
+// Prometheus Operator is installed in the cluster.
+func (r *MattermostReconciler) prometheusOperatorInstalled() (bool, error) {
+	gk := schema.GroupKind{Group: monitoringv1.SchemeGroupVersion.Group, Kind: monitoringv1.ServiceMonitorsKind}
+	_, err := r.Client.RESTMapper().RESTMapping(gk, monitoringv1.SchemeGroupVersion.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
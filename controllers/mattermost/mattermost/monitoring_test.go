@@ -0,0 +1,113 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckMattermostMonitoring(t *testing.T) {
+	mmName := "foo"
+	mmNamespace := "default"
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mmName,
+			Namespace: mmNamespace,
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Image:     "mattermost/mattermost-enterprise-edition",
+			Version:   operatortest.LatestStableMattermostVersion,
+			JobServer: &mmv1beta.JobServer{DedicatedJobServer: true},
+			Monitoring: &mmv1beta.Monitoring{
+				ServiceMonitor: &mmv1beta.ServiceMonitor{Enabled: true},
+			},
+		},
+	}
+
+	t.Run("skipped without Prometheus Operator", func(t *testing.T) {
+		logger, _, reconciler := setupTestDeps(t)
+
+		err := reconciler.checkMattermostMonitoring(mm, logger)
+		require.NoError(t, err)
+
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, &monitoringv1.ServiceMonitor{})
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+
+	logger, _, reconciler := setupTestDeps(t)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.ServiceMonitorsKind), meta.RESTScopeNamespace)
+	mapper.Add(monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.PodMonitorsKind), meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+	reconciler.Client = c
+	reconciler.Resources = resources.NewResourceHelper(c, reconciler.Scheme)
+
+	getServiceMonitor := func() (*monitoringv1.ServiceMonitor, error) {
+		serviceMonitor := &monitoringv1.ServiceMonitor{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: mmName, Namespace: mmNamespace}, serviceMonitor)
+		return serviceMonitor, err
+	}
+	getPodMonitor := func() (*monitoringv1.PodMonitor, error) {
+		podMonitor := &monitoringv1.PodMonitor{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: mm.DedicatedJobServerName(), Namespace: mmNamespace}, podMonitor)
+		return podMonitor, err
+	}
+
+	t.Run("created", func(t *testing.T) {
+		err := reconciler.checkMattermostMonitoring(mm, logger)
+		require.NoError(t, err)
+
+		serviceMonitor, err := getServiceMonitor()
+		require.NoError(t, err)
+		require.Len(t, serviceMonitor.OwnerReferences, 1)
+		assert.Equal(t, mmName, serviceMonitor.OwnerReferences[0].Name)
+
+		_, err = getPodMonitor()
+		require.NoError(t, err)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		mm.Spec.Monitoring.ServiceMonitor.Interval = "15s"
+
+		err := reconciler.checkMattermostMonitoring(mm, logger)
+		require.NoError(t, err)
+
+		serviceMonitor, err := getServiceMonitor()
+		require.NoError(t, err)
+		assert.Equal(t, monitoringv1.Duration("15s"), serviceMonitor.Spec.Endpoints[0].Interval)
+	})
+
+	t.Run("pod monitor deleted without dedicated job server", func(t *testing.T) {
+		mm.Spec.JobServer = nil
+
+		err := reconciler.checkMattermostMonitoring(mm, logger)
+		require.NoError(t, err)
+
+		_, err = getServiceMonitor()
+		require.NoError(t, err)
+		_, err = getPodMonitor()
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+
+	t.Run("deleted when metrics are disabled", func(t *testing.T) {
+		mm.Spec.Monitoring.MetricsDisabled = true
+
+		err := reconciler.checkMattermostMonitoring(mm, logger)
+		require.NoError(t, err)
+
+		_, err = getServiceMonitor()
+		assert.True(t, k8sErrors.IsNotFound(err))
+	})
+}
@@ -0,0 +1,44 @@
+package mattermost
+
+import (
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+)
+
+// pluginStatuses returns the status of the plugins in the spec. Plugins are
+// configured once all Mattermost pods run with them, until then the status of
+// unchanged plugins is kept.
+func pluginStatuses(mattermost *mmv1beta.Mattermost, current []mmv1beta.PluginStatus, rolledOut bool) []mmv1beta.PluginStatus {
+	if len(mattermost.Spec.Plugins) == 0 {
+		return nil
+	}
+
+	previous := map[string]mmv1beta.PluginStatus{}
+	for _, plugin := range current {
+		previous[plugin.ID] = plugin
+	}
+
+	statuses := make([]mmv1beta.PluginStatus, 0, len(mattermost.Spec.Plugins))
+	for _, plugin := range mattermost.Spec.Plugins {
+		status := mmv1beta.PluginStatus{
+			ID:       plugin.ID,
+			Source:   plugin.Source(),
+			Checksum: plugin.Checksum,
+		}
+		switch prev, found := previous[plugin.ID]; {
+		case rolledOut:
+			status.Configured = true
+			status.ConfiguredEnabled = plugin.IsEnabled()
+			status.Message = "Plugin is staged on all pods and configured to be disabled"
+			if status.ConfiguredEnabled {
+				status.Message = "Plugin is staged on all pods and configured to be enabled"
+			}
+		case found && prev.Source == status.Source && prev.Checksum == status.Checksum && prev.Configured:
+			status = prev
+		default:
+			status.Message = "Waiting for the Mattermost pods to run with the plugin"
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
@@ -0,0 +1,45 @@
+package mattermost
+
+import (
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginStatuses(t *testing.T) {
+	mm := &mmv1beta.Mattermost{Spec: mmv1beta.MattermostSpec{
+		Plugins: []mmv1beta.Plugin{
+			{ID: "com.mattermost.plugin-todo", URL: "https://example.com/todo.tar.gz"},
+			{ID: "com.example.disabled", URL: "https://example.com/disabled.tar.gz", Enabled: utils.NewBool(false)},
+		},
+	}}
+
+	statuses := pluginStatuses(mm, nil, false)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Configured)
+	assert.Equal(t, "https://example.com/todo.tar.gz", statuses[0].Source)
+
+	statuses = pluginStatuses(mm, statuses, true)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Configured)
+	assert.True(t, statuses[0].ConfiguredEnabled)
+	assert.True(t, statuses[1].Configured)
+	assert.False(t, statuses[1].ConfiguredEnabled)
+
+	t.Run("unchanged plugin is kept during rollout", func(t *testing.T) {
+		changed := mm.DeepCopy()
+		changed.Spec.Plugins[1].URL = "https://example.com/disabled-2.tar.gz"
+
+		rollingOut := pluginStatuses(changed, statuses, false)
+		assert.Equal(t, statuses[0], rollingOut[0])
+		assert.False(t, rollingOut[1].Configured)
+		assert.Equal(t, "https://example.com/disabled-2.tar.gz", rollingOut[1].Source)
+	})
+
+	t.Run("no plugins", func(t *testing.T) {
+		assert.Nil(t, pluginStatuses(&mmv1beta.Mattermost{}, statuses, true))
+	})
+}
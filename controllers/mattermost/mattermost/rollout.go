@@ -0,0 +1,427 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkRollout rolls out the image of the desired deployment with the Canary
+// or BlueGreen strategy. The current deployment keeps running the stable image
+// while the new image is analysed in a separate deployment, receiving a share
+// of the traffic through the canary Ingress. Once the new image is healthy for
+// the analysis duration all traffic is switched to it and the current
+// deployment is updated. If the new image does not become healthy, the rollout
+// is aborted and the image is not rolled out again until it changes.
+func (r *MattermostReconciler) checkRollout(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (reconcileStatus, error) {
+	reqLogger = reqLogger.WithValues("rollout", mattermost.RolloutStrategy())
+
+	stableImage := mmv1beta.GetMattermostAppContainerFromDeployment(current).Image
+	targetImage := mmv1beta.GetMattermostAppContainerFromDeployment(desired).Image
+	rollout := status.Rollout
+
+	// The current deployment is updated with the new image only when the
+	// rollout is promoted.
+	stableDesired := deploymentWithImage(desired, stableImage)
+
+	switch {
+	case rollout != nil && rollout.Phase == mmv1beta.RolloutPromoting && rollout.Image == targetImage:
+		return r.promoteRollout(mattermost, current, desired, status, reqLogger)
+	case stableImage == targetImage:
+		// The image was reverted to the stable one during the rollout.
+		err := r.abortRollout(mattermost, status, "Mattermost image was changed back to the stable image", reqLogger)
+		if err != nil {
+			return reconcileStatus{}, err
+		}
+		return reconcileStatus{ResourcesReady: true}, r.Resources.Update(current, desired, reqLogger)
+	case rollout.IsAbortedFor(targetImage, mattermost.Generation):
+		reqLogger.Info("Rollout of the image was aborted, keeping the stable image", "image", targetImage)
+		return reconcileStatus{ResourcesReady: true}, r.Resources.Update(current, stableDesired, reqLogger)
+	case !rollout.IsActive() || rollout.Image != targetImage:
+		recStatus, err := r.startRollout(mattermost, desired, stableImage, status, reqLogger)
+		if err != nil || !recStatus.ResourcesReady {
+			return recStatus, err
+		}
+	}
+
+	return r.progressRollout(mattermost, current, stableDesired, desired, status, reqLogger)
+}
+
+// startRollout verifies the new image with the update job and initializes the
+// rollout status.
+func (r *MattermostReconciler) startRollout(
+	mattermost *mmv1beta.Mattermost,
+	desired *appsv1.Deployment,
+	stableImage string,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (reconcileStatus, error) {
+	if status.Rollout.IsActive() {
+		// The image changed during the rollout, the rollout resources are
+		// updated with the new image.
+		reqLogger.Info("Mattermost image changed during the rollout, restarting the rollout")
+	}
+
+	if mattermost.Spec.UpdateJob != nil && mattermost.Spec.UpdateJob.Disabled {
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionTrue, mmv1beta.ReasonUpdateJobDisabled, "Update job is disabled, image was not verified")
+	} else {
+		job, recStatus, err := r.checkUpdateJob(mattermost, mattermost.Namespace, desired, reqLogger)
+		if job != nil {
+			defer r.cleanupUpdateJob(job, reqLogger)
+		}
+		if err != nil {
+			status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionFalse, mmv1beta.ReasonUpdateJobFailed, err.Error())
+			return recStatus, err
+		}
+		if !recStatus.ResourcesReady {
+			status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionUnknown, mmv1beta.ReasonUpdateJobRunning, "Update job is verifying the new image")
+			status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, "Waiting for update job to complete")
+			return recStatus, nil
+		}
+		status.SetCondition(mmv1beta.ConditionUpdateJobSucceeded, metav1.ConditionTrue, mmv1beta.ReasonUpdateJobCompleted, "Update job verified the new image")
+	}
+
+	targetImage := mmv1beta.GetMattermostAppContainerFromDeployment(desired).Image
+	status.Rollout = &mmv1beta.RolloutStatus{
+		Strategy:    mattermost.RolloutStrategy(),
+		Phase:       mmv1beta.RolloutProgressing,
+		Image:       targetImage,
+		StableImage: stableImage,
+		Deployment:  mattermost.RolloutDeploymentName(),
+		StartTime:   &metav1.Time{Time: time.Now()},
+	}
+
+	reqLogger.Info("Starting rollout", "image", targetImage, "stableImage", stableImage)
+	r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonRolloutStarted, "Started %s rollout of image %s", mattermost.RolloutStrategy(), targetImage)
+
+	return reconcileStatus{ResourcesReady: true}, nil
+}
+
+// progressRollout brings up the deployment running the new image and decides
+// whether to promote or abort the rollout based on its health.
+func (r *MattermostReconciler) progressRollout(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	stableDesired *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (reconcileStatus, error) {
+	rollout := status.Rollout
+
+	err := r.Resources.Update(current, stableDesired, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to update mattermost deployment")
+	}
+
+	deployment, err := r.checkRolloutResources(mattermost, desired, mattermost.RolloutWeight(), reqLogger)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to check rollout resources")
+	}
+
+	rollout.Weight = mattermost.RolloutWeight()
+	rollout.Replicas = mattermost.RolloutReplicas()
+	rollout.AvailableReplicas = deployment.Status.AvailableReplicas
+	status.SetCondition(mmv1beta.ConditionDeploymentRolledOut, metav1.ConditionFalse, mmv1beta.ReasonRolloutInProgress, fmt.Sprintf("%s rollout of the new image is in progress", rollout.Strategy))
+
+	now := time.Now()
+	healthy := deploymentRolledOut(deployment)
+	switch {
+	case healthy && rollout.HealthySince == nil:
+		rollout.HealthySince = &metav1.Time{Time: now}
+	case !healthy && rollout.HealthySince != nil && rollout.AvailableReplicas < rollout.Replicas:
+		message := "New image became unhealthy during the analysis"
+		status.SetCondition(mmv1beta.ConditionRolledBack, metav1.ConditionTrue, mmv1beta.ReasonNewImageUnhealthy, message)
+		return reconcileStatus{ResourcesReady: true}, r.abortRollout(mattermost, status, message, reqLogger)
+	case !healthy && rollout.HealthySince == nil && now.Sub(rollout.StartTime.Time) > mattermost.Spec.Rollout.GetProgressDeadline():
+		message := fmt.Sprintf("New image did not become healthy within %s", mattermost.Spec.Rollout.GetProgressDeadline())
+		status.SetCondition(mmv1beta.ConditionRolledBack, metav1.ConditionTrue, mmv1beta.ReasonProgressDeadlineExceeded, message)
+		return reconcileStatus{ResourcesReady: true}, r.abortRollout(mattermost, status, message, reqLogger)
+	case !healthy:
+		rollout.Message = fmt.Sprintf("Waiting for the new image to become healthy, %d/%d replicas available", rollout.AvailableReplicas, rollout.Replicas)
+		return reconcileStatus{}, nil
+	}
+
+	analysis := mattermost.Spec.Rollout.GetAnalysisDuration()
+	if healthySince := now.Sub(rollout.HealthySince.Time); healthySince < analysis {
+		rollout.Message = fmt.Sprintf("New image is healthy, analysing for %s more", (analysis - healthySince).Round(time.Second))
+		return reconcileStatus{}, nil
+	}
+
+	reqLogger.Info("New image is healthy, promoting the rollout")
+	rollout.Phase = mmv1beta.RolloutPromoting
+	r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonRolloutPromoting, "Promoting image %s", rollout.Image)
+
+	return r.promoteRollout(mattermost, current, desired, status, reqLogger)
+}
+
+// promoteRollout routes all traffic to the new image while the current
+// deployment is updated and removes the rollout resources once it is done.
+func (r *MattermostReconciler) promoteRollout(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (reconcileStatus, error) {
+	rollout := status.Rollout
+
+	_, err := r.checkRolloutResources(mattermost, desired, 100, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to check rollout resources")
+	}
+	rollout.Weight = 100
+
+	err = r.Resources.Update(current, desired, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to update mattermost deployment")
+	}
+
+	updated := &appsv1.Deployment{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: current.Name, Namespace: current.Namespace}, updated)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to get mattermost deployment")
+	}
+	if !deploymentRolledOut(updated) {
+		rollout.Message = fmt.Sprintf("All traffic is routed to the new image, waiting for deployment %s to be updated", updated.Name)
+		return reconcileStatus{}, nil
+	}
+
+	err = r.cleanupRollout(mattermost, rollout.Deployment, reqLogger)
+	if err != nil {
+		return reconcileStatus{}, err
+	}
+
+	reqLogger.Info("Rollout completed", "image", rollout.Image)
+	rollout.Phase = mmv1beta.RolloutSucceeded
+	rollout.Weight = 0
+	rollout.CompletionTime = &metav1.Time{Time: time.Now()}
+	rollout.Message = "New image was promoted"
+	status.SetCondition(mmv1beta.ConditionRolledBack, metav1.ConditionFalse, mmv1beta.ReasonRolloutComplete, "New image was rolled out")
+	r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonRolloutSucceeded, "Promoted image %s", rollout.Image)
+
+	return reconcileStatus{ResourcesReady: true}, nil
+}
+
+// abortRollout removes the rollout resources, the stable image keeps running.
+func (r *MattermostReconciler) abortRollout(mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, message string, reqLogger logr.Logger) error {
+	rollout := status.Rollout
+	if !rollout.IsActive() {
+		return nil
+	}
+
+	err := r.cleanupRollout(mattermost, rollout.Deployment, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	reqLogger.Info("Rollout aborted", "image", rollout.Image, "reason", message)
+	rollout.Phase = mmv1beta.RolloutAborted
+	rollout.Weight = 0
+	rollout.CompletionTime = &metav1.Time{Time: time.Now()}
+	rollout.AbortedGeneration = mattermost.Generation
+	rollout.Message = message
+	r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonRolloutAborted, "Aborted rollout of image %s: %s", rollout.Image, message)
+
+	return nil
+}
+
+// startRollingUpdate initializes the rollout status before the deployment is
+// updated with the new image.
+func (r *MattermostReconciler) startRollingUpdate(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) {
+	targetImage := mmv1beta.GetMattermostAppContainerFromDeployment(desired).Image
+	stableImage := status.LastGoodImage
+	if stableImage == "" {
+		stableImage = mmv1beta.GetMattermostAppContainerFromDeployment(current).Image
+	}
+
+	status.Rollout = &mmv1beta.RolloutStatus{
+		Strategy:    mmv1beta.RolloutStrategyRollingUpdate,
+		Phase:       mmv1beta.RolloutProgressing,
+		Image:       targetImage,
+		StableImage: stableImage,
+		StartTime:   &metav1.Time{Time: time.Now()},
+	}
+
+	reqLogger.Info("Starting rollout", "image", targetImage, "stableImage", stableImage)
+	r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonRolloutStarted, "Started %s rollout of image %s", mmv1beta.RolloutStrategyRollingUpdate, targetImage)
+}
+
+// checkRollingUpdate waits for the deployment to roll out the new image and
+// rolls it back to the stable image if it does not become available within
+// the progress deadline.
+func (r *MattermostReconciler) checkRollingUpdate(
+	mattermost *mmv1beta.Mattermost,
+	current *appsv1.Deployment,
+	desired *appsv1.Deployment,
+	status *mmv1beta.MattermostStatus,
+	reqLogger logr.Logger,
+) (reconcileStatus, error) {
+	rollout := status.Rollout
+	recStatus := reconcileStatus{ResourcesReady: true}
+
+	if deploymentRolledOut(current) {
+		reqLogger.Info("Rollout completed", "image", rollout.Image)
+		rollout.Phase = mmv1beta.RolloutSucceeded
+		rollout.CompletionTime = &metav1.Time{Time: time.Now()}
+		rollout.Message = "New image was rolled out"
+		status.SetCondition(mmv1beta.ConditionRolledBack, metav1.ConditionFalse, mmv1beta.ReasonRolloutComplete, "New image was rolled out")
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonRolloutSucceeded, "Rolled out image %s", rollout.Image)
+		return recStatus, r.Resources.Update(current, desired, reqLogger)
+	}
+
+	deadline := mattermost.Spec.Rollout.GetProgressDeadline()
+	if time.Since(rollout.StartTime.Time) <= deadline {
+		rollout.Message = fmt.Sprintf("Waiting for the new image to become available, %d/%d replicas available", current.Status.AvailableReplicas, current.Status.Replicas)
+		return recStatus, r.Resources.Update(current, desired, reqLogger)
+	}
+
+	if rollout.StableImage == "" || rollout.StableImage == rollout.Image {
+		rollout.Message = fmt.Sprintf("New image did not become available within %s, there is no known good image to roll back to", deadline)
+		return recStatus, r.Resources.Update(current, desired, reqLogger)
+	}
+
+	message := fmt.Sprintf("New image did not become available within %s, rolled back to %s", deadline, rollout.StableImage)
+	err := r.Resources.Update(current, deploymentWithImage(desired, rollout.StableImage), reqLogger)
+	if err != nil {
+		return reconcileStatus{}, errors.Wrap(err, "failed to roll back mattermost deployment")
+	}
+
+	reqLogger.Info("Rolled back the deployment", "image", rollout.Image, "stableImage", rollout.StableImage)
+	rollout.Phase = mmv1beta.RolloutAborted
+	rollout.CompletionTime = &metav1.Time{Time: time.Now()}
+	rollout.AbortedGeneration = mattermost.Generation
+	rollout.Message = message
+	status.SetCondition(mmv1beta.ConditionRolledBack, metav1.ConditionTrue, mmv1beta.ReasonProgressDeadlineExceeded, message)
+	r.Recorder.Eventf(mattermost, corev1.EventTypeWarning, eventReasonRolledBack, "Rolled back image %s to %s: %s", rollout.Image, rollout.StableImage, message)
+
+	return recStatus, nil
+}
+
+// checkRolloutResources creates or updates the service, the canary Ingress and
+// the deployment running the new image and returns the deployment.
+func (r *MattermostReconciler) checkRolloutResources(mattermost *mmv1beta.Mattermost, desired *appsv1.Deployment, weight int32, reqLogger logr.Logger) (*appsv1.Deployment, error) {
+	name := mattermost.RolloutDeploymentName()
+
+	desiredService := mattermostApp.GenerateRolloutServiceV1Beta(mattermost, name)
+	err := r.Resources.CreateServiceIfNotExists(mattermost, desiredService, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+	currentService := &corev1.Service{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mattermost.Namespace}, currentService)
+	if err != nil {
+		return nil, err
+	}
+	resources.CopyServiceEmptyAutoAssignedFields(desiredService, currentService)
+	err = r.Resources.Update(currentService, desiredService, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without the Ingress the new image is analysed without receiving traffic.
+	if mattermost.RolloutTrafficRoutingEnabled() {
+		desiredIngress := mattermostApp.GenerateRolloutIngressV1Beta(mattermost, name, weight, mattermost.RolloutCookie(), reqLogger)
+		err = r.Resources.CreateIngressIfNotExists(mattermost, desiredIngress, reqLogger)
+		if err != nil {
+			return nil, err
+		}
+		currentIngress := &networkingv1.Ingress{}
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mattermost.Namespace}, currentIngress)
+		if err != nil {
+			return nil, err
+		}
+		err = r.Resources.Update(currentIngress, desiredIngress, reqLogger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	desiredDeployment := mattermostApp.GenerateRolloutDeploymentV1Beta(mattermost, desired, name, mattermost.RolloutReplicas())
+	err = r.Resources.CreateDeploymentIfNotExists(mattermost, desiredDeployment, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+	currentDeployment := &appsv1.Deployment{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mattermost.Namespace}, currentDeployment)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Resources.Update(currentDeployment, desiredDeployment, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	return currentDeployment, nil
+}
+
+// cleanupRollout deletes the Ingress, the deployment and the service of the
+// new image. Traffic is switched back to the Mattermost service first.
+func (r *MattermostReconciler) cleanupRollout(mattermost *mmv1beta.Mattermost, name string, reqLogger logr.Logger) error {
+	key := types.NamespacedName{Name: name, Namespace: mattermost.Namespace}
+
+	err := r.Resources.DeleteIngress(key, reqLogger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete rollout ingress")
+	}
+	err = r.Resources.DeleteDeployment(key, reqLogger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete rollout deployment")
+	}
+	err = r.Resources.DeleteService(key, reqLogger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete rollout service")
+	}
+
+	return nil
+}
+
+// deploymentRolledOut returns true if all replicas of the deployment are
+// updated and available.
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas &&
+		deployment.Status.Replicas == replicas
+}
+
+// deploymentWithImage returns a copy of the deployment running the given
+// Mattermost image.
+func deploymentWithImage(deployment *appsv1.Deployment, image string) *appsv1.Deployment {
+	updated := deployment.DeepCopy()
+	for i := range updated.Spec.Template.Spec.Containers {
+		if updated.Spec.Template.Spec.Containers[i].Name == mmv1beta.MattermostAppContainerName {
+			updated.Spec.Template.Spec.Containers[i].Image = image
+		}
+	}
+
+	return updated
+}
@@ -0,0 +1,314 @@
+package mattermost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	operatortest "github.com/mattermost/mattermost-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newRolloutTestMattermost(strategy mmv1beta.RolloutStrategyType) *mmv1beta.Mattermost {
+	replicas := int32(3)
+	analysisSeconds := int32(0)
+	return &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       types.UID("test"),
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas: &replicas,
+			Image:    "mattermost/mattermost-enterprise-edition",
+			Version:  operatortest.LatestStableMattermostVersion,
+			Ingress: &mmv1beta.Ingress{
+				Enabled: true,
+				Host:    "foo.mattermost.dev",
+			},
+			UpdateJob: &mmv1beta.UpdateJob{Disabled: true},
+			Rollout: &mmv1beta.Rollout{
+				Strategy:        strategy,
+				AnalysisSeconds: &analysisSeconds,
+			},
+		},
+	}
+}
+
+func setDeploymentRolledOut(t *testing.T, reconciler *MattermostReconciler, name string) {
+	deployment := &appsv1.Deployment{}
+	err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, deployment)
+	require.NoError(t, err)
+
+	replicas := *deployment.Spec.Replicas
+	deployment.Status.ObservedGeneration = deployment.Generation
+	deployment.Status.Replicas = replicas
+	deployment.Status.UpdatedReplicas = replicas
+	deployment.Status.AvailableReplicas = replicas
+	err = reconciler.Client.Status().Update(context.TODO(), deployment)
+	require.NoError(t, err)
+}
+
+func getAppImage(t *testing.T, reconciler *MattermostReconciler, name string) string {
+	deployment := &appsv1.Deployment{}
+	err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, deployment)
+	require.NoError(t, err)
+
+	return mmv1beta.GetMattermostAppContainerFromDeployment(deployment).Image
+}
+
+func assertRolloutResourcesDeleted(t *testing.T, reconciler *MattermostReconciler, name string) {
+	key := types.NamespacedName{Name: name, Namespace: "default"}
+
+	err := reconciler.Client.Get(context.TODO(), key, &appsv1.Deployment{})
+	assert.True(t, k8sErrors.IsNotFound(err), "expected rollout deployment to be deleted")
+	err = reconciler.Client.Get(context.TODO(), key, &corev1.Service{})
+	assert.True(t, k8sErrors.IsNotFound(err), "expected rollout service to be deleted")
+	err = reconciler.Client.Get(context.TODO(), key, &networkingv1.Ingress{})
+	assert.True(t, k8sErrors.IsNotFound(err), "expected rollout ingress to be deleted")
+}
+
+func TestCheckMattermostCanaryRollout(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyCanary)
+	weight := int32(25)
+	mm.Spec.Rollout.Canary = &mmv1beta.CanaryRollout{
+		Weight: &weight,
+		Cookie: "canary",
+	}
+	status := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	require.True(t, recStatus.ResourcesReady)
+	require.Nil(t, status.Rollout)
+
+	stableImage := getAppImage(t, reconciler, mm.Name)
+	mm.Spec.Version = "10.0.0"
+	targetImage := mm.GetImageName()
+
+	t.Run("start", func(t *testing.T) {
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.False(t, recStatus.ResourcesReady)
+
+		require.NotNil(t, status.Rollout)
+		assert.Equal(t, mmv1beta.RolloutProgressing, status.Rollout.Phase)
+		assert.Equal(t, targetImage, status.Rollout.Image)
+		assert.Equal(t, stableImage, status.Rollout.StableImage)
+		assert.Equal(t, "foo-canary", status.Rollout.Deployment)
+		assert.Equal(t, int32(25), status.Rollout.Weight)
+		assert.Equal(t, int32(1), status.Rollout.Replicas)
+
+		assert.Equal(t, stableImage, getAppImage(t, reconciler, mm.Name))
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, "foo-canary"))
+
+		service := &corev1.Service{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "foo-canary", Namespace: mm.Namespace}, service)
+		require.NoError(t, err)
+		assert.Equal(t, mmv1beta.MattermostSelectorLabels("foo-canary"), service.Spec.Selector)
+
+		ingress := &networkingv1.Ingress{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "foo-canary", Namespace: mm.Namespace}, ingress)
+		require.NoError(t, err)
+		assert.Equal(t, "true", ingress.Annotations["nginx.ingress.kubernetes.io/canary"])
+		assert.Equal(t, "25", ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+		assert.Equal(t, "canary", ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-cookie"])
+		assert.Equal(t, "foo-canary", ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		setDeploymentRolledOut(t, reconciler, "foo-canary")
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.False(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutPromoting, status.Rollout.Phase)
+		assert.Equal(t, int32(100), status.Rollout.Weight)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+
+		ingress := &networkingv1.Ingress{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "foo-canary", Namespace: mm.Namespace}, ingress)
+		require.NoError(t, err)
+		assert.Equal(t, "100", ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+	})
+
+	t.Run("succeed", func(t *testing.T) {
+		setDeploymentRolledOut(t, reconciler, mm.Name)
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutSucceeded, status.Rollout.Phase)
+		assert.NotNil(t, status.Rollout.CompletionTime)
+		assertRolloutResourcesDeleted(t, reconciler, "foo-canary")
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutSucceeded, status.Rollout.Phase)
+	})
+}
+
+func TestCheckMattermostRolloutAbort(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyBlueGreen)
+	status := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	_, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+
+	stableImage := getAppImage(t, reconciler, mm.Name)
+	mm.Spec.Version = "10.0.0"
+	targetImage := mm.GetImageName()
+
+	recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	assert.False(t, recStatus.ResourcesReady)
+	require.NotNil(t, status.Rollout)
+	assert.Equal(t, "foo-preview", status.Rollout.Deployment)
+	assert.Equal(t, int32(0), status.Rollout.Weight)
+	assert.Equal(t, int32(3), status.Rollout.Replicas)
+
+	ingress := &networkingv1.Ingress{}
+	err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "foo-preview", Namespace: mm.Namespace}, ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "0", ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+	assert.Equal(t, mmv1beta.DefaultPreviewCookie, ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-cookie"])
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		status.Rollout.StartTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutAborted, status.Rollout.Phase)
+		assert.Equal(t, stableImage, getAppImage(t, reconciler, mm.Name))
+		assertRolloutResourcesDeleted(t, reconciler, "foo-preview")
+	})
+
+	t.Run("aborted image is not rolled out again", func(t *testing.T) {
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutAborted, status.Rollout.Phase)
+		assert.Equal(t, targetImage, status.Rollout.Image)
+		assert.Equal(t, stableImage, getAppImage(t, reconciler, mm.Name))
+		assertRolloutResourcesDeleted(t, reconciler, "foo-preview")
+	})
+
+	t.Run("new image starts new rollout", func(t *testing.T) {
+		mm.Spec.Version = "10.1.0"
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.False(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutProgressing, status.Rollout.Phase)
+		assert.Equal(t, mm.GetImageName(), status.Rollout.Image)
+		assert.Equal(t, mm.GetImageName(), getAppImage(t, reconciler, "foo-preview"))
+	})
+
+	t.Run("image changed back to stable", func(t *testing.T) {
+		mm.Spec.Version = operatortest.LatestStableMattermostVersion
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutAborted, status.Rollout.Phase)
+		assertRolloutResourcesDeleted(t, reconciler, "foo-preview")
+	})
+}
+
+func TestCheckMattermostRollingUpdateRollback(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mm := newRolloutTestMattermost(mmv1beta.RolloutStrategyRollingUpdate)
+	status := &mmv1beta.MattermostStatus{}
+	dbInfo, fileStoreInfo := fixedDBAndFileStoreInfo(t, mm)
+
+	_, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+	require.NoError(t, err)
+	require.Nil(t, status.Rollout)
+
+	stableImage := getAppImage(t, reconciler, mm.Name)
+	status.LastGoodImage = stableImage
+	mm.Spec.Version = "10.0.0"
+	targetImage := mm.GetImageName()
+
+	t.Run("start", func(t *testing.T) {
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		require.NotNil(t, status.Rollout)
+		assert.Equal(t, mmv1beta.RolloutStrategyRollingUpdate, status.Rollout.Strategy)
+		assert.Equal(t, mmv1beta.RolloutProgressing, status.Rollout.Phase)
+		assert.Equal(t, targetImage, status.Rollout.Image)
+		assert.Equal(t, stableImage, status.Rollout.StableImage)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+
+		recStatus, err = reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutProgressing, status.Rollout.Phase)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		status.Rollout.StartTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutAborted, status.Rollout.Phase)
+		assert.Equal(t, stableImage, getAppImage(t, reconciler, mm.Name))
+
+		condition := status.GetCondition(mmv1beta.ConditionRolledBack)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, mmv1beta.ReasonProgressDeadlineExceeded, condition.Reason)
+	})
+
+	t.Run("rolled back image is not rolled out again", func(t *testing.T) {
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutAborted, status.Rollout.Phase)
+		assert.Equal(t, stableImage, getAppImage(t, reconciler, mm.Name))
+	})
+
+	t.Run("spec change retries the image", func(t *testing.T) {
+		mm.Generation++
+
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutProgressing, status.Rollout.Phase)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+	})
+
+	t.Run("succeed", func(t *testing.T) {
+		setDeploymentRolledOut(t, reconciler, mm.Name)
+
+		recStatus, err := reconciler.checkMattermostDeployment(mm, dbInfo, fileStoreInfo, status, logger)
+		require.NoError(t, err)
+		assert.True(t, recStatus.ResourcesReady)
+		assert.Equal(t, mmv1beta.RolloutSucceeded, status.Rollout.Phase)
+		assert.Equal(t, targetImage, getAppImage(t, reconciler, mm.Name))
+
+		condition := status.GetCondition(mmv1beta.ConditionRolledBack)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	})
+}
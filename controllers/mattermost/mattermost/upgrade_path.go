@@ -0,0 +1,79 @@
+package mattermost
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkUpgradePath returns the version the installation is reconciled with.
+// Blocked upgrades keep the current version, and upgrades skipping major
+// versions are walked one major version at a time if the upgrade path is
+// enabled. The result is reported with the UpgradeAllowed condition.
+func (r *MattermostReconciler) checkUpgradePath(mattermost *mmv1beta.Mattermost, status *mmv1beta.MattermostStatus, reqLogger logr.Logger) string {
+	target := mattermost.Spec.Version
+	version, reason, err := upgradeStep(mattermost, status.Version)
+	if err != nil {
+		if condition := status.GetCondition(mmv1beta.ConditionUpgradeAllowed); condition == nil || condition.Message != err.Error() {
+			reqLogger.Info("Upgrade blocked, keeping the current version", "version", status.Version, "reason", err.Error())
+			r.Recorder.Event(mattermost, corev1.EventTypeWarning, eventReasonUpgradeBlocked, err.Error())
+		}
+		status.SetCondition(mmv1beta.ConditionUpgradeAllowed, metav1.ConditionFalse, reason, err.Error())
+		return status.Version
+	}
+
+	var message string
+	switch reason {
+	case mmv1beta.ReasonIntermediateUpgrade:
+		reqLogger.Info("Upgrading to intermediate version", "version", version, "target", target)
+		message = fmt.Sprintf("Upgrading to intermediate version %s on the way to %s", version, target)
+	case mmv1beta.ReasonUpgradeOverridden:
+		message = fmt.Sprintf("Upgrade to %s allowed with the %s annotation", target, mmv1beta.AllowUnsupportedUpgradeAnnotation)
+	default:
+		message = fmt.Sprintf("Version %s is supported", target)
+	}
+	status.SetCondition(mmv1beta.ConditionUpgradeAllowed, metav1.ConditionTrue, reason, message)
+
+	return version
+}
+
+// upgradeStep returns the next version of the upgrade from the current
+// version to the version in the spec, and the reason of the decision.
+// Versions which are not semantic versions, like digests, are not checked.
+func upgradeStep(mattermost *mmv1beta.Mattermost, current string) (string, string, error) {
+	target := mattermost.Spec.Version
+	if current == "" || current == target {
+		return target, mmv1beta.ReasonUpgradeSupported, nil
+	}
+
+	currentVersion, err := utils.ParseVersion(current)
+	if err != nil {
+		return target, mmv1beta.ReasonUpgradeSupported, nil
+	}
+	targetVersion, err := utils.ParseVersion(target)
+	if err != nil {
+		return target, mmv1beta.ReasonUpgradeSupported, nil
+	}
+
+	supported := targetVersion.Compare(currentVersion) >= 0 && targetVersion.Major-currentVersion.Major <= 1
+	switch {
+	case supported:
+		return target, mmv1beta.ReasonUpgradeSupported, nil
+	case mattermost.UnsupportedUpgradeAllowed():
+		return target, mmv1beta.ReasonUpgradeOverridden, nil
+	case targetVersion.Compare(currentVersion) < 0:
+		return "", mmv1beta.ReasonDowngradeBlocked, fmt.Errorf(
+			"downgrade from %s to %s is not supported as the database may be migrated already, annotate the Mattermost with %s=%s to roll it out anyway",
+			current, target, mmv1beta.AllowUnsupportedUpgradeAnnotation, target)
+	case mattermost.UpgradePathEnabled():
+		return mattermost.Spec.UpgradePath.IntermediateVersion(currentVersion.Major + 1), mmv1beta.ReasonIntermediateUpgrade, nil
+	default:
+		return "", mmv1beta.ReasonMajorVersionSkipBlocked, fmt.Errorf(
+			"upgrade from %s to %s skips major versions, upgrade one major version at a time or enable spec.upgradePath, or annotate the Mattermost with %s=%s to roll it out anyway",
+			current, target, mmv1beta.AllowUnsupportedUpgradeAnnotation, target)
+	}
+}
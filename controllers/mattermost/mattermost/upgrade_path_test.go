@@ -0,0 +1,151 @@
+package mattermost
+
+import (
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradeStep(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		current     string
+		target      string
+		upgradePath *mmv1beta.UpgradePath
+		annotation  string
+		expected    string
+		reason      string
+	}{
+		{
+			description: "new installation",
+			target:      "10.5.1",
+			expected:    "10.5.1",
+			reason:      mmv1beta.ReasonUpgradeSupported,
+		},
+		{
+			description: "minor upgrade",
+			current:     "10.4.2",
+			target:      "10.5.1",
+			expected:    "10.5.1",
+			reason:      mmv1beta.ReasonUpgradeSupported,
+		},
+		{
+			description: "next major version",
+			current:     "9.11.6",
+			target:      "10.5.1",
+			expected:    "10.5.1",
+			reason:      mmv1beta.ReasonUpgradeSupported,
+		},
+		{
+			description: "digest is not checked",
+			current:     "10.5.1",
+			target:      "sha256:dd15a51ac7dafd213744d1ef23394e7532f71a90f477c969b94600e46da5a0cf",
+			expected:    "sha256:dd15a51ac7dafd213744d1ef23394e7532f71a90f477c969b94600e46da5a0cf",
+			reason:      mmv1beta.ReasonUpgradeSupported,
+		},
+		{
+			description: "downgrade",
+			current:     "10.5.1",
+			target:      "10.4.2",
+			reason:      mmv1beta.ReasonDowngradeBlocked,
+		},
+		{
+			description: "downgrade is not walked",
+			current:     "10.5.1",
+			target:      "8.1.0",
+			upgradePath: &mmv1beta.UpgradePath{Enabled: true},
+			reason:      mmv1beta.ReasonDowngradeBlocked,
+		},
+		{
+			description: "allowed downgrade",
+			current:     "10.5.1",
+			target:      "10.4.2",
+			annotation:  "10.4.2",
+			expected:    "10.4.2",
+			reason:      mmv1beta.ReasonUpgradeOverridden,
+		},
+		{
+			description: "annotation for other version",
+			current:     "10.5.1",
+			target:      "10.4.2",
+			annotation:  "10.4.1",
+			reason:      mmv1beta.ReasonDowngradeBlocked,
+		},
+		{
+			description: "major version skipped",
+			current:     "8.1.5",
+			target:      "10.5.1",
+			reason:      mmv1beta.ReasonMajorVersionSkipBlocked,
+		},
+		{
+			description: "intermediate version",
+			current:     "8.1.5",
+			target:      "10.5.1",
+			upgradePath: &mmv1beta.UpgradePath{Enabled: true},
+			expected:    "9.0.0",
+			reason:      mmv1beta.ReasonIntermediateUpgrade,
+		},
+		{
+			description: "listed intermediate version",
+			current:     "8.1.5",
+			target:      "10.5.1",
+			upgradePath: &mmv1beta.UpgradePath{Enabled: true, IntermediateVersions: []string{"9.5.12", "9.11.6", "10.0.0"}},
+			expected:    "9.11.6",
+			reason:      mmv1beta.ReasonIntermediateUpgrade,
+		},
+		{
+			description: "disabled upgrade path",
+			current:     "8.1.5",
+			target:      "10.5.1",
+			upgradePath: &mmv1beta.UpgradePath{IntermediateVersions: []string{"9.11.6"}},
+			reason:      mmv1beta.ReasonMajorVersionSkipBlocked,
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			mm := &mmv1beta.Mattermost{
+				Spec: mmv1beta.MattermostSpec{
+					Version:     testCase.target,
+					UpgradePath: testCase.upgradePath,
+				},
+			}
+			if testCase.annotation != "" {
+				mm.Annotations = map[string]string{mmv1beta.AllowUnsupportedUpgradeAnnotation: testCase.annotation}
+			}
+
+			version, reason, err := upgradeStep(mm, testCase.current)
+			assert.Equal(t, testCase.reason, reason)
+			if testCase.expected == "" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, version)
+		})
+	}
+}
+
+func TestCheckUpgradePath(t *testing.T) {
+	logger, _, reconciler := setupTestDeps(t)
+
+	mm := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       mmv1beta.MattermostSpec{Version: "10.4.2"},
+	}
+	status := &mmv1beta.MattermostStatus{Version: "10.5.1"}
+
+	version := reconciler.checkUpgradePath(mm, status, logger)
+	assert.Equal(t, "10.5.1", version)
+	condition := status.GetCondition(mmv1beta.ConditionUpgradeAllowed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, mmv1beta.ReasonDowngradeBlocked, condition.Reason)
+	assert.Contains(t, condition.Message, "downgrade from 10.5.1 to 10.4.2 is not supported")
+
+	mm.Spec.Version = "10.6.0"
+	version = reconciler.checkUpgradePath(mm, status, logger)
+	assert.Equal(t, "10.6.0", version)
+	assert.True(t, status.IsConditionTrue(mmv1beta.ConditionUpgradeAllowed))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-logr/logr"
 	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/metrics"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -39,6 +40,9 @@ func (r *MattermostReconciler) updateStatusReconciling(mattermost *mmv1beta.Matt
 // This should only be used when the outcome of setting the state can be ignored.
 func (r *MattermostReconciler) updateStatusReconcilingAndLogError(mattermost *mmv1beta.Mattermost, status mmv1beta.MattermostStatus, reqLogger logr.Logger, statusErr error) {
 	if statusErr != nil {
+		if mattermost.Status.Error != statusErr.Error() {
+			r.Recorder.Event(mattermost, corev1.EventTypeWarning, eventReasonReconcileFailed, statusErr.Error())
+		}
 		status.Error = statusErr.Error()
 	}
 	err := r.updateStatusReconciling(mattermost, status, reqLogger)
@@ -48,12 +52,15 @@ func (r *MattermostReconciler) updateStatusReconcilingAndLogError(mattermost *mm
 }
 
 func (r *MattermostReconciler) updateStatus(mattermost *mmv1beta.Mattermost, status mmv1beta.MattermostStatus, reqLogger logr.Logger) error {
+	metrics.SetInstallationState(mattermost.Namespace, mattermost.Name, status.State)
+
 	if reflect.DeepEqual(mattermost.Status, status) {
 		return nil
 	}
 
 	if mattermost.Status.State != status.State {
 		reqLogger.Info(fmt.Sprintf("Updating Mattermost state from '%s' to '%s'", mattermost.Status.State, status.State))
+		r.Recorder.Eventf(mattermost, corev1.EventTypeNormal, eventReasonStateChanged, "Mattermost state changed from '%s' to '%s'", mattermost.Status.State, status.State)
 	}
 
 	mattermost.Status = status
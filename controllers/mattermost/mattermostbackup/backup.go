@@ -0,0 +1,250 @@
+package mattermostbackup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/operation"
+	"github.com/mattermost/mattermost-operator/pkg/components/backup"
+	mattermostMinio "github.com/mattermost/mattermost-operator/pkg/components/minio"
+	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	mattermostpostgres "github.com/mattermost/mattermost-operator/pkg/components/postgres"
+	"github.com/mattermost/mattermost-operator/pkg/database"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// startBackup reads the database and file store configuration of Mattermost
+// and launches the backup job.
+func (r *MattermostBackupReconciler) startBackup(mmBackup *mmv1beta.MattermostBackup, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if err := validateBackup(mmBackup); err != nil {
+		return reconcile.Result{}, r.fail(mmBackup, err.Error(), reqLogger)
+	}
+
+	var dbType string
+	var dbConfig mattermostApp.DatabaseConfig
+	var fileStore mattermostApp.FileStoreConfig
+	var err error
+
+	if !mmBackup.Spec.SkipDatabase {
+		dbType, dbConfig, err = r.databaseConfig(mattermost)
+		if err != nil {
+			return r.pending(mmBackup, fmt.Sprintf("Failed to read database configuration: %s", err), reqLogger)
+		}
+	}
+	if !mmBackup.Spec.SkipFileStore {
+		fileStore, err = r.fileStoreConfig(mattermost)
+		if err != nil {
+			return r.pending(mmBackup, fmt.Sprintf("Failed to read file store configuration: %s", err), reqLogger)
+		}
+	}
+
+	status := *mmBackup.Status.DeepCopy()
+	status.StartTime = operation.Now()
+	path := backup.Path(mmBackup, status.StartTime.Time)
+
+	err = r.Resources.Create(mmBackup, backup.Job(mmBackup, mattermost, path, dbType, dbConfig, fileStore), reqLogger)
+	if err != nil {
+		if !k8sErrors.IsAlreadyExists(err) {
+			return reconcile.Result{}, errors.Wrap(err, "failed to create backup job")
+		}
+		// The job was created by a previous attempt which failed to update
+		// the status, the backup is stored in the path of that job.
+		job := &batchv1.Job{}
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: backup.JobName(mmBackup), Namespace: mmBackup.Namespace}, job)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to get backup job")
+		}
+		path = backup.JobPath(job)
+		if path == "" {
+			return reconcile.Result{}, r.fail(mmBackup, "Backup job does not define the backup path", reqLogger)
+		}
+		status.StartTime = job.CreationTimestamp.DeepCopy()
+	}
+
+	status.Phase = mmv1beta.BackupRunning
+	status.Message = "Backup job is running"
+	status.Location = fmt.Sprintf("s3://%s", path)
+	status.DatabaseLocation = ""
+	if dbType != "" {
+		status.DatabaseLocation = fmt.Sprintf("%s/%s", status.Location, backup.DumpFile(dbType))
+	}
+
+	return reconcile.Result{RequeueAfter: requeueBackupDelay}, r.updateStatus(mmBackup, status, reqLogger)
+}
+
+func validateBackup(mmBackup *mmv1beta.MattermostBackup) error {
+	if !strings.HasPrefix(mmBackup.Spec.Destination.URL, "s3://") || strings.Trim(strings.TrimPrefix(mmBackup.Spec.Destination.URL, "s3://"), "/") == "" {
+		return errors.New("destination.url has to be in the s3://bucket/prefix format")
+	}
+	if mmBackup.Spec.Destination.Secret == "" {
+		return errors.New("destination.secret has to be set")
+	}
+	if mmBackup.Spec.SkipDatabase && mmBackup.Spec.SkipFileStore {
+		return errors.New("at least one of the database and the file store has to be backed up")
+	}
+
+	return nil
+}
+
+// pending keeps the backup pending until the configuration of Mattermost can
+// be read.
+func (r *MattermostBackupReconciler) pending(mmBackup *mmv1beta.MattermostBackup, message string, reqLogger logr.Logger) (reconcile.Result, error) {
+	reqLogger.Info("Backup can not be started", "reason", message)
+
+	status := *mmBackup.Status.DeepCopy()
+	status.Phase = mmv1beta.BackupPending
+	status.Message = message
+
+	return reconcile.Result{RequeueAfter: requeueBackupDelay}, r.updateStatus(mmBackup, status, reqLogger)
+}
+
+// databaseConfig returns the type and the configuration of the database used
+// by Mattermost.
+func (r *MattermostBackupReconciler) databaseConfig(mattermost *mmv1beta.Mattermost) (string, mattermostApp.DatabaseConfig, error) {
+	dbSpec := mattermost.Spec.Database.DeepCopy()
+	dbSpec.SetDefaults()
+
+	if dbSpec.IsExternal() {
+		secret, err := r.getSecret(dbSpec.External.Secret, mattermost.Namespace)
+		if err != nil {
+			return "", nil, err
+		}
+		dbType := database.GetTypeFromConnectionString(string(secret.Data["DB_CONNECTION_STRING"]))
+		if dbType != database.MySQLDatabase && dbType != database.PostgreSQLDatabase {
+			return "", nil, errors.New("unable to determine the type of the external database from the connection string")
+		}
+		dbConfig, err := mattermostApp.NewExternalDBConfig(mattermost, *secret)
+		return dbType, dbConfig, err
+	}
+
+	switch dbSpec.OperatorManaged.Type {
+	case mmv1beta.DatabaseTypeMySQL:
+		secret, err := r.getSecret(mattermostmysql.DefaultDatabaseSecretName(mattermost.Name), mattermost.Namespace)
+		if err != nil {
+			return "", nil, err
+		}
+		dbConfig, err := mattermostApp.NewMySQLDBConfig(*secret)
+		return database.MySQLDatabase, dbConfig, err
+	case mmv1beta.DatabaseTypePostgres:
+		secret, err := r.getSecret(mattermostpostgres.DefaultDatabaseSecretName(mattermost.Name), mattermost.Namespace)
+		if err != nil {
+			return "", nil, err
+		}
+		dbConfig, err := mattermostApp.NewPostgresDBConfig(*secret)
+		return database.PostgreSQLDatabase, dbConfig, err
+	}
+
+	return "", nil, fmt.Errorf("database of type '%s' is not supported", dbSpec.OperatorManaged.Type)
+}
+
+// fileStoreConfig returns the configuration of the file store used by Mattermost.
+func (r *MattermostBackupReconciler) fileStoreConfig(mattermost *mmv1beta.Mattermost) (mattermostApp.FileStoreConfig, error) {
+	if mattermost.Spec.FileStore.IsExternal() {
+		if mattermost.Spec.FileStore.External.UseServiceAccount {
+			return mattermostApp.NewExternalFileStoreInfo(mattermost, nil)
+		}
+		secret, err := r.getSecret(mattermost.Spec.FileStore.External.Secret, mattermost.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		return mattermostApp.NewExternalFileStoreInfo(mattermost, secret)
+	}
+
+	if mattermost.Spec.FileStore.IsExternalVolume() {
+		return mattermostApp.NewExternalVolumeFileStoreInfo(mattermost)
+	}
+
+	if mattermost.Spec.FileStore.IsLocal() {
+		return mattermostApp.NewLocalFileStoreInfo(), nil
+	}
+
+	url, err := r.Resources.GetMinioService(mattermost.Name, mattermost.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get Minio service")
+	}
+
+	return mattermostApp.NewOperatorManagedFileStoreInfo(mattermost, mattermostMinio.DefaultMinioSecretName(mattermost.Name), url), nil
+}
+
+func (r *MattermostBackupReconciler) getSecret(name, namespace string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get secret %s", name)
+	}
+	return secret, nil
+}
+
+// checkBackupJob records the result of the backup job.
+func (r *MattermostBackupReconciler) checkBackupJob(mmBackup *mmv1beta.MattermostBackup, reqLogger logr.Logger) (reconcile.Result, error) {
+	job := &batchv1.Job{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: backup.JobName(mmBackup), Namespace: mmBackup.Namespace}, job)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return reconcile.Result{}, r.fail(mmBackup, "Backup job not found", reqLogger)
+		}
+		return reconcile.Result{}, errors.Wrap(err, "failed to get backup job")
+	}
+
+	if job.Status.Failed > 0 {
+		reqLogger.Info("Backup job failed", "Job.Name", job.Name)
+		return reconcile.Result{}, r.fail(mmBackup, "Backup job failed, check its logs for details", reqLogger)
+	}
+	if job.Status.Succeeded == 0 {
+		return reconcile.Result{RequeueAfter: requeueBackupDelay}, nil
+	}
+
+	size, err := r.backupSize(job)
+	if err != nil {
+		// The size is informative only, the backup is stored anyway.
+		reqLogger.Error(err, "Failed to read the size of the backup")
+	}
+
+	status := *mmBackup.Status.DeepCopy()
+	status.Phase = mmv1beta.BackupSucceeded
+	status.Message = "Backup completed"
+	status.SizeBytes = size
+	status.CompletionTime = operation.Now()
+	if job.Status.CompletionTime != nil {
+		status.CompletionTime = job.Status.CompletionTime.DeepCopy()
+	}
+	if status.StartTime != nil {
+		status.Duration = &metav1.Duration{Duration: status.CompletionTime.Sub(status.StartTime.Time).Round(time.Second)}
+	}
+
+	return reconcile.Result{}, r.updateStatus(mmBackup, status, reqLogger)
+}
+
+// backupSize reads the size of the backup reported by the upload container
+// in its termination message.
+func (r *MattermostBackupReconciler) backupSize(job *batchv1.Job) (int64, error) {
+	pods := &corev1.PodList{}
+	err := r.NonCachedAPIReader.List(context.TODO(), pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list backup job pods")
+	}
+
+	for _, pod := range pods.Items {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name != backup.UploadContainerName || containerStatus.State.Terminated == nil || containerStatus.State.Terminated.ExitCode != 0 {
+				continue
+			}
+			return strconv.ParseInt(strings.TrimSpace(containerStatus.State.Terminated.Message), 10, 64)
+		}
+	}
+
+	return 0, errors.New("backup job pod with the size of the backup not found")
+}
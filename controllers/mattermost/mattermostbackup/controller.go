@@ -0,0 +1,91 @@
+package mattermostbackup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/operation"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	batchv1 "k8s.io/api/batch/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const requeueBackupDelay = 10 * time.Second
+
+// MattermostBackupReconciler reconciles a MattermostBackup object
+type MattermostBackupReconciler struct {
+	client.Client
+	NonCachedAPIReader client.Reader
+	Log                logr.Logger
+	Scheme             *runtime.Scheme
+	Recorder           record.EventRecorder
+	Resources          *resources.ResourceHelper
+}
+
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostbackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostbackups/status,verbs=get;update;patch
+
+func (r *MattermostBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mmv1beta.MattermostBackup{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
+
+func (r *MattermostBackupReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling MattermostBackup")
+
+	backup := &mmv1beta.MattermostBackup{}
+	err := r.Client.Get(ctx, request.NamespacedName, backup)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if backup.IsFinished() {
+		return reconcile.Result{}, nil
+	}
+
+	mattermost := &mmv1beta.Mattermost{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: backup.Spec.MattermostName, Namespace: backup.Namespace}, mattermost)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			reqLogger.Error(err, "Mattermost installation not found", "Mattermost.Name", backup.Spec.MattermostName)
+			return reconcile.Result{}, r.fail(backup, fmt.Sprintf("Mattermost installation %q not found", backup.Spec.MattermostName), reqLogger)
+		}
+		return reconcile.Result{}, err
+	}
+
+	switch backup.Status.Phase {
+	case "", mmv1beta.BackupPending:
+		return r.startBackup(backup, mattermost, reqLogger)
+	case mmv1beta.BackupRunning:
+		return r.checkBackupJob(backup, reqLogger)
+	}
+
+	return reconcile.Result{}, fmt.Errorf("unknown backup phase %q", backup.Status.Phase)
+}
+
+// fail marks the backup as failed.
+func (r *MattermostBackupReconciler) fail(backup *mmv1beta.MattermostBackup, message string, reqLogger logr.Logger) error {
+	status := *backup.Status.DeepCopy()
+	status.Phase = mmv1beta.BackupFailed
+	status.Message = message
+	status.CompletionTime = operation.Now()
+
+	return r.updateStatus(backup, status, reqLogger)
+}
@@ -0,0 +1,322 @@
+package mattermostbackup
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/controllertest"
+	"github.com/mattermost/mattermost-operator/pkg/components/backup"
+	mattermostMinio "github.com/mattermost/mattermost-operator/pkg/components/minio"
+	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	mmName      = controllertest.MattermostName
+	mmNamespace = controllertest.Namespace
+	backupName  = "backup"
+)
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *MattermostBackupReconciler {
+	deps := controllertest.NewDeps(t, objects...)
+	return &MattermostBackupReconciler{
+		Client:             deps.Client,
+		NonCachedAPIReader: deps.Client,
+		Log:                deps.Log,
+		Scheme:             deps.Scheme,
+		Recorder:           deps.Recorder,
+		Resources:          deps.Resources,
+	}
+}
+
+// newTestMattermost returns Mattermost with Operator-managed MySQL database
+// and MinIO file store.
+func newTestMattermost() *mmv1beta.Mattermost {
+	mattermost := controllertest.NewMattermost()
+	mattermost.Spec.Database = mmv1beta.Database{
+		OperatorManaged: &mmv1beta.OperatorManagedDatabase{Type: mmv1beta.DatabaseTypeMySQL},
+	}
+	mattermost.Spec.FileStore = mmv1beta.FileStore{
+		OperatorManaged: &mmv1beta.OperatorManagedMinio{},
+	}
+	return mattermost
+}
+
+func newTestBackup() *mmv1beta.MattermostBackup {
+	return &mmv1beta.MattermostBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupName,
+			Namespace: mmNamespace,
+			UID:       types.UID("backup"),
+		},
+		Spec: mmv1beta.MattermostBackupSpec{
+			MattermostName: mmName,
+			Destination: mmv1beta.BackupDestination{
+				URL:    "s3://backups/mattermost/",
+				Secret: "backup-credentials",
+			},
+			Retention: &mmv1beta.BackupRetention{KeepLast: 3},
+		},
+	}
+}
+
+func newTestMySQLSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: mattermostmysql.DefaultDatabaseSecretName(mmName), Namespace: mmNamespace},
+		Data: map[string][]byte{
+			"ROOT_PASSWORD": []byte("root"),
+			"USER":          []byte("mmuser"),
+			"PASSWORD":      []byte("pass"),
+			"DATABASE":      []byte("mattermost"),
+		},
+	}
+}
+
+func newTestMinioService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: mmName + "-minio-hl-svc", Namespace: mmNamespace},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Port: 9000}},
+		},
+	}
+}
+
+func reconcileBackup(t *testing.T, r *MattermostBackupReconciler) reconcile.Result {
+	return controllertest.Reconcile(t, r, backupName, mmNamespace)
+}
+
+func getBackup(t *testing.T, r *MattermostBackupReconciler) *mmv1beta.MattermostBackup {
+	return controllertest.Get(t, r.Client, backupName, mmNamespace, &mmv1beta.MattermostBackup{})
+}
+
+func getJob(t *testing.T, r *MattermostBackupReconciler) *batchv1.Job {
+	return controllertest.Get(t, r.Client, backup.JobName(getBackup(t, r)), mmNamespace, &batchv1.Job{})
+}
+
+func setJobStatus(t *testing.T, r *MattermostBackupReconciler, status batchv1.JobStatus) {
+	controllertest.SetJobStatus(t, r.Client, backup.JobName(getBackup(t, r)), mmNamespace, status)
+}
+
+func findEnv(env []corev1.EnvVar, name string) *corev1.EnvVar {
+	for i := range env {
+		if env[i].Name == name {
+			return &env[i]
+		}
+	}
+	return nil
+}
+
+func TestBackup(t *testing.T) {
+	r := newTestReconciler(t, newTestMattermost(), newTestBackup(), newTestMySQLSecret(), newTestMinioService())
+
+	t.Run("launch job", func(t *testing.T) {
+		res := reconcileBackup(t, r)
+		assert.Equal(t, requeueBackupDelay, res.RequeueAfter)
+
+		mmBackup := getBackup(t, r)
+		assert.Equal(t, mmv1beta.BackupRunning, mmBackup.Status.Phase)
+		require.NotNil(t, mmBackup.Status.StartTime)
+		assert.True(t, strings.HasPrefix(mmBackup.Status.Location, "s3://backups/mattermost/foo/"))
+		assert.True(t, strings.HasSuffix(mmBackup.Status.Location, "-backup"))
+		assert.Equal(t, mmBackup.Status.Location+"/"+backup.MySQLDumpFile, mmBackup.Status.DatabaseLocation)
+
+		job := getJob(t, r)
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		dump := job.Spec.Template.Spec.InitContainers[0]
+		assert.Equal(t, backup.DefaultMySQLDumpImage, dump.Image)
+		assert.Equal(t, "$(MM_CONFIG)", findEnv(dump.Env, "DB_CONNECTION_STRING").Value)
+		assert.Contains(t, findEnv(dump.Env, "MM_CONFIG").Value, "-mysql-master.default.svc.cluster.local:3306")
+
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		upload := job.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, "backups/mattermost/foo", findEnv(upload.Env, "BACKUP_PREFIX").Value)
+		assert.Equal(t, strings.TrimPrefix(mmBackup.Status.Location, "s3://"), findEnv(upload.Env, "BACKUP_PATH").Value)
+		assert.Equal(t, "3", findEnv(upload.Env, "KEEP_LAST").Value)
+		assert.Equal(t, backup.RetentionPattern(mmBackup), findEnv(upload.Env, "RETENTION_PATTERN").Value)
+		assert.Equal(t, "backup-credentials", findEnv(upload.Env, "TARGET_ACCESS_KEY_ID").ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, "foo-minio-hl-svc.default.svc.cluster.local:9000", findEnv(upload.Env, "MM_FILESETTINGS_AMAZONS3ENDPOINT").Value)
+		assert.Equal(t, mattermostMinio.DefaultMinioSecretName(mmName), findEnv(upload.Env, "MM_FILESETTINGS_AMAZONS3ACCESSKEYID").ValueFrom.SecretKeyRef.Name)
+	})
+
+	t.Run("wait for job", func(t *testing.T) {
+		res := reconcileBackup(t, r)
+		assert.Equal(t, requeueBackupDelay, res.RequeueAfter)
+		assert.Equal(t, mmv1beta.BackupRunning, getBackup(t, r).Status.Phase)
+	})
+
+	t.Run("succeeded", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "backup-backup-abcde",
+				Namespace: mmNamespace,
+				Labels:    map[string]string{"job-name": backup.JobName(getBackup(t, r))},
+			},
+		}
+		require.NoError(t, r.Client.Create(context.TODO(), pod))
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name:  backup.UploadContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "1048576\n"}},
+			},
+		}
+		require.NoError(t, r.Client.Status().Update(context.TODO(), pod))
+
+		setJobStatus(t, r, batchv1.JobStatus{Succeeded: 1})
+		reconcileBackup(t, r)
+
+		mmBackup := getBackup(t, r)
+		assert.Equal(t, mmv1beta.BackupSucceeded, mmBackup.Status.Phase)
+		assert.Equal(t, int64(1048576), mmBackup.Status.SizeBytes)
+		assert.NotNil(t, mmBackup.Status.CompletionTime)
+		assert.NotNil(t, mmBackup.Status.Duration)
+	})
+}
+
+func TestBackupJobExists(t *testing.T) {
+	mmBackup := newTestBackup()
+	// The job was created by an attempt which failed to update the status.
+	path := "backups/mattermost/foo/20250101T000000Z-backup"
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              backup.JobName(mmBackup),
+			Namespace:         mmNamespace,
+			CreationTimestamp: metav1.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: backup.UploadContainerName,
+							Env:  []corev1.EnvVar{{Name: "BACKUP_PATH", Value: path}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	r := newTestReconciler(t, newTestMattermost(), mmBackup, newTestMySQLSecret(), newTestMinioService(), job)
+	reconcileBackup(t, r)
+
+	mmBackup = getBackup(t, r)
+	assert.Equal(t, mmv1beta.BackupRunning, mmBackup.Status.Phase)
+	assert.Equal(t, "s3://"+path, mmBackup.Status.Location)
+	assert.Equal(t, "s3://"+path+"/"+backup.MySQLDumpFile, mmBackup.Status.DatabaseLocation)
+	assert.Equal(t, job.CreationTimestamp.Unix(), mmBackup.Status.StartTime.Unix())
+}
+
+func TestBackupExternalDatabase(t *testing.T) {
+	mm := newTestMattermost()
+	mm.Spec.Database = mmv1beta.Database{External: &mmv1beta.ExternalDatabase{Secret: "db"}}
+	mm.Spec.FileStore = mmv1beta.FileStore{Local: &mmv1beta.LocalFileStore{Enabled: true}}
+	dbSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: mmNamespace},
+		Data:       map[string][]byte{"DB_CONNECTION_STRING": []byte("postgres://user:pass@db:5432/mattermost")},
+	}
+
+	r := newTestReconciler(t, mm, newTestBackup(), dbSecret)
+	reconcileBackup(t, r)
+
+	mmBackup := getBackup(t, r)
+	assert.Equal(t, mmv1beta.BackupRunning, mmBackup.Status.Phase)
+	assert.Equal(t, mmBackup.Status.Location+"/"+backup.PostgresDumpFile, mmBackup.Status.DatabaseLocation)
+
+	job := getJob(t, r)
+	dump := job.Spec.Template.Spec.InitContainers[0]
+	assert.Equal(t, backup.DefaultPostgresDumpImage, dump.Image)
+	assert.Equal(t, "db", findEnv(dump.Env, "MM_CONFIG").ValueFrom.SecretKeyRef.Name)
+
+	upload := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "local", findEnv(upload.Env, "MM_FILESETTINGS_DRIVERNAME").Value)
+	require.Len(t, job.Spec.Template.Spec.Volumes, 2)
+	assert.Equal(t, mmName, job.Spec.Template.Spec.Volumes[1].PersistentVolumeClaim.ClaimName)
+	assert.True(t, upload.VolumeMounts[1].ReadOnly)
+
+	setJobStatus(t, r, batchv1.JobStatus{Failed: 1})
+	reconcileBackup(t, r)
+	assert.Equal(t, mmv1beta.BackupFailed, getBackup(t, r).Status.Phase)
+}
+
+func TestBackupSkipDatabase(t *testing.T) {
+	mmBackup := newTestBackup()
+	mmBackup.Spec.SkipDatabase = true
+
+	r := newTestReconciler(t, newTestMattermost(), mmBackup, newTestMinioService())
+	reconcileBackup(t, r)
+
+	mmBackup = getBackup(t, r)
+	assert.Equal(t, mmv1beta.BackupRunning, mmBackup.Status.Phase)
+	assert.Empty(t, mmBackup.Status.DatabaseLocation)
+	assert.Empty(t, getJob(t, r).Spec.Template.Spec.InitContainers)
+}
+
+func TestBackupPending(t *testing.T) {
+	r := newTestReconciler(t, newTestMattermost(), newTestBackup(), newTestMinioService())
+
+	res := reconcileBackup(t, r)
+	assert.Equal(t, requeueBackupDelay, res.RequeueAfter)
+
+	mmBackup := getBackup(t, r)
+	assert.Equal(t, mmv1beta.BackupPending, mmBackup.Status.Phase)
+	assert.Contains(t, mmBackup.Status.Message, "database")
+}
+
+func TestBackupValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		mutate      func(mmBackup *mmv1beta.MattermostBackup)
+	}{
+		{
+			description: "invalid destination URL",
+			mutate: func(mmBackup *mmv1beta.MattermostBackup) {
+				mmBackup.Spec.Destination.URL = "https://backups"
+			},
+		},
+		{
+			description: "no bucket",
+			mutate: func(mmBackup *mmv1beta.MattermostBackup) {
+				mmBackup.Spec.Destination.URL = "s3://"
+			},
+		},
+		{
+			description: "no secret",
+			mutate: func(mmBackup *mmv1beta.MattermostBackup) {
+				mmBackup.Spec.Destination.Secret = ""
+			},
+		},
+		{
+			description: "nothing to back up",
+			mutate: func(mmBackup *mmv1beta.MattermostBackup) {
+				mmBackup.Spec.SkipDatabase = true
+				mmBackup.Spec.SkipFileStore = true
+			},
+		},
+		{
+			description: "Mattermost not found",
+			mutate: func(mmBackup *mmv1beta.MattermostBackup) {
+				mmBackup.Spec.MattermostName = "bar"
+			},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			mmBackup := newTestBackup()
+			testCase.mutate(mmBackup)
+
+			r := newTestReconciler(t, newTestMattermost(), mmBackup, newTestMySQLSecret(), newTestMinioService())
+			reconcileBackup(t, r)
+
+			assert.Equal(t, mmv1beta.BackupFailed, getBackup(t, r).Status.Phase)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package mattermostbackup
+
+// Reasons of the events emitted on MattermostBackup resources.
+const (
+	eventReasonPhaseChanged = "PhaseChanged"
+)
@@ -0,0 +1,33 @@
+package mattermostbackup
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (r *MattermostBackupReconciler) updateStatus(backup *mmv1beta.MattermostBackup, status mmv1beta.MattermostBackupStatus, reqLogger logr.Logger) error {
+	if reflect.DeepEqual(backup.Status, status) {
+		return nil
+	}
+
+	if backup.Status.Phase != status.Phase {
+		eventType := corev1.EventTypeNormal
+		if status.Phase == mmv1beta.BackupFailed {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Eventf(backup, eventType, eventReasonPhaseChanged, "Backup phase changed from '%s' to '%s': %s", backup.Status.Phase, status.Phase, status.Message)
+	}
+
+	backup.Status = status
+	err := r.Client.Status().Update(context.TODO(), backup)
+	if err != nil {
+		reqLogger.Error(err, "failed to update the mattermostbackup status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package mattermostbackupschedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const requeueActiveBackupDelay = 30 * time.Second
+
+// MattermostBackupScheduleReconciler reconciles a MattermostBackupSchedule object
+type MattermostBackupScheduleReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Resources *resources.ResourceHelper
+}
+
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostbackupschedules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostbackupschedules/status,verbs=get;update;patch
+
+func (r *MattermostBackupScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mmv1beta.MattermostBackupSchedule{}).
+		Owns(&mmv1beta.MattermostBackup{}).
+		Complete(r)
+}
+
+func (r *MattermostBackupScheduleReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling MattermostBackupSchedule")
+
+	schedule := &mmv1beta.MattermostBackupSchedule{}
+	err := r.Client.Get(ctx, request.NamespacedName, schedule)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status := *schedule.Status.DeepCopy()
+
+	cron, err := utils.ParseCronSchedule(schedule.Spec.Schedule)
+	if err != nil {
+		if status.Error != err.Error() {
+			r.Recorder.Event(schedule, corev1.EventTypeWarning, eventReasonInvalidSchedule, err.Error())
+		}
+		status.Error = err.Error()
+		status.NextScheduleTime = nil
+		// The schedule is reconciled again once the spec is updated.
+		return reconcile.Result{}, r.updateStatus(schedule, status, reqLogger)
+	}
+	status.Error = ""
+
+	backups, err := r.listBackups(schedule)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	active := false
+	for _, backup := range backups {
+		if !backup.IsFinished() {
+			active = true
+		}
+		if backup.Status.Phase == mmv1beta.BackupSucceeded && backup.Status.CompletionTime != nil &&
+			(status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(backup.Status.CompletionTime)) {
+			status.LastSuccessfulTime = backup.Status.CompletionTime.DeepCopy()
+		}
+	}
+
+	err = r.pruneBackups(schedule, backups, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now().UTC()
+	lastScheduleTime := schedule.CreationTimestamp.Time
+	if status.LastScheduleTime != nil {
+		lastScheduleTime = status.LastScheduleTime.Time
+	}
+	scheduledTime := mostRecentScheduleTime(cron, lastScheduleTime, now)
+
+	result := reconcile.Result{}
+	switch {
+	case scheduledTime.IsZero() || schedule.Spec.Suspend:
+	case active:
+		// Backups of the same schedule never run concurrently.
+		reqLogger.Info("Previous backup is still running, postponing the scheduled backup")
+		result.RequeueAfter = requeueActiveBackupDelay
+	default:
+		name, err := r.createBackup(schedule, scheduledTime, reqLogger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		status.LastBackup = name
+		status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+		lastScheduleTime = scheduledTime
+	}
+
+	status.NextScheduleTime = nil
+	if !schedule.Spec.Suspend {
+		next := cron.Next(lastScheduleTime.UTC())
+		if !next.After(now) {
+			next = cron.Next(now)
+		}
+		status.NextScheduleTime = &metav1.Time{Time: next}
+		if result.RequeueAfter == 0 {
+			result.RequeueAfter = next.Sub(now)
+		}
+	}
+
+	return result, r.updateStatus(schedule, status, reqLogger)
+}
+
+// mostRecentScheduleTime returns the most recent schedule time after the last
+// schedule time which is not after now, or zero time when there is none.
+// Only the most recent missed schedule is run.
+func mostRecentScheduleTime(cron *utils.CronSchedule, lastScheduleTime, now time.Time) time.Time {
+	// Schedules missed long ago are not relevant.
+	if earliest := now.AddDate(-1, 0, 0); lastScheduleTime.Before(earliest) {
+		lastScheduleTime = earliest
+	}
+
+	scheduledTime := time.Time{}
+	for next := cron.Next(lastScheduleTime.UTC()); !next.IsZero() && !next.After(now); next = cron.Next(next) {
+		scheduledTime = next
+	}
+	return scheduledTime
+}
+
+// listBackups returns backups created by the schedule, the oldest first.
+func (r *MattermostBackupScheduleReconciler) listBackups(schedule *mmv1beta.MattermostBackupSchedule) ([]mmv1beta.MattermostBackup, error) {
+	backupList := &mmv1beta.MattermostBackupList{}
+	err := r.Client.List(context.TODO(), backupList,
+		client.InNamespace(schedule.Namespace),
+		client.MatchingLabels{mmv1beta.BackupScheduleLabel: schedule.Name},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	backups := backupList.Items
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreationTimestamp.Equal(&backups[j].CreationTimestamp) {
+			return backups[i].CreationTimestamp.Before(&backups[j].CreationTimestamp)
+		}
+		return backups[i].Name < backups[j].Name
+	})
+
+	return backups, nil
+}
+
+// pruneBackups deletes finished backups exceeding the retention of the
+// template. Succeeded and failed backups are counted separately, so that
+// failed runs never cause the deletion of successful backups.
+func (r *MattermostBackupScheduleReconciler) pruneBackups(schedule *mmv1beta.MattermostBackupSchedule, backups []mmv1beta.MattermostBackup, reqLogger logr.Logger) error {
+	if schedule.Spec.Template.Retention == nil {
+		return nil
+	}
+
+	var succeeded, failed []mmv1beta.MattermostBackup
+	for _, backup := range backups {
+		switch backup.Status.Phase {
+		case mmv1beta.BackupSucceeded:
+			succeeded = append(succeeded, backup)
+		case mmv1beta.BackupFailed:
+			failed = append(failed, backup)
+		}
+	}
+
+	keepLast := int(schedule.Spec.Template.Retention.KeepLast)
+	for _, finished := range [][]mmv1beta.MattermostBackup{succeeded, failed} {
+		for i := 0; i < len(finished)-keepLast; i++ {
+			backup := finished[i]
+			reqLogger.Info("Deleting backup exceeding retention", "MattermostBackup.Name", backup.Name)
+			err := r.Client.Delete(context.TODO(), &backup)
+			if err != nil && !k8sErrors.IsNotFound(err) {
+				return errors.Wrap(err, "failed to delete backup")
+			}
+			r.Recorder.Eventf(schedule, corev1.EventTypeNormal, eventReasonBackupDeleted, "Deleted backup %s exceeding retention", backup.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *MattermostBackupScheduleReconciler) createBackup(schedule *mmv1beta.MattermostBackupSchedule, scheduledTime time.Time, reqLogger logr.Logger) (string, error) {
+	backup := &mmv1beta.MattermostBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			// Named after the scheduled time, so that the backup is
+			// created only once.
+			Name:      fmt.Sprintf("%s-%d", schedule.Name, scheduledTime.Unix()),
+			Namespace: schedule.Namespace,
+			Labels:    map[string]string{mmv1beta.BackupScheduleLabel: schedule.Name},
+		},
+		Spec: *schedule.Spec.Template.DeepCopy(),
+	}
+
+	reqLogger.Info("Creating scheduled backup", "MattermostBackup.Name", backup.Name)
+	err := r.Resources.Create(schedule, backup, reqLogger)
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return "", errors.Wrap(err, "failed to create backup")
+	}
+	r.Recorder.Eventf(schedule, corev1.EventTypeNormal, eventReasonBackupCreated, "Created backup %s", backup.Name)
+
+	return backup.Name, nil
+}
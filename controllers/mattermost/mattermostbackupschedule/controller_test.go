@@ -0,0 +1,200 @@
+package mattermostbackupschedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/controllertest"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	scheduleName = "nightly"
+	namespace    = controllertest.Namespace
+)
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *MattermostBackupScheduleReconciler {
+	deps := controllertest.NewDeps(t, objects...)
+	return &MattermostBackupScheduleReconciler{
+		Client:    deps.Client,
+		Log:       deps.Log,
+		Scheme:    deps.Scheme,
+		Recorder:  deps.Recorder,
+		Resources: deps.Resources,
+	}
+}
+
+func newTestSchedule(schedule string, lastScheduleTime time.Time) *mmv1beta.MattermostBackupSchedule {
+	return &mmv1beta.MattermostBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              scheduleName,
+			Namespace:         namespace,
+			UID:               types.UID("schedule"),
+			CreationTimestamp: metav1.Time{Time: lastScheduleTime},
+		},
+		Spec: mmv1beta.MattermostBackupScheduleSpec{
+			Schedule: schedule,
+			Template: mmv1beta.MattermostBackupSpec{
+				MattermostName: controllertest.MattermostName,
+				Destination: mmv1beta.BackupDestination{
+					URL:    "s3://backups",
+					Secret: "backup-credentials",
+				},
+				Retention: &mmv1beta.BackupRetention{KeepLast: 2},
+			},
+		},
+		Status: mmv1beta.MattermostBackupScheduleStatus{
+			LastScheduleTime: &metav1.Time{Time: lastScheduleTime},
+		},
+	}
+}
+
+func newTestBackup(name string, created time.Time, phase mmv1beta.BackupPhase) *mmv1beta.MattermostBackup {
+	return &mmv1beta.MattermostBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: metav1.Time{Time: created},
+			Labels:            map[string]string{mmv1beta.BackupScheduleLabel: scheduleName},
+		},
+		Status: mmv1beta.MattermostBackupStatus{
+			Phase:          phase,
+			CompletionTime: &metav1.Time{Time: created.Add(time.Minute)},
+		},
+	}
+}
+
+func reconcileSchedule(t *testing.T, r *MattermostBackupScheduleReconciler) reconcile.Result {
+	return controllertest.Reconcile(t, r, scheduleName, namespace)
+}
+
+func getSchedule(t *testing.T, r *MattermostBackupScheduleReconciler) *mmv1beta.MattermostBackupSchedule {
+	return controllertest.Get(t, r.Client, scheduleName, namespace, &mmv1beta.MattermostBackupSchedule{})
+}
+
+func listBackups(t *testing.T, r *MattermostBackupScheduleReconciler) []mmv1beta.MattermostBackup {
+	backups := &mmv1beta.MattermostBackupList{}
+	require.NoError(t, r.Client.List(context.TODO(), backups, client.InNamespace(namespace)))
+	return backups.Items
+}
+
+func TestScheduleBackup(t *testing.T) {
+	r := newTestReconciler(t, newTestSchedule("* * * * *", time.Now().Add(-5*time.Minute)))
+
+	res := reconcileSchedule(t, r)
+	assert.True(t, res.RequeueAfter > 0)
+	assert.True(t, res.RequeueAfter <= time.Minute)
+
+	backups := listBackups(t, r)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "foo", backups[0].Spec.MattermostName)
+	assert.Equal(t, scheduleName, backups[0].Labels[mmv1beta.BackupScheduleLabel])
+	require.Len(t, backups[0].OwnerReferences, 1)
+	assert.Equal(t, scheduleName, backups[0].OwnerReferences[0].Name)
+
+	schedule := getSchedule(t, r)
+	assert.Equal(t, backups[0].Name, schedule.Status.LastBackup)
+	assert.Empty(t, schedule.Status.Error)
+	require.NotNil(t, schedule.Status.NextScheduleTime)
+	assert.True(t, schedule.Status.NextScheduleTime.After(time.Now()))
+	// Only the most recent missed schedule is run.
+	assert.True(t, time.Since(schedule.Status.LastScheduleTime.Time) <= time.Minute)
+
+	t.Run("do not schedule twice", func(t *testing.T) {
+		reconcileSchedule(t, r)
+		assert.Len(t, listBackups(t, r), 1)
+	})
+}
+
+func TestScheduleNotDue(t *testing.T) {
+	r := newTestReconciler(t, newTestSchedule("0 0 1 1 *", time.Now().Add(-time.Minute)))
+
+	reconcileSchedule(t, r)
+
+	assert.Empty(t, listBackups(t, r))
+	assert.NotNil(t, getSchedule(t, r).Status.NextScheduleTime)
+}
+
+func TestScheduleSuspended(t *testing.T) {
+	schedule := newTestSchedule("* * * * *", time.Now().Add(-5*time.Minute))
+	schedule.Spec.Suspend = true
+	r := newTestReconciler(t, schedule)
+
+	res := reconcileSchedule(t, r)
+	assert.Equal(t, time.Duration(0), res.RequeueAfter)
+
+	assert.Empty(t, listBackups(t, r))
+	assert.Nil(t, getSchedule(t, r).Status.NextScheduleTime)
+}
+
+func TestScheduleActiveBackup(t *testing.T) {
+	lastScheduleTime := time.Now().Add(-5 * time.Minute)
+	r := newTestReconciler(t,
+		newTestSchedule("* * * * *", lastScheduleTime),
+		newTestBackup("nightly-1", lastScheduleTime, mmv1beta.BackupRunning),
+	)
+
+	res := reconcileSchedule(t, r)
+	assert.Equal(t, requeueActiveBackupDelay, res.RequeueAfter)
+
+	assert.Len(t, listBackups(t, r), 1)
+	assert.Equal(t, lastScheduleTime.Unix(), getSchedule(t, r).Status.LastScheduleTime.Unix())
+}
+
+func TestScheduleRetention(t *testing.T) {
+	now := time.Now()
+	r := newTestReconciler(t,
+		newTestSchedule("0 0 1 1 *", now.Add(-time.Minute)),
+		newTestBackup("nightly-1", now.Add(-6*time.Hour), mmv1beta.BackupSucceeded),
+		newTestBackup("nightly-2", now.Add(-5*time.Hour), mmv1beta.BackupFailed),
+		newTestBackup("nightly-3", now.Add(-4*time.Hour), mmv1beta.BackupSucceeded),
+		newTestBackup("nightly-4", now.Add(-3*time.Hour), mmv1beta.BackupSucceeded),
+		newTestBackup("nightly-5", now.Add(-2*time.Hour), mmv1beta.BackupFailed),
+		newTestBackup("nightly-6", now.Add(-1*time.Hour), mmv1beta.BackupFailed),
+	)
+
+	reconcileSchedule(t, r)
+
+	backups := listBackups(t, r)
+	var names []string
+	for _, backup := range backups {
+		names = append(names, backup.Name)
+	}
+	// Recent failures do not remove the successful backups.
+	assert.ElementsMatch(t, []string{"nightly-3", "nightly-4", "nightly-5", "nightly-6"}, names)
+
+	schedule := getSchedule(t, r)
+	require.NotNil(t, schedule.Status.LastSuccessfulTime)
+	assert.Equal(t, now.Add(-3*time.Hour+time.Minute).Unix(), schedule.Status.LastSuccessfulTime.Unix())
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	r := newTestReconciler(t, newTestSchedule("every night", time.Now().Add(-time.Hour)))
+
+	res := reconcileSchedule(t, r)
+	assert.Equal(t, reconcile.Result{}, res)
+
+	schedule := getSchedule(t, r)
+	assert.NotEmpty(t, schedule.Status.Error)
+	assert.Nil(t, schedule.Status.NextScheduleTime)
+	assert.Empty(t, listBackups(t, r))
+}
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	cron, err := utils.ParseCronSchedule("0 2 * * *")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 5, 10, 2, 0, 0, 0, time.UTC), mostRecentScheduleTime(cron, now.AddDate(0, 0, -3), now))
+	assert.True(t, mostRecentScheduleTime(cron, time.Date(2024, 5, 10, 2, 0, 0, 0, time.UTC), now).IsZero())
+	assert.Equal(t, time.Date(2024, 5, 10, 2, 0, 0, 0, time.UTC), mostRecentScheduleTime(cron, time.Time{}, now))
+}
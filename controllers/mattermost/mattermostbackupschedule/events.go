@@ -0,0 +1,8 @@
+package mattermostbackupschedule
+
+// Reasons of the events emitted on MattermostBackupSchedule resources.
+const (
+	eventReasonBackupCreated   = "BackupCreated"
+	eventReasonBackupDeleted   = "BackupDeleted"
+	eventReasonInvalidSchedule = "InvalidSchedule"
+)
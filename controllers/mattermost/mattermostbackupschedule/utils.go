@@ -0,0 +1,24 @@
+package mattermostbackupschedule
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+)
+
+func (r *MattermostBackupScheduleReconciler) updateStatus(schedule *mmv1beta.MattermostBackupSchedule, status mmv1beta.MattermostBackupScheduleStatus, reqLogger logr.Logger) error {
+	if reflect.DeepEqual(schedule.Status, status) {
+		return nil
+	}
+
+	schedule.Status = status
+	err := r.Client.Status().Update(context.TODO(), schedule)
+	if err != nil {
+		reqLogger.Error(err, "failed to update the mattermostbackupschedule status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package mattermostdbmigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/operation"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	requeueScaleDownDelay = 5 * time.Second
+	requeueMigrationDelay = 10 * time.Second
+)
+
+// MattermostDBMigrationReconciler reconciles a MattermostDBMigration object
+type MattermostDBMigrationReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Resources *resources.ResourceHelper
+}
+
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostdbmigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostdbmigrations/status,verbs=get;update;patch
+
+func (r *MattermostDBMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mmv1beta.MattermostDBMigration{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
+
+func (r *MattermostDBMigrationReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling MattermostDBMigration")
+
+	migration := &mmv1beta.MattermostDBMigration{}
+	err := r.Client.Get(ctx, request.NamespacedName, migration)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if migration.IsFinished() {
+		return reconcile.Result{}, nil
+	}
+
+	mattermost := &mmv1beta.Mattermost{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: migration.Spec.MattermostName, Namespace: migration.Namespace}, mattermost)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			reqLogger.Error(err, "Mattermost installation not found", "Mattermost.Name", migration.Spec.MattermostName)
+			return reconcile.Result{}, r.fail(migration, fmt.Sprintf("Mattermost installation %q not found", migration.Spec.MattermostName), reqLogger)
+		}
+		return reconcile.Result{}, err
+	}
+
+	switch migration.Status.Phase {
+	case "", mmv1beta.MigrationPending:
+		return r.startMigration(migration, mattermost, reqLogger)
+	case mmv1beta.MigrationScalingDown:
+		return r.checkScaledDown(migration, mattermost, reqLogger)
+	case mmv1beta.MigrationMigrating:
+		return r.checkMigrationJob(migration, mattermost, reqLogger)
+	case mmv1beta.MigrationSwitching:
+		return r.checkSwitched(migration, mattermost, reqLogger)
+	}
+
+	return reconcile.Result{}, fmt.Errorf("unknown migration phase %q", migration.Status.Phase)
+}
+
+// fail marks the migration as failed without changing the Mattermost installation.
+func (r *MattermostDBMigrationReconciler) fail(migration *mmv1beta.MattermostDBMigration, message string, reqLogger logr.Logger) error {
+	status := *migration.Status.DeepCopy()
+	status.Phase = mmv1beta.MigrationFailed
+	status.Message = message
+	status.CompletionTime = operation.Now()
+
+	return r.updateStatus(migration, status, reqLogger)
+}
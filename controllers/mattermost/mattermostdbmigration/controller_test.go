@@ -0,0 +1,300 @@
+package mattermostdbmigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/controllertest"
+	"github.com/mattermost/mattermost-operator/pkg/components/dbmigration"
+	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	"github.com/mattermost/mattermost-operator/pkg/components/utils"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	mmName        = controllertest.MattermostName
+	mmNamespace   = controllertest.Namespace
+	migrationName = "migration"
+)
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *MattermostDBMigrationReconciler {
+	deps := controllertest.NewDeps(t, objects...)
+	return &MattermostDBMigrationReconciler{
+		Client:    deps.Client,
+		Log:       deps.Log,
+		Scheme:    deps.Scheme,
+		Recorder:  deps.Recorder,
+		Resources: deps.Resources,
+	}
+}
+
+// newTestMattermost returns Mattermost with Operator-managed MySQL database
+// and dedicated job server.
+func newTestMattermost() *mmv1beta.Mattermost {
+	mattermost := controllertest.NewMattermost()
+	mattermost.Generation = 1
+	mattermost.Spec.Database = mmv1beta.Database{
+		OperatorManaged: &mmv1beta.OperatorManagedDatabase{Type: mmv1beta.DatabaseTypeMySQL},
+	}
+	mattermost.Spec.JobServer = &mmv1beta.JobServer{DedicatedJobServer: true}
+	mattermost.Status = mmv1beta.MattermostStatus{
+		State:              mmv1beta.Stable,
+		Version:            mattermost.Spec.Version,
+		ObservedGeneration: 1,
+	}
+	return mattermost
+}
+
+func newTestMigration() *mmv1beta.MattermostDBMigration {
+	return &mmv1beta.MattermostDBMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migrationName,
+			Namespace: mmNamespace,
+			UID:       types.UID("migration"),
+		},
+		Spec: mmv1beta.MattermostDBMigrationSpec{
+			MattermostName: mmName,
+			Target: mmv1beta.MigrationTarget{
+				OperatorManaged: &mmv1beta.OperatorManagedDatabase{Type: mmv1beta.DatabaseTypePostgres},
+			},
+		},
+	}
+}
+
+func newTestMySQLSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mattermostmysql.DefaultDatabaseSecretName(mmName),
+			Namespace: mmNamespace,
+		},
+		Data: map[string][]byte{
+			"USER":     []byte("mmuser"),
+			"PASSWORD": []byte("pass"),
+			"DATABASE": []byte("mattermost"),
+		},
+	}
+}
+
+func reconcileMigration(t *testing.T, r *MattermostDBMigrationReconciler) reconcile.Result {
+	return controllertest.Reconcile(t, r, migrationName, mmNamespace)
+}
+
+func getMigration(t *testing.T, r *MattermostDBMigrationReconciler) *mmv1beta.MattermostDBMigration {
+	return controllertest.Get(t, r.Client, migrationName, mmNamespace, &mmv1beta.MattermostDBMigration{})
+}
+
+func getMattermost(t *testing.T, r *MattermostDBMigrationReconciler) *mmv1beta.Mattermost {
+	return controllertest.Get(t, r.Client, mmName, mmNamespace, &mmv1beta.Mattermost{})
+}
+
+// migrateUntilJobCreated drives the migration to the Migrating phase.
+func migrateUntilJobCreated(t *testing.T, r *MattermostDBMigrationReconciler) {
+	reconcileMigration(t, r)
+	require.Equal(t, mmv1beta.MigrationScalingDown, getMigration(t, r).Status.Phase)
+
+	// The cluster is created and waited for.
+	res := reconcileMigration(t, r)
+	assert.Equal(t, requeueMigrationDelay, res.RequeueAfter)
+	cluster := &cnpgv1.Cluster{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: utils.HashWithPrefix("db", mmName), Namespace: mmNamespace}, cluster)
+	require.NoError(t, err)
+	cluster.Status.ReadyInstances = 1
+	require.NoError(t, r.Client.Status().Update(context.TODO(), cluster))
+
+	reconcileMigration(t, r)
+	require.Equal(t, mmv1beta.MigrationMigrating, getMigration(t, r).Status.Phase)
+}
+
+// setMattermostState simulates the Mattermost controller reporting the state.
+func setMattermostState(t *testing.T, r *MattermostDBMigrationReconciler, state mmv1beta.RunningState) {
+	mattermost := getMattermost(t, r)
+	mattermost.Status.State = state
+	require.NoError(t, r.Client.Status().Update(context.TODO(), mattermost))
+}
+
+func setJobStatus(t *testing.T, r *MattermostDBMigrationReconciler, status batchv1.JobStatus) {
+	controllertest.SetJobStatus(t, r.Client, dbmigration.ResourceName(getMigration(t, r)), mmNamespace, status)
+}
+
+func TestMigration(t *testing.T) {
+	r := newTestReconciler(t, newTestMattermost(), newTestMigration(), newTestMySQLSecret())
+
+	t.Run("scale down", func(t *testing.T) {
+		reconcileMigration(t, r)
+
+		migration := getMigration(t, r)
+		assert.Equal(t, mmv1beta.MigrationScalingDown, migration.Status.Phase)
+		assert.Equal(t, int32(3), *migration.Status.OriginalReplicas)
+		assert.True(t, migration.Status.OriginalDedicatedJobServer)
+		assert.Equal(t, "10.8.1", migration.Status.MattermostVersion)
+
+		mattermost := getMattermost(t, r)
+		assert.Equal(t, int32(0), *mattermost.Spec.Replicas)
+		assert.False(t, mattermost.Spec.JobServer.DedicatedJobServer)
+	})
+
+	t.Run("wait for deployment", func(t *testing.T) {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: mmName, Namespace: mmNamespace},
+			Status:     appsv1.DeploymentStatus{Replicas: 3},
+		}
+		require.NoError(t, r.Client.Create(context.TODO(), deployment))
+
+		res := reconcileMigration(t, r)
+		assert.Equal(t, requeueScaleDownDelay, res.RequeueAfter)
+
+		require.NoError(t, r.Client.Delete(context.TODO(), deployment))
+	})
+
+	t.Run("launch job", func(t *testing.T) {
+		res := reconcileMigration(t, r)
+		assert.Equal(t, requeueMigrationDelay, res.RequeueAfter)
+
+		cluster := &cnpgv1.Cluster{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: utils.HashWithPrefix("db", mmName), Namespace: mmNamespace}, cluster)
+		require.NoError(t, err)
+		cluster.Status.ReadyInstances = 1
+		require.NoError(t, r.Client.Status().Update(context.TODO(), cluster))
+
+		reconcileMigration(t, r)
+		migration := getMigration(t, r)
+		assert.Equal(t, mmv1beta.MigrationMigrating, migration.Status.Phase)
+
+		job := &batchv1.Job{}
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: dbmigration.ResourceName(migration), Namespace: mmNamespace}, job)
+		require.NoError(t, err)
+		initContainers := job.Spec.Template.Spec.InitContainers
+		require.Len(t, initContainers, 3)
+		assert.Equal(t, "mattermost/mattermost-enterprise-edition:10.8.1", initContainers[0].Image)
+		assert.Equal(t, []string{"db", "migrate"}, initContainers[0].Args)
+		assert.Equal(t, mmv1beta.DefaultPgloaderImage, initContainers[1].Image)
+		assert.Equal(t, mmv1beta.DefaultMigrationMySQLClientImage, initContainers[2].Image)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, mmv1beta.DefaultMigrationPostgresClientImage, job.Spec.Template.Spec.Containers[0].Image)
+
+		configMap := &corev1.ConfigMap{}
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: dbmigration.ResourceName(migration), Namespace: mmNamespace}, configMap)
+		require.NoError(t, err)
+	})
+
+	t.Run("switch database", func(t *testing.T) {
+		setJobStatus(t, r, batchv1.JobStatus{Succeeded: 1})
+		reconcileMigration(t, r)
+
+		assert.Equal(t, mmv1beta.MigrationSwitching, getMigration(t, r).Status.Phase)
+
+		mattermost := getMattermost(t, r)
+		assert.Equal(t, int32(3), *mattermost.Spec.Replicas)
+		assert.True(t, mattermost.Spec.JobServer.DedicatedJobServer)
+		require.NotNil(t, mattermost.Spec.Database.OperatorManaged)
+		assert.Equal(t, mmv1beta.DatabaseTypePostgres, mattermost.Spec.Database.OperatorManaged.Type)
+	})
+
+	t.Run("wait for stable", func(t *testing.T) {
+		setMattermostState(t, r, mmv1beta.Reconciling)
+		res := reconcileMigration(t, r)
+		assert.Equal(t, requeueMigrationDelay, res.RequeueAfter)
+		assert.Equal(t, mmv1beta.MigrationSwitching, getMigration(t, r).Status.Phase)
+
+		setMattermostState(t, r, mmv1beta.Stable)
+
+		reconcileMigration(t, r)
+		migration := getMigration(t, r)
+		assert.Equal(t, mmv1beta.MigrationSucceeded, migration.Status.Phase)
+		assert.NotNil(t, migration.Status.CompletionTime)
+	})
+}
+
+func TestMigrationRollback(t *testing.T) {
+	t.Run("job failed", func(t *testing.T) {
+		r := newTestReconciler(t, newTestMattermost(), newTestMigration(), newTestMySQLSecret())
+		migrateUntilJobCreated(t, r)
+
+		setJobStatus(t, r, batchv1.JobStatus{Failed: 1})
+		reconcileMigration(t, r)
+
+		assert.Equal(t, mmv1beta.MigrationRolledBack, getMigration(t, r).Status.Phase)
+		mattermost := getMattermost(t, r)
+		assert.Equal(t, int32(3), *mattermost.Spec.Replicas)
+		assert.True(t, mattermost.Spec.JobServer.DedicatedJobServer)
+		assert.Equal(t, mmv1beta.DatabaseTypeMySQL, mattermost.Spec.Database.OperatorManaged.Type)
+	})
+
+	t.Run("progress deadline exceeded", func(t *testing.T) {
+		r := newTestReconciler(t, newTestMattermost(), newTestMigration(), newTestMySQLSecret())
+		migrateUntilJobCreated(t, r)
+
+		setJobStatus(t, r, batchv1.JobStatus{Succeeded: 1})
+		reconcileMigration(t, r)
+		assert.Equal(t, mmv1beta.DatabaseTypePostgres, getMattermost(t, r).Spec.Database.OperatorManaged.Type)
+		setMattermostState(t, r, mmv1beta.Reconciling)
+
+		migration := getMigration(t, r)
+		migration.Status.SwitchTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		require.NoError(t, r.Client.Status().Update(context.TODO(), migration))
+
+		reconcileMigration(t, r)
+		assert.Equal(t, mmv1beta.MigrationRolledBack, getMigration(t, r).Status.Phase)
+		assert.Equal(t, mmv1beta.DatabaseTypeMySQL, getMattermost(t, r).Spec.Database.OperatorManaged.Type)
+	})
+}
+
+func TestMigrationValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		mutate      func(mm *mmv1beta.Mattermost, migration *mmv1beta.MattermostDBMigration)
+	}{
+		{
+			description: "external source database",
+			mutate: func(mm *mmv1beta.Mattermost, _ *mmv1beta.MattermostDBMigration) {
+				mm.Spec.Database = mmv1beta.Database{External: &mmv1beta.ExternalDatabase{Secret: "db"}}
+			},
+		},
+		{
+			description: "no target",
+			mutate: func(_ *mmv1beta.Mattermost, migration *mmv1beta.MattermostDBMigration) {
+				migration.Spec.Target = mmv1beta.MigrationTarget{}
+			},
+		},
+		{
+			description: "unsupported target type",
+			mutate: func(_ *mmv1beta.Mattermost, migration *mmv1beta.MattermostDBMigration) {
+				migration.Spec.Target.OperatorManaged.Type = mmv1beta.DatabaseTypeMySQL
+			},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			mm := newTestMattermost()
+			migration := newTestMigration()
+			testCase.mutate(mm, migration)
+
+			r := newTestReconciler(t, mm, migration, newTestMySQLSecret())
+			reconcileMigration(t, r)
+
+			assert.Equal(t, mmv1beta.MigrationFailed, getMigration(t, r).Status.Phase)
+			assert.Equal(t, int32(3), *getMattermost(t, r).Spec.Replicas)
+		})
+	}
+
+	t.Run("wait for stable Mattermost", func(t *testing.T) {
+		mm := newTestMattermost()
+		mm.Status.Version = "10.7.0"
+
+		r := newTestReconciler(t, mm, newTestMigration(), newTestMySQLSecret())
+		res := reconcileMigration(t, r)
+
+		assert.Equal(t, requeueMigrationDelay, res.RequeueAfter)
+		assert.Equal(t, mmv1beta.MigrationPending, getMigration(t, r).Status.Phase)
+	})
+}
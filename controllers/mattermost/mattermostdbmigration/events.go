@@ -0,0 +1,7 @@
+package mattermostdbmigration
+
+// Reasons of the events emitted on MattermostDBMigration resources.
+const (
+	eventReasonPhaseChanged      = "PhaseChanged"
+	eventReasonMattermostUpdated = "MattermostUpdated"
+)
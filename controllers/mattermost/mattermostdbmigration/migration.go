@@ -0,0 +1,266 @@
+package mattermostdbmigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/operation"
+	"github.com/mattermost/mattermost-operator/pkg/components/dbmigration"
+	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	mattermostpostgres "github.com/mattermost/mattermost-operator/pkg/components/postgres"
+	"github.com/mattermost/mattermost-operator/pkg/database"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// startMigration validates the migration, records the original state of
+// Mattermost and scales it down.
+func (r *MattermostDBMigrationReconciler) startMigration(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if err := validateMigration(migration, mattermost); err != nil {
+		return reconcile.Result{}, r.fail(migration, err.Error(), reqLogger)
+	}
+
+	// The schema is created by the same Mattermost version, therefore
+	// Mattermost must not be in the middle of an upgrade.
+	if mattermost.Status.State != mmv1beta.Stable || mattermost.Status.Version != mattermost.Spec.Version {
+		status := *migration.Status.DeepCopy()
+		status.Phase = mmv1beta.MigrationPending
+		status.Message = "Waiting for Mattermost to be stable"
+		err := r.updateStatus(migration, status, reqLogger)
+		return reconcile.Result{RequeueAfter: requeueMigrationDelay}, err
+	}
+
+	status := *migration.Status.DeepCopy()
+	status.Phase = mmv1beta.MigrationScalingDown
+	status.Message = "Scaling down Mattermost"
+	status.MattermostVersion = mattermost.Spec.Version
+	status.OriginalDatabase = mattermost.Spec.Database.DeepCopy()
+	status.OriginalReplicas = mattermost.Spec.Replicas
+	status.OriginalDedicatedJobServer = mattermost.Spec.JobServer != nil && mattermost.Spec.JobServer.DedicatedJobServer
+
+	// Original values are persisted before Mattermost is modified, so that
+	// it can always be restored.
+	if err := r.updateStatus(migration, status, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{Requeue: true}, r.scaleDown(migration, mattermost, reqLogger)
+}
+
+func validateMigration(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost) error {
+	source := mattermost.Spec.Database.DeepCopy()
+	source.SetDefaults()
+	if source.IsExternal() || source.OperatorManaged.Type != mmv1beta.DatabaseTypeMySQL {
+		return errors.New("only Mattermost installations using Operator-managed MySQL database can be migrated")
+	}
+
+	target := migration.Spec.Target
+	if (target.External == nil) == (target.OperatorManaged == nil) {
+		return errors.New("exactly one of target.external and target.operatorManaged has to be set")
+	}
+	if target.External != nil && target.External.Secret == "" {
+		return errors.New("target.external.secret has to be set")
+	}
+	if target.OperatorManaged != nil && target.OperatorManaged.Type != "" && target.OperatorManaged.Type != mmv1beta.DatabaseTypePostgres {
+		return fmt.Errorf("target database of type %q is not supported", target.OperatorManaged.Type)
+	}
+
+	return nil
+}
+
+func (r *MattermostDBMigrationReconciler) scaleDown(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	if !operation.ScaleDown(mattermost) {
+		return nil
+	}
+
+	reqLogger.Info("Scaling down Mattermost", "Mattermost.Name", mattermost.Name)
+	return r.updateMattermost(migration, mattermost, "Scaled down Mattermost for the database migration")
+}
+
+// checkScaledDown waits for all Mattermost pods to stop, prepares the target
+// database and launches the migration job.
+func (r *MattermostDBMigrationReconciler) checkScaledDown(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if err := r.scaleDown(migration, mattermost, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	scaledDown, err := operation.IsScaledDown(context.TODO(), r.Client, mattermost, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !scaledDown {
+		return reconcile.Result{RequeueAfter: requeueScaleDownDelay}, nil
+	}
+
+	targetMattermost := mattermost.DeepCopy()
+	targetMattermost.Spec.Database = migration.TargetDatabase(*migration.Status.OriginalDatabase)
+
+	targetDB, ready, err := r.prepareTargetDatabase(targetMattermost, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, r.rollback(migration, mattermost, fmt.Sprintf("Failed to prepare target database: %s", err), reqLogger)
+	}
+	if !ready {
+		reqLogger.Info("Waiting for target database to be ready")
+		return reconcile.Result{RequeueAfter: requeueMigrationDelay}, nil
+	}
+
+	sourceSecretName := mattermostmysql.DefaultDatabaseSecretName(mattermost.Name)
+	sourceSecret := &corev1.Secret{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: sourceSecretName, Namespace: mattermost.Namespace}, sourceSecret)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to get MySQL database secret")
+	}
+	sourceEnv := dbmigration.SourceEnvVars(mattermost, sourceSecretName, string(sourceSecret.Data["DATABASE"]))
+
+	err = r.Resources.Create(migration, dbmigration.ConfigMap(migration), reqLogger)
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return reconcile.Result{}, errors.Wrap(err, "failed to create migration config map")
+	}
+	err = r.Resources.Create(migration, dbmigration.Job(migration, targetMattermost, sourceEnv, targetDB), reqLogger)
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return reconcile.Result{}, errors.Wrap(err, "failed to create migration job")
+	}
+
+	status := *migration.Status.DeepCopy()
+	status.Phase = mmv1beta.MigrationMigrating
+	status.Message = "Migration job is running"
+	status.StartTime = operation.Now()
+
+	return reconcile.Result{RequeueAfter: requeueMigrationDelay}, r.updateStatus(migration, status, reqLogger)
+}
+
+// prepareTargetDatabase returns the configuration of the target database and
+// whether the database is ready to be migrated to.
+func (r *MattermostDBMigrationReconciler) prepareTargetDatabase(targetMattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (mattermostApp.DatabaseConfig, bool, error) {
+	if targetMattermost.Spec.Database.IsExternal() {
+		secret := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: targetMattermost.Spec.Database.External.Secret, Namespace: targetMattermost.Namespace}, secret)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "failed to get target database secret")
+		}
+		if dbType := database.GetTypeFromConnectionString(string(secret.Data["DB_CONNECTION_STRING"])); dbType != database.PostgreSQLDatabase {
+			return nil, false, errors.New("target database connection string has to use the postgres scheme")
+		}
+
+		dbConfig, err := mattermostApp.NewExternalDBConfig(targetMattermost, *secret)
+		return dbConfig, true, err
+	}
+
+	// The cluster and its secret are owned by Mattermost as they are
+	// reconciled by the Mattermost controller once the migration completes.
+	secret, err := r.Resources.GetOrCreatePostgresSecrets(targetMattermost, mattermostpostgres.DefaultDatabaseSecretName(targetMattermost.Name), reqLogger)
+	if err != nil {
+		return nil, false, err
+	}
+
+	desired := mattermostpostgres.ClusterV1Beta(targetMattermost)
+	err = r.Resources.CreatePostgresClusterIfNotExists(targetMattermost, desired, reqLogger)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cluster := &cnpgv1.Cluster{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, cluster)
+	if err != nil {
+		return nil, false, err
+	}
+
+	dbConfig, err := mattermostApp.NewPostgresDBConfig(*secret)
+	return dbConfig, cluster.Status.ReadyInstances > 0, err
+}
+
+// checkMigrationJob switches Mattermost to the target database once the
+// migration job succeeds, or restores it when the job fails.
+func (r *MattermostDBMigrationReconciler) checkMigrationJob(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	job := &batchv1.Job{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: dbmigration.ResourceName(migration), Namespace: migration.Namespace}, job)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return reconcile.Result{}, r.rollback(migration, mattermost, "Migration job not found", reqLogger)
+		}
+		return reconcile.Result{}, errors.Wrap(err, "failed to get migration job")
+	}
+
+	if job.Status.Failed > 0 {
+		reqLogger.Info("Migration job failed", "Job.Name", job.Name)
+		return reconcile.Result{}, r.rollback(migration, mattermost, "Migration job failed, check its logs for details", reqLogger)
+	}
+	if job.Status.Succeeded == 0 {
+		return reconcile.Result{RequeueAfter: requeueMigrationDelay}, nil
+	}
+
+	reqLogger.Info("Migration job succeeded, switching Mattermost to the target database")
+	mattermost.Spec.Database = migration.TargetDatabase(*migration.Status.OriginalDatabase)
+	operation.SetReplicas(mattermost, migration.Status.OriginalReplicas, migration.Status.OriginalDedicatedJobServer)
+	err = r.updateMattermost(migration, mattermost, "Switched Mattermost to the migrated PostgreSQL database")
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status := *migration.Status.DeepCopy()
+	status.Phase = mmv1beta.MigrationSwitching
+	status.Message = "Waiting for Mattermost to become stable with the target database"
+	status.SwitchTime = operation.Now()
+
+	return reconcile.Result{RequeueAfter: requeueMigrationDelay}, r.updateStatus(migration, status, reqLogger)
+}
+
+// checkSwitched waits for Mattermost to become stable with the target
+// database and restores the original database after the progress deadline.
+func (r *MattermostDBMigrationReconciler) checkSwitched(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if mattermost.Status.ObservedGeneration >= mattermost.Generation && mattermost.Status.State == mmv1beta.Stable {
+		status := *migration.Status.DeepCopy()
+		status.Phase = mmv1beta.MigrationSucceeded
+		status.Message = "Mattermost is running with the target database. The MySQL cluster is kept and can be removed once the migration is verified"
+		status.CompletionTime = operation.Now()
+
+		return reconcile.Result{}, r.updateStatus(migration, status, reqLogger)
+	}
+
+	if migration.Status.SwitchTime != nil && time.Since(migration.Status.SwitchTime.Time) > migration.GetProgressDeadline() {
+		reqLogger.Info("Mattermost did not become stable with the target database")
+		return reconcile.Result{}, r.rollback(migration, mattermost, "Mattermost did not become stable with the target database before the progress deadline", reqLogger)
+	}
+
+	return reconcile.Result{RequeueAfter: requeueMigrationDelay}, nil
+}
+
+// rollback restores the original database and replicas of Mattermost.
+func (r *MattermostDBMigrationReconciler) rollback(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, message string, reqLogger logr.Logger) error {
+	reqLogger.Info("Rolling back Mattermost to the original database", "reason", message)
+
+	if migration.Status.OriginalDatabase != nil {
+		mattermost.Spec.Database = *migration.Status.OriginalDatabase.DeepCopy()
+	}
+	operation.SetReplicas(mattermost, migration.Status.OriginalReplicas, migration.Status.OriginalDedicatedJobServer)
+	err := r.updateMattermost(migration, mattermost, "Restored the original Mattermost database")
+	if err != nil {
+		return err
+	}
+
+	status := *migration.Status.DeepCopy()
+	status.Phase = mmv1beta.MigrationRolledBack
+	status.Message = message
+	status.CompletionTime = operation.Now()
+
+	return r.updateStatus(migration, status, reqLogger)
+}
+
+func (r *MattermostDBMigrationReconciler) updateMattermost(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, message string) error {
+	err := r.Client.Update(context.TODO(), mattermost)
+	if err != nil {
+		return errors.Wrap(err, "failed to update Mattermost")
+	}
+	r.Recorder.Event(migration, corev1.EventTypeNormal, eventReasonMattermostUpdated, message)
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package mattermostdbmigration
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (r *MattermostDBMigrationReconciler) updateStatus(migration *mmv1beta.MattermostDBMigration, status mmv1beta.MattermostDBMigrationStatus, reqLogger logr.Logger) error {
+	if reflect.DeepEqual(migration.Status, status) {
+		return nil
+	}
+
+	if migration.Status.Phase != status.Phase {
+		eventType := corev1.EventTypeNormal
+		if status.Phase == mmv1beta.MigrationFailed || status.Phase == mmv1beta.MigrationRolledBack {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Eventf(migration, eventType, eventReasonPhaseChanged, "Migration phase changed from '%s' to '%s': %s", migration.Status.Phase, status.Phase, status.Message)
+	}
+
+	migration.Status = status
+	err := r.Client.Status().Update(context.TODO(), migration)
+	if err != nil {
+		reqLogger.Error(err, "failed to update the mattermostdbmigration status")
+		return err
+	}
+
+	return nil
+}
@@ -11,22 +11,27 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	mattermostv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
 	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
 
 	errrors "github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 )
 
 // MattermostRestoreDBReconciler reconciles a MattermostRestoreDB object
 type MattermostRestoreDBReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Resources *resources.ResourceHelper
 
 	state mattermostv1alpha1.RestoreState
 }
@@ -37,6 +42,7 @@ type MattermostRestoreDBReconciler struct {
 func (r *MattermostRestoreDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&mattermostv1alpha1.MattermostRestoreDB{}).
+		Owns(&batchv1.Job{}).
 		Complete(r)
 }
 
@@ -70,6 +76,10 @@ func (r *MattermostRestoreDBReconciler) Reconcile(ctx context.Context, request c
 		return reconcile.Result{}, err
 	}
 
+	if restoreMM.Spec.MattermostName != "" {
+		return r.reconcileMattermost(restoreMM, reqLogger)
+	}
+
 	// Check if this Mattermost ClusterInstallation exists
 	clusterInstallation := &mattermostv1alpha1.ClusterInstallation{}
 	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: restoreMM.Spec.MattermostClusterName, Namespace: restoreMM.Namespace}, clusterInstallation)
@@ -118,7 +128,7 @@ func (r *MattermostRestoreDBReconciler) Reconcile(ctx context.Context, request c
 
 	// Update the mysql secret to use the existing users
 	if restoreMM.Spec.MattermostDBName != "" || restoreMM.Spec.MattermostDBUser != "" || restoreMM.Spec.MattermostDBPassword != "" {
-		err = r.updateMySQLSecrets(restoreMM, reqLogger)
+		err = r.updateMySQLSecrets(restoreMM, restoreMM.Spec.MattermostClusterName, reqLogger)
 		if err != nil {
 			reqLogger.Error(err, "failed to update the mysql secret")
 			return reconcile.Result{}, err
@@ -0,0 +1,8 @@
+package mattermostrestoredb
+
+// Reasons of the events emitted on MattermostRestoreDB resources.
+const (
+	eventReasonStateChanged      = "StateChanged"
+	eventReasonPhaseChanged      = "PhaseChanged"
+	eventReasonMattermostUpdated = "MattermostUpdated"
+)
@@ -0,0 +1,309 @@
+package mattermostrestoredb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mattermostv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/operation"
+	"github.com/mattermost/mattermost-operator/pkg/components/restoredb"
+	componentUtils "github.com/mattermost/mattermost-operator/pkg/components/utils"
+	"github.com/mattermost/mattermost-operator/pkg/database"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	requeueScaleDownDelay = 5 * time.Second
+	requeueRestoreDelay   = 10 * time.Second
+)
+
+// reconcileMattermost restores the database of a v1beta1 Mattermost
+// installation. Mattermost and the dedicated job server are scaled down for
+// the duration of the restore.
+func (r *MattermostRestoreDBReconciler) reconcileMattermost(restoreMM *mattermostv1alpha1.MattermostRestoreDB, reqLogger logr.Logger) (reconcile.Result, error) {
+	if restoreMM.Status.Phase == mattermostv1alpha1.RestoreFinished || restoreMM.Status.Phase == mattermostv1alpha1.RestoreFailed {
+		return reconcile.Result{}, nil
+	}
+
+	mattermost := &mmv1beta.Mattermost{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: restoreMM.Spec.MattermostName, Namespace: restoreMM.Namespace}, mattermost)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			reqLogger.Error(err, "Mattermost installation not found", "Mattermost.Name", restoreMM.Spec.MattermostName)
+			return reconcile.Result{}, r.fail(restoreMM, fmt.Sprintf("Mattermost installation %q not found", restoreMM.Spec.MattermostName), reqLogger)
+		}
+		return reconcile.Result{}, err
+	}
+
+	switch restoreMM.Status.Phase {
+	case "":
+		return r.startRestore(restoreMM, mattermost, reqLogger)
+	case mattermostv1alpha1.RestoreScalingDown:
+		return r.checkScaledDown(restoreMM, mattermost, reqLogger)
+	case mattermostv1alpha1.RestoreRestoring:
+		if mattermost.Spec.Database.IsExternal() {
+			return r.checkRestoreJob(restoreMM, mattermost, reqLogger)
+		}
+		return r.checkMySQLRestored(restoreMM, mattermost, reqLogger)
+	}
+
+	return reconcile.Result{}, fmt.Errorf("unknown restore phase %q", restoreMM.Status.Phase)
+}
+
+// startRestore validates the restore, records the original state of
+// Mattermost and scales it down.
+func (r *MattermostRestoreDBReconciler) startRestore(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if err := validateRestore(restoreMM, mattermost); err != nil {
+		return reconcile.Result{}, r.fail(restoreMM, err.Error(), reqLogger)
+	}
+
+	status := *restoreMM.Status.DeepCopy()
+	status.State = mattermostv1alpha1.Restoring
+	status.Phase = mattermostv1alpha1.RestoreScalingDown
+	status.Message = "Scaling down Mattermost"
+	status.OriginalReplicas = mattermost.Spec.Replicas
+	status.OriginalDedicatedJobServer = mattermost.Spec.JobServer != nil && mattermost.Spec.JobServer.DedicatedJobServer
+	status.StartTime = operation.Now()
+	if !mattermost.Spec.Database.IsExternal() {
+		dbSpec := mattermost.Spec.Database.DeepCopy()
+		dbSpec.SetDefaults()
+		status.OriginalDBReplicas = *dbSpec.OperatorManaged.Replicas
+	}
+
+	// Original values are persisted before Mattermost is modified, so that
+	// it can always be restored.
+	if err := r.updateStatus(restoreMM, status, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{Requeue: true}, r.scaleDown(restoreMM, mattermost, reqLogger)
+}
+
+func validateRestore(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost) error {
+	if restoreMM.Spec.InitBucketURL == "" {
+		return errors.New("initBucketURL has to be set")
+	}
+	if restoreMM.Spec.RestoreSecret == "" {
+		return errors.New("restoreSecret has to be set")
+	}
+
+	dbSpec := mattermost.Spec.Database.DeepCopy()
+	dbSpec.SetDefaults()
+	if !dbSpec.IsExternal() && dbSpec.OperatorManaged.Type != mmv1beta.DatabaseTypeMySQL {
+		return fmt.Errorf("restoring Operator-managed database of type %q is not supported", dbSpec.OperatorManaged.Type)
+	}
+
+	return nil
+}
+
+func (r *MattermostRestoreDBReconciler) scaleDown(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) error {
+	if !operation.ScaleDown(mattermost) {
+		return nil
+	}
+
+	reqLogger.Info("Scaling down Mattermost", "Mattermost.Name", mattermost.Name)
+	return r.updateMattermost(restoreMM, mattermost, "Scaled down Mattermost for the database restore")
+}
+
+// checkScaledDown waits for all Mattermost pods to stop and starts the
+// restore of the database.
+func (r *MattermostRestoreDBReconciler) checkScaledDown(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if err := r.scaleDown(restoreMM, mattermost, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	scaledDown, err := operation.IsScaledDown(context.TODO(), r.Client, mattermost, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !scaledDown {
+		return reconcile.Result{RequeueAfter: requeueScaleDownDelay}, nil
+	}
+
+	if mattermost.Spec.Database.IsExternal() {
+		return r.startRestoreJob(restoreMM, mattermost, reqLogger)
+	}
+
+	return r.startMySQLRestore(restoreMM, mattermost, reqLogger)
+}
+
+// startRestoreJob launches the job restoring the external database.
+func (r *MattermostRestoreDBReconciler) startRestoreJob(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	secret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: mattermost.Spec.Database.External.Secret, Namespace: mattermost.Namespace}, secret)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return reconcile.Result{}, r.complete(restoreMM, mattermost, mattermostv1alpha1.RestoreFailed, "External database secret not found", reqLogger)
+		}
+		return reconcile.Result{}, errors.Wrap(err, "failed to get external database secret")
+	}
+
+	dbType := database.GetTypeFromConnectionString(string(secret.Data["DB_CONNECTION_STRING"]))
+	if dbType != database.MySQLDatabase && dbType != database.PostgreSQLDatabase {
+		return reconcile.Result{}, r.complete(restoreMM, mattermost, mattermostv1alpha1.RestoreFailed, "Unable to determine the type of the external database from the connection string", reqLogger)
+	}
+
+	err = r.Resources.Create(restoreMM, restoredb.Job(restoreMM, mattermost, dbType), reqLogger)
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return reconcile.Result{}, errors.Wrap(err, "failed to create restore job")
+	}
+
+	status := *restoreMM.Status.DeepCopy()
+	status.Phase = mattermostv1alpha1.RestoreRestoring
+	status.Message = "Restore job is running"
+
+	return reconcile.Result{RequeueAfter: requeueRestoreDelay}, r.updateStatus(restoreMM, status, reqLogger)
+}
+
+// checkRestoreJob scales Mattermost up once the restore job completes.
+func (r *MattermostRestoreDBReconciler) checkRestoreJob(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	job := &batchv1.Job{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: restoredb.JobName(restoreMM), Namespace: restoreMM.Namespace}, job)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return reconcile.Result{}, r.complete(restoreMM, mattermost, mattermostv1alpha1.RestoreFailed, "Restore job not found", reqLogger)
+		}
+		return reconcile.Result{}, errors.Wrap(err, "failed to get restore job")
+	}
+
+	if job.Status.Failed > 0 {
+		reqLogger.Info("Restore job failed", "Job.Name", job.Name)
+		return reconcile.Result{}, r.complete(restoreMM, mattermost, mattermostv1alpha1.RestoreFailed, "Restore job failed, check its logs for details", reqLogger)
+	}
+	if job.Status.Succeeded == 0 {
+		return reconcile.Result{RequeueAfter: requeueRestoreDelay}, nil
+	}
+
+	reqLogger.Info("Restore job succeeded", "Job.Name", job.Name)
+	return reconcile.Result{}, r.complete(restoreMM, mattermost, mattermostv1alpha1.RestoreFinished, "Database restored", reqLogger)
+}
+
+// startMySQLRestore scales the Operator-managed MySQL cluster down and
+// configures it to be initialized from the backup.
+func (r *MattermostRestoreDBReconciler) startMySQLRestore(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	if restoreMM.Spec.MattermostDBName != "" || restoreMM.Spec.MattermostDBUser != "" || restoreMM.Spec.MattermostDBPassword != "" {
+		err := r.updateMySQLSecrets(restoreMM, mattermost.Name, reqLogger)
+		if err != nil {
+			reqLogger.Error(err, "failed to update the mysql secret")
+			return reconcile.Result{}, err
+		}
+	}
+
+	replicas := int32(0)
+	mattermost.Spec.Database.OperatorManaged.Replicas = &replicas
+	mattermost.Spec.Database.OperatorManaged.InitBucketURL = restoreMM.Spec.InitBucketURL
+	mattermost.Spec.Database.OperatorManaged.BackupRestoreSecretName = restoreMM.Spec.RestoreSecret
+	err := r.updateMattermost(restoreMM, mattermost, "Scaled down MySQL cluster for the database restore")
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status := *restoreMM.Status.DeepCopy()
+	status.Phase = mattermostv1alpha1.RestoreRestoring
+	status.Message = "Waiting for the MySQL cluster to scale down"
+
+	return reconcile.Result{RequeueAfter: requeueScaleDownDelay}, r.updateStatus(restoreMM, status, reqLogger)
+}
+
+// checkMySQLRestored removes the MySQL volumes once the cluster is scaled
+// down and scales it up, so that it is initialized from the backup.
+func (r *MattermostRestoreDBReconciler) checkMySQLRestored(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (reconcile.Result, error) {
+	mySQLName := componentUtils.HashWithPrefix("db", mattermost.Name)
+	statefulset := &appsv1.StatefulSet{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: fmt.Sprintf("%s-mysql", mySQLName), Namespace: restoreMM.Namespace}, statefulset)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return reconcile.Result{}, errors.Wrap(err, "unable to get the statefulset")
+	}
+	if err == nil && statefulset.Status.ReadyReplicas != 0 {
+		reqLogger.Info("Waiting for MySQL Statefulset to scale down")
+		return reconcile.Result{RequeueAfter: requeueScaleDownDelay}, nil
+	}
+
+	pods := &corev1.PodList{}
+	err = r.Client.List(context.TODO(), pods, client.InNamespace(mattermost.Namespace), client.MatchingLabels(mattermostv1alpha1.MySQLLabels()))
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "unable to get pod list")
+	}
+	if len(pods.Items) != 0 {
+		reqLogger.Info("Waiting for MySQL pods to terminate", "Number of Pods", len(pods.Items))
+		return reconcile.Result{RequeueAfter: requeueScaleDownDelay}, nil
+	}
+
+	for i := 0; i < int(restoreMM.Status.OriginalDBReplicas); i++ {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("data-%s-mysql-%d", mySQLName, i),
+				Namespace: restoreMM.Namespace,
+			},
+		}
+		reqLogger.Info("Deleting PVC...", "PersistentVolumeClaimName", pvc.Name)
+		err = r.Client.Delete(context.TODO(), pvc, client.GracePeriodSeconds(0))
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return reconcile.Result{}, errors.Wrap(err, "failed to delete the DB PVC")
+		}
+	}
+
+	dbReplicas := restoreMM.Status.OriginalDBReplicas
+	if dbReplicas == 0 {
+		// at least set to one replica
+		dbReplicas = 1
+	}
+	mattermost.Spec.Database.OperatorManaged.Replicas = &dbReplicas
+
+	return reconcile.Result{}, r.complete(restoreMM, mattermost, mattermostv1alpha1.RestoreFinished, "Database restored", reqLogger)
+}
+
+// complete restores the original replicas of Mattermost and ends the restore
+// in the given phase.
+func (r *MattermostRestoreDBReconciler) complete(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, phase mattermostv1alpha1.RestorePhase, message string, reqLogger logr.Logger) error {
+	reqLogger.Info("Scaling up Mattermost", "Phase", phase, "Message", message)
+
+	operation.SetReplicas(mattermost, restoreMM.Status.OriginalReplicas, restoreMM.Status.OriginalDedicatedJobServer)
+	err := r.updateMattermost(restoreMM, mattermost, "Scaled up Mattermost after the database restore")
+	if err != nil {
+		return err
+	}
+
+	status := *restoreMM.Status.DeepCopy()
+	status.State = mattermostv1alpha1.Finished
+	if phase == mattermostv1alpha1.RestoreFailed {
+		status.State = mattermostv1alpha1.Failed
+	}
+	status.Phase = phase
+	status.Message = message
+	status.CompletionTime = operation.Now()
+
+	return r.updateStatus(restoreMM, status, reqLogger)
+}
+
+// fail marks the restore as failed without changing the Mattermost installation.
+func (r *MattermostRestoreDBReconciler) fail(restoreMM *mattermostv1alpha1.MattermostRestoreDB, message string, reqLogger logr.Logger) error {
+	status := *restoreMM.Status.DeepCopy()
+	status.State = mattermostv1alpha1.Failed
+	status.Phase = mattermostv1alpha1.RestoreFailed
+	status.Message = message
+	status.CompletionTime = operation.Now()
+
+	return r.updateStatus(restoreMM, status, reqLogger)
+}
+
+func (r *MattermostRestoreDBReconciler) updateMattermost(restoreMM *mattermostv1alpha1.MattermostRestoreDB, mattermost *mmv1beta.Mattermost, message string) error {
+	err := r.Client.Update(context.TODO(), mattermost)
+	if err != nil {
+		return errors.Wrap(err, "failed to update Mattermost")
+	}
+	r.Recorder.Event(restoreMM, corev1.EventTypeNormal, eventReasonMattermostUpdated, message)
+
+	return nil
+}
@@ -15,8 +15,8 @@ import (
 	mattermostmysql "github.com/mattermost/mattermost-operator/pkg/components/mysql"
 )
 
-func (r *MattermostRestoreDBReconciler) updateMySQLSecrets(mattermostRestore *mattermostv1alpha1.MattermostRestoreDB, reqLogger logr.Logger) error {
-	dbSecretName := mattermostmysql.DefaultDatabaseSecretName(mattermostRestore.Spec.MattermostClusterName)
+func (r *MattermostRestoreDBReconciler) updateMySQLSecrets(mattermostRestore *mattermostv1alpha1.MattermostRestoreDB, installationName string, reqLogger logr.Logger) error {
+	dbSecretName := mattermostmysql.DefaultDatabaseSecretName(installationName)
 	dbSecret := &corev1.Secret{}
 	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: dbSecretName, Namespace: mattermostRestore.Namespace}, dbSecret)
 	if err != nil && errors.IsNotFound(err) {
@@ -9,10 +9,26 @@ import (
 
 	"github.com/go-logr/logr"
 	mattermostv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func (r *MattermostRestoreDBReconciler) updateStatus(mattermost *mattermostv1alpha1.MattermostRestoreDB, status mattermostv1alpha1.MattermostRestoreDBStatus, reqLogger logr.Logger) error {
 	if !reflect.DeepEqual(mattermost.Status, status) {
+		if mattermost.Status.State != status.State {
+			eventType := corev1.EventTypeNormal
+			if status.State == mattermostv1alpha1.Failed {
+				eventType = corev1.EventTypeWarning
+			}
+			r.Recorder.Eventf(mattermost, eventType, eventReasonStateChanged, "MattermostRestoreDB state changed from '%s' to '%s'", mattermost.Status.State, status.State)
+		}
+		if mattermost.Status.Phase != status.Phase {
+			eventType := corev1.EventTypeNormal
+			if status.Phase == mattermostv1alpha1.RestoreFailed {
+				eventType = corev1.EventTypeWarning
+			}
+			r.Recorder.Eventf(mattermost, eventType, eventReasonPhaseChanged, "MattermostRestoreDB phase changed from '%s' to '%s': %s", mattermost.Status.Phase, status.Phase, status.Message)
+		}
+
 		mattermost.Status = status
 		err := r.Client.Status().Update(context.TODO(), mattermost)
 		if err != nil {
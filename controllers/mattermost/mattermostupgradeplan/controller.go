@@ -0,0 +1,457 @@
+package mattermostupgradeplan
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/resources"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const requeueUpgradeDelay = 30 * time.Second
+
+// MattermostUpgradePlanReconciler reconciles a MattermostUpgradePlan object
+type MattermostUpgradePlanReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Resources *resources.ResourceHelper
+}
+
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostupgradeplans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=installation.mattermost.com,resources=mattermostupgradeplans/status,verbs=get;update;patch
+
+func (r *MattermostUpgradePlanReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mmv1beta.MattermostUpgradePlan{}).
+		Complete(r)
+}
+
+func (r *MattermostUpgradePlanReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Name", request.Name)
+	reqLogger.Info("Reconciling MattermostUpgradePlan")
+
+	plan := &mmv1beta.MattermostUpgradePlan{}
+	err := r.Client.Get(ctx, request.NamespacedName, plan)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if plan.Status.Phase == mmv1beta.UpgradePlanRolledBack {
+		return reconcile.Result{}, nil
+	}
+
+	status := *plan.Status.DeepCopy()
+
+	if status.StartTime == nil {
+		installations, err := r.selectInstallations(ctx, plan)
+		if err != nil {
+			if status.Message != err.Error() {
+				r.Recorder.Event(plan, corev1.EventTypeWarning, eventReasonInvalidSelector, err.Error())
+			}
+			status.Message = err.Error()
+			// The plan is reconciled again once the spec is updated.
+			return reconcile.Result{}, r.updateStatus(plan, status, reqLogger)
+		}
+		status.Installations = installations
+		status.Phase = mmv1beta.UpgradePlanProgressing
+		status.StartTime = &metav1.Time{Time: time.Now()}
+		r.Recorder.Eventf(plan, corev1.EventTypeNormal, eventReasonPlanStarted, "Selected %d installations for the upgrade to %s", len(installations), plan.Spec.Version)
+	}
+
+	if plan.Spec.Rollback {
+		result, err := r.rollback(ctx, plan, &status, reqLogger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		return result, r.updateStatus(plan, status, reqLogger)
+	}
+
+	if status.Phase == mmv1beta.UpgradePlanSucceeded {
+		return reconcile.Result{}, nil
+	}
+
+	for i := range status.Installations {
+		if status.Installations[i].Phase != mmv1beta.InstallationUpgradeInProgress {
+			continue
+		}
+		err = r.checkInstallation(ctx, plan, &status.Installations[i], reqLogger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	countInstallations(&status)
+
+	upgrading := installationsInPhase(status, mmv1beta.InstallationUpgradeInProgress)
+	pending := installationsInPhase(status, mmv1beta.InstallationUpgradePending)
+
+	result := reconcile.Result{}
+	if upgrading > 0 {
+		result.RequeueAfter = requeueUpgradeDelay
+	}
+
+	switch {
+	case status.Failed > plan.Spec.MaxFailures:
+		// Installations being upgraded are still followed, but no new batch
+		// is started until the failure budget is increased.
+		message := fmt.Sprintf("%d installations failed to upgrade, exceeding the failure budget of %d", status.Failed, plan.Spec.MaxFailures)
+		if status.Phase != mmv1beta.UpgradePlanHalted {
+			reqLogger.Info("Halting upgrade plan", "failed", status.Failed)
+			r.Recorder.Event(plan, corev1.EventTypeWarning, eventReasonPlanHalted, message)
+		}
+		status.Phase = mmv1beta.UpgradePlanHalted
+		status.Message = message
+	case upgrading > 0:
+		status.Phase = mmv1beta.UpgradePlanProgressing
+		status.Message = fmt.Sprintf("Waiting for %d installations of batch %d to become stable", upgrading, status.CurrentBatch)
+	case pending == 0:
+		status.Phase = mmv1beta.UpgradePlanSucceeded
+		status.Message = fmt.Sprintf("Upgraded %d of %d installations to %s", status.Succeeded, status.Total, plan.Spec.Version)
+		status.CompletionTime = &metav1.Time{Time: time.Now()}
+		r.Recorder.Event(plan, corev1.EventTypeNormal, eventReasonPlanSucceeded, status.Message)
+	case plan.Spec.Paused:
+		status.Phase = mmv1beta.UpgradePlanPaused
+		status.Message = fmt.Sprintf("Paused with %d installations pending", pending)
+	default:
+		started, err := r.startBatch(ctx, plan, &status, reqLogger)
+		if err != nil {
+			statusErr := r.updateStatus(plan, status, reqLogger)
+			if statusErr != nil {
+				reqLogger.Error(statusErr, "Error updating status")
+			}
+			return reconcile.Result{}, err
+		}
+		countInstallations(&status)
+		status.Phase = mmv1beta.UpgradePlanProgressing
+		status.Message = fmt.Sprintf("Started batch %d with %d installations", status.CurrentBatch, started)
+		// Installations of the batch may have been skipped or not found,
+		// in which case the next batch is started on the next reconcile.
+		result.RequeueAfter = requeueUpgradeDelay
+	}
+
+	return result, r.updateStatus(plan, status, reqLogger)
+}
+
+// selectInstallations returns the installations selected by the plan sorted
+// by their namespace and name.
+func (r *MattermostUpgradePlanReconciler) selectInstallations(ctx context.Context, plan *mmv1beta.MattermostUpgradePlan) ([]mmv1beta.InstallationUpgradeStatus, error) {
+	selector := labels.Everything()
+	if plan.Spec.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(plan.Spec.Selector)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid selector")
+		}
+	}
+
+	namespaces := plan.Spec.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var installations []mmv1beta.InstallationUpgradeStatus
+	for _, namespace := range namespaces {
+		mattermostList := &mmv1beta.MattermostList{}
+		err := r.Client.List(ctx, mattermostList,
+			client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list Mattermost installations")
+		}
+
+		for _, mattermost := range mattermostList.Items {
+			installations = append(installations, mmv1beta.InstallationUpgradeStatus{
+				Name:      mattermost.Name,
+				Namespace: mattermost.Namespace,
+				Phase:     mmv1beta.InstallationUpgradePending,
+			})
+		}
+	}
+
+	sort.Slice(installations, func(i, j int) bool {
+		if installations[i].Namespace != installations[j].Namespace {
+			return installations[i].Namespace < installations[j].Namespace
+		}
+		return installations[i].Name < installations[j].Name
+	})
+	// Namespaces may be listed more than once.
+	installations = slices.CompactFunc(installations, func(a, b mmv1beta.InstallationUpgradeStatus) bool {
+		return a.Namespace == b.Namespace && a.Name == b.Name
+	})
+
+	return installations, nil
+}
+
+// startBatch upgrades the next pending installations and returns how many
+// of them were started.
+func (r *MattermostUpgradePlanReconciler) startBatch(ctx context.Context, plan *mmv1beta.MattermostUpgradePlan, status *mmv1beta.MattermostUpgradePlanStatus, reqLogger logr.Logger) (int, error) {
+	status.CurrentBatch++
+
+	started := 0
+	for i := range status.Installations {
+		if started == plan.GetBatchSize() {
+			break
+		}
+		installation := &status.Installations[i]
+		if installation.Phase != mmv1beta.InstallationUpgradePending {
+			continue
+		}
+
+		installation.Batch = status.CurrentBatch
+		err := r.upgradeInstallation(ctx, plan, installation, reqLogger)
+		if err != nil {
+			return started, err
+		}
+		started++
+	}
+
+	r.Recorder.Eventf(plan, corev1.EventTypeNormal, eventReasonBatchStarted, "Started batch %d with %d installations", status.CurrentBatch, started)
+	return started, nil
+}
+
+// upgradeInstallation sets the target image and version of the plan on the
+// installation and records the previous ones, so that it can be rolled back.
+func (r *MattermostUpgradePlanReconciler) upgradeInstallation(ctx context.Context, plan *mmv1beta.MattermostUpgradePlan, installation *mmv1beta.InstallationUpgradeStatus, reqLogger logr.Logger) error {
+	now := metav1.Now()
+
+	mattermost := &mmv1beta.Mattermost{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: installation.Name, Namespace: installation.Namespace}, mattermost)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			failInstallation(installation, "Mattermost installation not found")
+			r.Recorder.Eventf(plan, corev1.EventTypeWarning, eventReasonInstallationFailed, "Mattermost %s/%s not found", installation.Namespace, installation.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get Mattermost %s/%s", installation.Namespace, installation.Name)
+	}
+
+	image := plan.TargetImage(mattermost)
+	if mattermost.Spec.Image == image && mattermost.Spec.Version == plan.Spec.Version {
+		installation.Phase = mmv1beta.InstallationUpgradeSkipped
+		installation.CompletionTime = &now
+		installation.Message = "Mattermost already runs the target version"
+		return nil
+	}
+
+	reqLogger.Info("Upgrading Mattermost", "Mattermost.Namespace", mattermost.Namespace, "Mattermost.Name", mattermost.Name, "version", plan.Spec.Version)
+	patch := client.MergeFrom(mattermost.DeepCopy())
+	installation.PreviousImage = mattermost.Spec.Image
+	installation.PreviousVersion = mattermost.Spec.Version
+	mattermost.Spec.Image = image
+	mattermost.Spec.Version = plan.Spec.Version
+	if mattermost.Annotations == nil {
+		mattermost.Annotations = map[string]string{}
+	}
+	mattermost.Annotations[mmv1beta.UpgradePlanAnnotation] = plan.Name
+	err = r.Client.Patch(ctx, mattermost, patch)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upgrade Mattermost %s/%s", mattermost.Namespace, mattermost.Name)
+	}
+
+	installation.Phase = mmv1beta.InstallationUpgradeInProgress
+	installation.StartTime = &now
+	installation.Message = fmt.Sprintf("Upgrading from %s:%s", installation.PreviousImage, installation.PreviousVersion)
+	return nil
+}
+
+// checkInstallation follows the upgrade of the installation using the health
+// signals reported by the Mattermost reconciler.
+func (r *MattermostUpgradePlanReconciler) checkInstallation(ctx context.Context, plan *mmv1beta.MattermostUpgradePlan, installation *mmv1beta.InstallationUpgradeStatus, reqLogger logr.Logger) error {
+	mattermost := &mmv1beta.Mattermost{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: installation.Name, Namespace: installation.Namespace}, mattermost)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			failInstallation(installation, "Mattermost installation not found")
+			r.Recorder.Eventf(plan, corev1.EventTypeWarning, eventReasonInstallationFailed, "Mattermost %s/%s not found", installation.Namespace, installation.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get Mattermost %s/%s", installation.Namespace, installation.Name)
+	}
+
+	var message string
+	switch {
+	case mattermost.IsRolledOut():
+		reqLogger.Info("Mattermost upgraded", "Mattermost.Namespace", mattermost.Namespace, "Mattermost.Name", mattermost.Name)
+		now := metav1.Now()
+		installation.Phase = mmv1beta.InstallationUpgradeSucceeded
+		installation.CompletionTime = &now
+		installation.Message = "Mattermost is stable"
+		return nil
+	case mattermost.Status.Rollout.IsAbortedFor(mattermost.GetPinnedImageName(), mattermost.Generation):
+		message = fmt.Sprintf("Rollout aborted: %s", mattermost.Status.Rollout.Message)
+	case installation.StartTime != nil && time.Since(installation.StartTime.Time) > plan.GetTimeout():
+		message = fmt.Sprintf("Mattermost did not become stable within %s", plan.GetTimeout())
+		if mattermost.Status.Error != "" {
+			message = fmt.Sprintf("%s: %s", message, mattermost.Status.Error)
+		}
+	default:
+		installation.Message = fmt.Sprintf("Waiting for Mattermost to become stable, current state: %s", mattermost.Status.State)
+		return nil
+	}
+
+	reqLogger.Info("Mattermost failed to upgrade", "Mattermost.Namespace", mattermost.Namespace, "Mattermost.Name", mattermost.Name, "reason", message)
+	failInstallation(installation, message)
+	r.Recorder.Eventf(plan, corev1.EventTypeWarning, eventReasonInstallationFailed, "Mattermost %s/%s failed to upgrade: %s", mattermost.Namespace, mattermost.Name, message)
+	return nil
+}
+
+func failInstallation(installation *mmv1beta.InstallationUpgradeStatus, message string) {
+	now := metav1.Now()
+	installation.Phase = mmv1beta.InstallationUpgradeFailed
+	installation.CompletionTime = &now
+	installation.Message = message
+}
+
+// rollback restores the previous image and version of the installations
+// upgraded by the plan and waits until they run them again. Installations
+// changed since the upgrade are left as they are.
+func (r *MattermostUpgradePlanReconciler) rollback(ctx context.Context, plan *mmv1beta.MattermostUpgradePlan, status *mmv1beta.MattermostUpgradePlanStatus, reqLogger logr.Logger) (reconcile.Result, error) {
+	var kept []string
+	for i := range status.Installations {
+		installation := &status.Installations[i]
+		switch installation.Phase {
+		case mmv1beta.InstallationUpgradeInProgress, mmv1beta.InstallationUpgradeSucceeded, mmv1beta.InstallationUpgradeFailed:
+		default:
+			continue
+		}
+		if installation.PreviousVersion == "" {
+			// The installation was not found when the batch was started.
+			continue
+		}
+
+		name := fmt.Sprintf("%s/%s", installation.Namespace, installation.Name)
+		mattermost := &mmv1beta.Mattermost{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: installation.Name, Namespace: installation.Namespace}, mattermost)
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to get Mattermost %s", name)
+		}
+		if err != nil || mattermost.Spec.Version != plan.Spec.Version || mattermost.Spec.Image != plan.TargetImage(mattermost) {
+			installation.Message = "Mattermost was changed after the upgrade, not rolled back"
+			kept = append(kept, name)
+			continue
+		}
+
+		reqLogger.Info("Rolling back Mattermost", "Mattermost.Namespace", mattermost.Namespace, "Mattermost.Name", mattermost.Name, "version", installation.PreviousVersion)
+		patch := client.MergeFrom(mattermost.DeepCopy())
+		mattermost.Spec.Image = installation.PreviousImage
+		mattermost.Spec.Version = installation.PreviousVersion
+		if mattermost.Annotations == nil {
+			mattermost.Annotations = map[string]string{}
+		}
+		// The rollback is a downgrade, which is blocked otherwise.
+		mattermost.Annotations[mmv1beta.AllowUnsupportedUpgradeAnnotation] = installation.PreviousVersion
+		err = r.Client.Patch(ctx, mattermost, patch)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to roll back Mattermost %s", name)
+		}
+
+		installation.Phase = mmv1beta.InstallationUpgradeRollingBack
+		installation.Message = fmt.Sprintf("Rolling back to %s:%s", installation.PreviousImage, installation.PreviousVersion)
+	}
+
+	for i := range status.Installations {
+		if status.Installations[i].Phase != mmv1beta.InstallationUpgradeRollingBack {
+			continue
+		}
+		err := r.checkRollback(ctx, &status.Installations[i], reqLogger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	countInstallations(status)
+
+	if rollingBack := installationsInPhase(*status, mmv1beta.InstallationUpgradeRollingBack); rollingBack > 0 {
+		status.Phase = mmv1beta.UpgradePlanRollingBack
+		status.Message = fmt.Sprintf("Waiting for %d installations to run their previous version", rollingBack)
+		return reconcile.Result{RequeueAfter: requeueUpgradeDelay}, nil
+	}
+
+	status.Phase = mmv1beta.UpgradePlanRolledBack
+	status.Message = fmt.Sprintf("Rolled back %d installations", installationsInPhase(*status, mmv1beta.InstallationUpgradeRolledBack))
+	if len(kept) > 0 {
+		status.Message = fmt.Sprintf("%s, kept %s changed after the upgrade", status.Message, strings.Join(kept, ", "))
+	}
+	status.CompletionTime = &metav1.Time{Time: time.Now()}
+	r.Recorder.Event(plan, corev1.EventTypeNormal, eventReasonPlanRolledBack, status.Message)
+
+	return reconcile.Result{}, nil
+}
+
+// checkRollback marks the installation rolled back once it is stable with
+// its previous image and version. The annotation allowing the downgrade is
+// removed once the rollback is finished.
+func (r *MattermostUpgradePlanReconciler) checkRollback(ctx context.Context, installation *mmv1beta.InstallationUpgradeStatus, reqLogger logr.Logger) error {
+	mattermost := &mmv1beta.Mattermost{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: installation.Name, Namespace: installation.Namespace}, mattermost)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			failInstallation(installation, "Mattermost installation not found")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get Mattermost %s/%s", installation.Namespace, installation.Name)
+	}
+
+	switch {
+	case mattermost.Spec.Image != installation.PreviousImage || mattermost.Spec.Version != installation.PreviousVersion:
+		failInstallation(installation, "Mattermost was changed during the rollback")
+	case mattermost.IsRolledOut():
+		reqLogger.Info("Mattermost rolled back", "Mattermost.Namespace", mattermost.Namespace, "Mattermost.Name", mattermost.Name)
+		now := metav1.Now()
+		installation.Phase = mmv1beta.InstallationUpgradeRolledBack
+		installation.CompletionTime = &now
+		installation.Message = fmt.Sprintf("Rolled back to %s:%s", installation.PreviousImage, installation.PreviousVersion)
+	default:
+		installation.Message = fmt.Sprintf("Waiting for Mattermost to run %s, current version: %s", installation.PreviousVersion, mattermost.Status.Version)
+		return nil
+	}
+
+	// Annotations set by the user to a different version are kept.
+	if mattermost.Annotations[mmv1beta.AllowUnsupportedUpgradeAnnotation] != installation.PreviousVersion {
+		return nil
+	}
+	patch := client.MergeFrom(mattermost.DeepCopy())
+	delete(mattermost.Annotations, mmv1beta.AllowUnsupportedUpgradeAnnotation)
+	err = r.Client.Patch(ctx, mattermost, patch)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove the rollback annotation from Mattermost %s/%s", installation.Namespace, installation.Name)
+	}
+	return nil
+}
+
+func countInstallations(status *mmv1beta.MattermostUpgradePlanStatus) {
+	status.Total = int32(len(status.Installations))
+	status.Succeeded = installationsInPhase(*status, mmv1beta.InstallationUpgradeSucceeded)
+	status.Failed = installationsInPhase(*status, mmv1beta.InstallationUpgradeFailed)
+}
+
+func installationsInPhase(status mmv1beta.MattermostUpgradePlanStatus, phase mmv1beta.InstallationUpgradePhase) int32 {
+	var count int32
+	for _, installation := range status.Installations {
+		if installation.Phase == phase {
+			count++
+		}
+	}
+	return count
+}
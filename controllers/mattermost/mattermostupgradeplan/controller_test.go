@@ -0,0 +1,305 @@
+package mattermostupgradeplan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/controllertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	planName       = "upgrade-10-5"
+	image          = "mattermost/mattermost-enterprise-edition"
+	currentVersion = "10.4.0"
+	targetVersion  = "10.5.0"
+)
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *MattermostUpgradePlanReconciler {
+	deps := controllertest.NewDeps(t, objects...)
+	return &MattermostUpgradePlanReconciler{
+		Client:    deps.Client,
+		Log:       deps.Log,
+		Scheme:    deps.Scheme,
+		Recorder:  deps.Recorder,
+		Resources: deps.Resources,
+	}
+}
+
+func newTestPlan(batchSize int32) *mmv1beta.MattermostUpgradePlan {
+	return &mmv1beta.MattermostUpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: planName,
+		},
+		Spec: mmv1beta.MattermostUpgradePlanSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "production"},
+			},
+			Version:   targetVersion,
+			BatchSize: batchSize,
+		},
+	}
+}
+
+func newTestMattermost(name, namespace string, tier string) *mmv1beta.Mattermost {
+	mattermost := controllertest.NewMattermost()
+	mattermost.Name = name
+	mattermost.Namespace = namespace
+	mattermost.Labels = map[string]string{"tier": tier}
+	mattermost.Spec.Image = image
+	mattermost.Spec.Version = currentVersion
+	return mattermost
+}
+
+func reconcilePlan(t *testing.T, r *MattermostUpgradePlanReconciler) (reconcile.Result, *mmv1beta.MattermostUpgradePlan) {
+	result := controllertest.Reconcile(t, r, planName, "")
+	return result, controllertest.Get(t, r.Client, planName, "", &mmv1beta.MattermostUpgradePlan{})
+}
+
+func getMattermost(t *testing.T, r *MattermostUpgradePlanReconciler, name, namespace string) *mmv1beta.Mattermost {
+	return controllertest.Get(t, r.Client, name, namespace, &mmv1beta.Mattermost{})
+}
+
+// setStable reports the Mattermost stable the way the health check of the
+// Mattermost reconciler does.
+func setStable(t *testing.T, r *MattermostUpgradePlanReconciler, name, namespace string) {
+	mattermost := getMattermost(t, r, name, namespace)
+	mattermost.Status.State = mmv1beta.Stable
+	mattermost.Status.ObservedGeneration = mattermost.Generation
+	mattermost.Status.Image = mattermost.Spec.Image
+	mattermost.Status.Version = mattermost.Spec.Version
+	require.NoError(t, r.Client.Status().Update(context.TODO(), mattermost))
+}
+
+func installationPhases(plan *mmv1beta.MattermostUpgradePlan) map[string]mmv1beta.InstallationUpgradePhase {
+	phases := map[string]mmv1beta.InstallationUpgradePhase{}
+	for _, installation := range plan.Status.Installations {
+		phases[installation.Namespace+"/"+installation.Name] = installation.Phase
+	}
+	return phases
+}
+
+func TestReconcileUpgradePlan(t *testing.T) {
+	r := newTestReconciler(t,
+		newTestPlan(2),
+		newTestMattermost("foo", "team-a", "production"),
+		newTestMattermost("bar", "team-a", "production"),
+		newTestMattermost("baz", "team-b", "production"),
+		newTestMattermost("test", "team-b", "staging"),
+	)
+
+	result, plan := reconcilePlan(t, r)
+	assert.Equal(t, requeueUpgradeDelay, result.RequeueAfter)
+	assert.Equal(t, mmv1beta.UpgradePlanProgressing, plan.Status.Phase)
+	assert.Equal(t, int32(1), plan.Status.CurrentBatch)
+	assert.Equal(t, int32(3), plan.Status.Total)
+	assert.Equal(t, map[string]mmv1beta.InstallationUpgradePhase{
+		"team-a/bar": mmv1beta.InstallationUpgradeInProgress,
+		"team-a/foo": mmv1beta.InstallationUpgradeInProgress,
+		"team-b/baz": mmv1beta.InstallationUpgradePending,
+	}, installationPhases(plan))
+	assert.Equal(t, currentVersion, plan.Status.Installations[0].PreviousVersion)
+
+	mattermost := getMattermost(t, r, "bar", "team-a")
+	assert.Equal(t, targetVersion, mattermost.Spec.Version)
+	assert.Equal(t, image, mattermost.Spec.Image)
+	assert.Equal(t, planName, mattermost.Annotations[mmv1beta.UpgradePlanAnnotation])
+	assert.Equal(t, currentVersion, getMattermost(t, r, "baz", "team-b").Spec.Version)
+	assert.Equal(t, currentVersion, getMattermost(t, r, "test", "team-b").Spec.Version)
+
+	t.Run("next batch waits for stable installations", func(t *testing.T) {
+		setStable(t, r, "bar", "team-a")
+
+		_, plan := reconcilePlan(t, r)
+		assert.Equal(t, int32(1), plan.Status.CurrentBatch)
+		assert.Equal(t, int32(1), plan.Status.Succeeded)
+		assert.Equal(t, mmv1beta.InstallationUpgradeInProgress, installationPhases(plan)["team-a/foo"])
+		assert.Equal(t, currentVersion, getMattermost(t, r, "baz", "team-b").Spec.Version)
+	})
+
+	t.Run("next batch started", func(t *testing.T) {
+		setStable(t, r, "foo", "team-a")
+
+		_, plan := reconcilePlan(t, r)
+		assert.Equal(t, int32(2), plan.Status.CurrentBatch)
+		assert.Equal(t, int32(2), plan.Status.Succeeded)
+		assert.Equal(t, mmv1beta.InstallationUpgradeInProgress, installationPhases(plan)["team-b/baz"])
+		assert.Equal(t, targetVersion, getMattermost(t, r, "baz", "team-b").Spec.Version)
+	})
+
+	t.Run("plan succeeded", func(t *testing.T) {
+		setStable(t, r, "baz", "team-b")
+
+		result, plan := reconcilePlan(t, r)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Equal(t, mmv1beta.UpgradePlanSucceeded, plan.Status.Phase)
+		assert.Equal(t, int32(3), plan.Status.Succeeded)
+		assert.NotNil(t, plan.Status.CompletionTime)
+	})
+}
+
+func TestReconcileUpgradePlanHalt(t *testing.T) {
+	plan := newTestPlan(1)
+	plan.Spec.Namespaces = []string{"team-a"}
+	r := newTestReconciler(t,
+		plan,
+		newTestMattermost("bar", "team-a", "production"),
+		newTestMattermost("foo", "team-a", "production"),
+		newTestMattermost("baz", "team-b", "production"),
+	)
+
+	_, plan = reconcilePlan(t, r)
+	require.Equal(t, int32(2), plan.Status.Total)
+	require.Equal(t, mmv1beta.InstallationUpgradeInProgress, installationPhases(plan)["team-a/bar"])
+
+	mattermost := getMattermost(t, r, "bar", "team-a")
+	mattermost.Status.Rollout = &mmv1beta.RolloutStatus{
+		Phase:             mmv1beta.RolloutAborted,
+		Image:             mattermost.GetImageName(),
+		AbortedGeneration: mattermost.Generation,
+		Message:           "canary is not healthy",
+	}
+	require.NoError(t, r.Client.Status().Update(context.TODO(), mattermost))
+
+	result, plan := reconcilePlan(t, r)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Equal(t, mmv1beta.UpgradePlanHalted, plan.Status.Phase)
+	assert.Equal(t, int32(1), plan.Status.Failed)
+	assert.Contains(t, plan.Status.Installations[0].Message, "canary is not healthy")
+	assert.Equal(t, mmv1beta.InstallationUpgradePending, installationPhases(plan)["team-a/foo"])
+	assert.Equal(t, currentVersion, getMattermost(t, r, "foo", "team-a").Spec.Version)
+
+	t.Run("increased failure budget resumes the plan", func(t *testing.T) {
+		plan.Spec.MaxFailures = 1
+		plan.Spec.Timeout = &metav1.Duration{Duration: time.Nanosecond}
+		require.NoError(t, r.Client.Update(context.TODO(), plan))
+
+		_, plan := reconcilePlan(t, r)
+		assert.Equal(t, mmv1beta.UpgradePlanProgressing, plan.Status.Phase)
+		assert.Equal(t, mmv1beta.InstallationUpgradeInProgress, installationPhases(plan)["team-a/foo"])
+		assert.Equal(t, targetVersion, getMattermost(t, r, "foo", "team-a").Spec.Version)
+	})
+
+	t.Run("timed out installation", func(t *testing.T) {
+		_, plan := reconcilePlan(t, r)
+		assert.Equal(t, mmv1beta.UpgradePlanHalted, plan.Status.Phase)
+		assert.Equal(t, int32(2), plan.Status.Failed)
+		assert.Contains(t, plan.Status.Installations[1].Message, "did not become stable")
+	})
+}
+
+func TestReconcileUpgradePlanPaused(t *testing.T) {
+	plan := newTestPlan(1)
+	plan.Spec.Paused = true
+	r := newTestReconciler(t, plan, newTestMattermost("foo", "team-a", "production"))
+
+	result, plan := reconcilePlan(t, r)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Equal(t, mmv1beta.UpgradePlanPaused, plan.Status.Phase)
+	assert.Equal(t, int32(1), plan.Status.Total)
+	assert.Equal(t, currentVersion, getMattermost(t, r, "foo", "team-a").Spec.Version)
+
+	plan.Spec.Paused = false
+	require.NoError(t, r.Client.Update(context.TODO(), plan))
+
+	_, plan = reconcilePlan(t, r)
+	assert.Equal(t, mmv1beta.UpgradePlanProgressing, plan.Status.Phase)
+	assert.Equal(t, targetVersion, getMattermost(t, r, "foo", "team-a").Spec.Version)
+}
+
+func TestReconcileUpgradePlanSkipsUpgradedInstallations(t *testing.T) {
+	mattermost := newTestMattermost("foo", "team-a", "production")
+	mattermost.Spec.Version = targetVersion
+	r := newTestReconciler(t, newTestPlan(1), mattermost)
+
+	_, plan := reconcilePlan(t, r)
+	assert.Equal(t, mmv1beta.InstallationUpgradeSkipped, installationPhases(plan)["team-a/foo"])
+	assert.Empty(t, getMattermost(t, r, "foo", "team-a").Annotations[mmv1beta.UpgradePlanAnnotation])
+
+	_, plan = reconcilePlan(t, r)
+	assert.Equal(t, mmv1beta.UpgradePlanSucceeded, plan.Status.Phase)
+	assert.Equal(t, int32(0), plan.Status.Succeeded)
+}
+
+func TestReconcileUpgradePlanRollback(t *testing.T) {
+	plan := newTestPlan(2)
+	plan.Spec.Image = "mattermost/mattermost-team-edition"
+	r := newTestReconciler(t,
+		plan,
+		newTestMattermost("foo", "team-a", "production"),
+		newTestMattermost("bar", "team-a", "production"),
+		newTestMattermost("baz", "team-a", "production"),
+	)
+
+	_, plan = reconcilePlan(t, r)
+	require.Equal(t, "mattermost/mattermost-team-edition", getMattermost(t, r, "bar", "team-a").Spec.Image)
+
+	// Changes made after the upgrade are not overwritten.
+	mattermost := getMattermost(t, r, "baz", "team-a")
+	mattermost.Spec.Version = "10.5.1"
+	require.NoError(t, r.Client.Update(context.TODO(), mattermost))
+
+	plan.Spec.Rollback = true
+	require.NoError(t, r.Client.Update(context.TODO(), plan))
+
+	result, plan := reconcilePlan(t, r)
+	assert.Equal(t, requeueUpgradeDelay, result.RequeueAfter)
+	assert.Equal(t, mmv1beta.UpgradePlanRollingBack, plan.Status.Phase)
+	assert.Equal(t, map[string]mmv1beta.InstallationUpgradePhase{
+		"team-a/bar": mmv1beta.InstallationUpgradeRollingBack,
+		"team-a/baz": mmv1beta.InstallationUpgradeInProgress,
+		"team-a/foo": mmv1beta.InstallationUpgradePending,
+	}, installationPhases(plan))
+
+	mattermost = getMattermost(t, r, "bar", "team-a")
+	assert.Equal(t, image, mattermost.Spec.Image)
+	assert.Equal(t, currentVersion, mattermost.Spec.Version)
+	assert.Equal(t, currentVersion, mattermost.Annotations[mmv1beta.AllowUnsupportedUpgradeAnnotation])
+	assert.True(t, mattermost.UnsupportedUpgradeAllowed())
+	assert.Equal(t, "10.5.1", getMattermost(t, r, "baz", "team-a").Spec.Version)
+	assert.Equal(t, currentVersion, getMattermost(t, r, "foo", "team-a").Spec.Version)
+
+	t.Run("rolled back once previous version is stable", func(t *testing.T) {
+		setStable(t, r, "bar", "team-a")
+
+		result, plan := reconcilePlan(t, r)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Equal(t, mmv1beta.UpgradePlanRolledBack, plan.Status.Phase)
+		assert.Equal(t, mmv1beta.InstallationUpgradeRolledBack, installationPhases(plan)["team-a/bar"])
+		assert.Equal(t, mmv1beta.InstallationUpgradeInProgress, installationPhases(plan)["team-a/baz"])
+		assert.Contains(t, plan.Status.Message, "Rolled back 1 installations")
+		assert.Contains(t, plan.Status.Message, "kept team-a/baz")
+
+		mattermost := getMattermost(t, r, "bar", "team-a")
+		assert.NotContains(t, mattermost.Annotations, mmv1beta.AllowUnsupportedUpgradeAnnotation)
+		assert.False(t, mattermost.UnsupportedUpgradeAllowed())
+	})
+}
+
+func TestReconcileUpgradePlanRollbackChanged(t *testing.T) {
+	plan := newTestPlan(1)
+	r := newTestReconciler(t, plan, newTestMattermost("foo", "team-a", "production"))
+
+	_, plan = reconcilePlan(t, r)
+	plan.Spec.Rollback = true
+	require.NoError(t, r.Client.Update(context.TODO(), plan))
+
+	_, plan = reconcilePlan(t, r)
+	require.Equal(t, mmv1beta.InstallationUpgradeRollingBack, installationPhases(plan)["team-a/foo"])
+
+	// Changes made during the rollback fail the installation.
+	mattermost := getMattermost(t, r, "foo", "team-a")
+	mattermost.Spec.Version = "10.5.1"
+	require.NoError(t, r.Client.Update(context.TODO(), mattermost))
+
+	_, plan = reconcilePlan(t, r)
+	assert.Equal(t, mmv1beta.UpgradePlanRolledBack, plan.Status.Phase)
+	assert.Equal(t, mmv1beta.InstallationUpgradeFailed, installationPhases(plan)["team-a/foo"])
+	assert.NotContains(t, getMattermost(t, r, "foo", "team-a").Annotations, mmv1beta.AllowUnsupportedUpgradeAnnotation)
+}
@@ -0,0 +1,12 @@
+package mattermostupgradeplan
+
+// Reasons of the events emitted on MattermostUpgradePlan resources.
+const (
+	eventReasonPlanStarted        = "PlanStarted"
+	eventReasonBatchStarted       = "BatchStarted"
+	eventReasonInstallationFailed = "InstallationFailed"
+	eventReasonPlanHalted         = "PlanHalted"
+	eventReasonPlanSucceeded      = "PlanSucceeded"
+	eventReasonPlanRolledBack     = "PlanRolledBack"
+	eventReasonInvalidSelector    = "InvalidSelector"
+)
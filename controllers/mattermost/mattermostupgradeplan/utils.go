@@ -0,0 +1,24 @@
+package mattermostupgradeplan
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+)
+
+func (r *MattermostUpgradePlanReconciler) updateStatus(plan *mmv1beta.MattermostUpgradePlan, status mmv1beta.MattermostUpgradePlanStatus, reqLogger logr.Logger) error {
+	if reflect.DeepEqual(plan.Status, status) {
+		return nil
+	}
+
+	plan.Status = status
+	err := r.Client.Status().Update(context.TODO(), plan)
+	if err != nil {
+		reqLogger.Error(err, "failed to update the mattermostupgradeplan status")
+		return err
+	}
+
+	return nil
+}
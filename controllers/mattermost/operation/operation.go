@@ -0,0 +1,64 @@
+// Package operation contains the helpers shared by the controllers running
+// operations on Mattermost installations, like database migrations, restores
+// and backups.
+package operation
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Now returns the current time for the time fields of the statuses.
+func Now() *metav1.Time {
+	t := metav1.Now()
+	return &t
+}
+
+// SetReplicas sets replicas of Mattermost and enables or disables the
+// dedicated job server.
+func SetReplicas(mattermost *mmv1beta.Mattermost, replicas *int32, dedicatedJobServer bool) {
+	mattermost.Spec.Replicas = replicas
+	if mattermost.Spec.JobServer != nil {
+		mattermost.Spec.JobServer.DedicatedJobServer = dedicatedJobServer
+	}
+}
+
+// ScaleDown sets the replicas of Mattermost to zero and disables the
+// dedicated job server. It returns false if Mattermost is scaled down
+// already and does not have to be updated.
+func ScaleDown(mattermost *mmv1beta.Mattermost) bool {
+	if mattermost.Spec.Replicas != nil && *mattermost.Spec.Replicas == 0 &&
+		(mattermost.Spec.JobServer == nil || !mattermost.Spec.JobServer.DedicatedJobServer) {
+		return false
+	}
+
+	replicas := int32(0)
+	SetReplicas(mattermost, &replicas, false)
+	return true
+}
+
+// IsScaledDown returns true once all pods of Mattermost and of the dedicated
+// job server are stopped.
+func IsScaledDown(ctx context.Context, c client.Reader, mattermost *mmv1beta.Mattermost, reqLogger logr.Logger) (bool, error) {
+	for _, name := range []string{mattermost.Name, mattermost.DedicatedJobServerName()} {
+		deployment := &appsv1.Deployment{}
+		err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: mattermost.Namespace}, deployment)
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "failed to get Mattermost deployment")
+		}
+		if err == nil && (deployment.Status.Replicas != 0 || (deployment.Spec.Replicas != nil && *deployment.Spec.Replicas != 0)) {
+			reqLogger.Info("Waiting for Mattermost deployment to scale down", "Deployment.Name", name)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
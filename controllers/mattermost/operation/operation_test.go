@@ -0,0 +1,56 @@
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestScaleDown(t *testing.T) {
+	mattermost := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: mmv1beta.MattermostSpec{
+			Replicas:  utils.NewInt32(2),
+			JobServer: &mmv1beta.JobServer{DedicatedJobServer: true},
+		},
+	}
+
+	assert.True(t, ScaleDown(mattermost))
+	assert.Equal(t, int32(0), *mattermost.Spec.Replicas)
+	assert.False(t, mattermost.Spec.JobServer.DedicatedJobServer)
+	assert.False(t, ScaleDown(mattermost))
+
+	SetReplicas(mattermost, utils.NewInt32(2), true)
+	assert.Equal(t, int32(2), *mattermost.Spec.Replicas)
+	assert.True(t, mattermost.Spec.JobServer.DedicatedJobServer)
+}
+
+func TestIsScaledDown(t *testing.T) {
+	mattermost := &mmv1beta.Mattermost{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: utils.NewInt32(0)},
+		Status:     appsv1.DeploymentStatus{Replicas: 1},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment).WithStatusSubresource(deployment).Build()
+
+	scaledDown, err := IsScaledDown(context.TODO(), c, mattermost, logr.Discard())
+	require.NoError(t, err)
+	assert.False(t, scaledDown)
+
+	deployment.Status.Replicas = 0
+	require.NoError(t, c.Status().Update(context.TODO(), deployment))
+
+	scaledDown, err = IsScaledDown(context.TODO(), c, mattermost, logr.Discard())
+	require.NoError(t, err)
+	assert.True(t, scaledDown)
+}
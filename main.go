@@ -11,8 +11,16 @@ import (
 	blubr "github.com/mattermost/blubr"
 	"github.com/mattermost/mattermost-operator/controllers/mattermost/clusterinstallation"
 	"github.com/mattermost/mattermost-operator/controllers/mattermost/mattermost"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/mattermostbackup"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/mattermostbackupschedule"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/mattermostdbmigration"
 	"github.com/mattermost/mattermost-operator/controllers/mattermost/mattermostrestoredb"
+	"github.com/mattermost/mattermost-operator/controllers/mattermost/mattermostupgradeplan"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
 	mysqlv1alpha1 "github.com/mattermost/mattermost-operator/pkg/database/mysql_operator/v1alpha1"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
 	"github.com/mattermost/mattermost-operator/pkg/resources"
 	v1beta1Minio "github.com/minio/minio-operator/pkg/apis/miniocontroller/v1beta1"
 	"github.com/sirupsen/logrus"
@@ -52,6 +60,10 @@ func init() {
 
 	utilruntime.Must(v1beta1Minio.AddToScheme(scheme))
 	utilruntime.Must(mysqlv1alpha1.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(cnpgv1.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(certmanagerv1.SchemeBuilder.AddToScheme(scheme))
 }
 
 type Config struct {
@@ -70,10 +82,14 @@ func (c Config) String() string {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var enableWebhooks bool
 	flag.StringVar(&metricsAddr, "metrics-addr", fmt.Sprintf("%s:%d", metricsHost, metricsPort), "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable admission webhooks for Mattermost resources. "+
+			"Requires serving certificates to be mounted in the operator pod.")
 	flag.Parse()
 
 	// Setup logging.
@@ -121,18 +137,62 @@ func main() {
 		MaxReconciling:      config.MaxReconcilingInstallations,
 		RequeueOnLimitDelay: config.RequeueOnLimitDelay,
 		Resources:           resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
+		Recorder:            mgr.GetEventRecorderFor("mattermost-operator"),
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "Unable to create controller", "controller", "ClusterInstallation")
 		os.Exit(1)
 	}
 	if err = (&mattermostrestoredb.MattermostRestoreDBReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MattermostRestoreDB"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("MattermostRestoreDB"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("mattermost-operator"),
+		Resources: resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "Unable to create controller", "controller", "MattermostRestoreDB")
 		os.Exit(1)
 	}
+	if err = (&mattermostdbmigration.MattermostDBMigrationReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("MattermostDBMigration"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("mattermost-operator"),
+		Resources: resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "Unable to create controller", "controller", "MattermostDBMigration")
+		os.Exit(1)
+	}
+	if err = (&mattermostbackup.MattermostBackupReconciler{
+		Client:             mgr.GetClient(),
+		NonCachedAPIReader: mgr.GetAPIReader(),
+		Log:                ctrl.Log.WithName("controllers").WithName("MattermostBackup"),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           mgr.GetEventRecorderFor("mattermost-operator"),
+		Resources:          resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "Unable to create controller", "controller", "MattermostBackup")
+		os.Exit(1)
+	}
+	if err = (&mattermostbackupschedule.MattermostBackupScheduleReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("MattermostBackupSchedule"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("mattermost-operator"),
+		Resources: resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "Unable to create controller", "controller", "MattermostBackupSchedule")
+		os.Exit(1)
+	}
+	if err = (&mattermostupgradeplan.MattermostUpgradePlanReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("MattermostUpgradePlan"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("mattermost-operator"),
+		Resources: resources.NewResourceHelper(mgr.GetClient(), mgr.GetScheme()),
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "Unable to create controller", "controller", "MattermostUpgradePlan")
+		os.Exit(1)
+	}
 	if err = mattermost.NewMattermostReconciler(
 		mgr,
 		config.MaxReconcilingInstallations,
@@ -143,6 +203,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = (&mmv1beta.Mattermost{}).SetupWebhookWithManager(mgr); err != nil {
+			logger.Error(err, "Unable to create webhook", "webhook", "Mattermost")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	logger.Info("Starting manager")
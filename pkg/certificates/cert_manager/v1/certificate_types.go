@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: only the fields used by the Mattermost operator are defined here.
+// Unknown fields are preserved by the API server, but they are dropped when
+// the Certificate is updated by the operator.
+
+const (
+	// CertificateKind is the kind of the Certificate resource.
+	CertificateKind = "Certificate"
+	// IssuerKind is the kind of the namespaced Issuer resource.
+	IssuerKind = "Issuer"
+	// ClusterIssuerKind is the kind of the cluster scoped ClusterIssuer
+	// resource.
+	ClusterIssuerKind = "ClusterIssuer"
+)
+
+// +kubebuilder:object:root=true
+
+// A Certificate resource should be created to ensure an up to date and signed
+// X.509 certificate is stored in the Kubernetes Secret resource named in
+// `spec.secretName`.
+type Certificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired state of the Certificate resource.
+	Spec CertificateSpec `json:"spec"`
+
+	// Status of the Certificate.
+	// This is set and managed automatically.
+	// +optional
+	Status CertificateStatus `json:"status"`
+}
+
+// +kubebuilder:object:root=true
+
+// CertificateList is a list of Certificates.
+type CertificateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Certificate `json:"items"`
+}
+
+// CertificateSpec defines the desired state of Certificate.
+type CertificateSpec struct {
+	// Requested DNS subject alternative names.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// Requested 'duration' (i.e. lifetime) of the Certificate.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// How long before the currently issued certificate's expiry cert-manager
+	// should renew the certificate.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// Name of the Secret resource that will be automatically created and
+	// managed by this Certificate resource.
+	SecretName string `json:"secretName"`
+
+	// Reference to the issuer responsible for issuing the certificate.
+	IssuerRef ObjectReference `json:"issuerRef"`
+}
+
+// ObjectReference is a reference to an object with a given name, kind and
+// group.
+type ObjectReference struct {
+	// Name of the resource being referred to.
+	Name string `json:"name"`
+	// Kind of the resource being referred to.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Group of the resource being referred to.
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// CertificateStatus defines the observed state of Certificate.
+type CertificateStatus struct {
+	// List of status conditions to indicate the status of certificates.
+	// +optional
+	Conditions []CertificateCondition `json:"conditions,omitempty"`
+
+	// LastFailureTime is set only if the latest issuance for this
+	// Certificate failed and contains the time of the failure.
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// The expiration time of the certificate stored in the secret named
+	// by this resource in `spec.secretName`.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// RenewalTime is the time at which the certificate will be next
+	// renewed.
+	// +optional
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+
+	// The number of continuous failed issuance attempts up till now.
+	// +optional
+	FailedIssuanceAttempts *int `json:"failedIssuanceAttempts,omitempty"`
+}
+
+// CertificateCondition contains condition information for a Certificate.
+type CertificateCondition struct {
+	// Type of the condition, known values are (`Ready`, `Issuing`).
+	Type CertificateConditionType `json:"type"`
+
+	// Status of the condition, one of (`True`, `False`, `Unknown`).
+	Status metav1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the timestamp corresponding to the last status
+	// change of this condition.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a brief machine readable explanation for the condition's last
+	// transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the details of the last
+	// transition, complementing reason.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// If set, this represents the .metadata.generation that the condition was
+	// set based upon.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// CertificateConditionType represents a Certificate condition value.
+type CertificateConditionType string
+
+const (
+	// CertificateConditionReady indicates that a certificate is ready for use.
+	CertificateConditionReady CertificateConditionType = "Ready"
+
+	// CertificateConditionIssuing is set to true when a new certificate is
+	// being issued.
+	CertificateConditionIssuing CertificateConditionType = "Issuing"
+)
+
+func init() {
+	SchemeBuilder.Register(&Certificate{}, &CertificateList{})
+}
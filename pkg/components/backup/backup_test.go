@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"regexp"
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRetentionPattern(t *testing.T) {
+	dirs := []string{
+		"20250101T000000Z-backup/",
+		"20250102T000000Z-backup/",
+		"20250101T000000Z-backup-2/",
+		"20250101T000000Z-nightly-1735689600/",
+		"20250101T000000Z-nightly-2-1735689600/",
+		"20250101T000000Z-nightly/",
+		"uploads/",
+		"backup/",
+	}
+	matching := func(pattern string) []string {
+		var matched []string
+		for _, dir := range dirs {
+			if regexp.MustCompile(pattern).MatchString(dir) {
+				matched = append(matched, dir)
+			}
+		}
+		return matched
+	}
+
+	t.Run("backup created by hand", func(t *testing.T) {
+		backup := &mmv1beta.MattermostBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup"}}
+		assert.Equal(t, []string{"20250101T000000Z-backup/", "20250102T000000Z-backup/"}, matching(RetentionPattern(backup)))
+	})
+
+	t.Run("scheduled backup", func(t *testing.T) {
+		backup := &mmv1beta.MattermostBackup{ObjectMeta: metav1.ObjectMeta{
+			Name:   "nightly-1735776000",
+			Labels: map[string]string{mmv1beta.BackupScheduleLabel: "nightly"},
+		}}
+		assert.Equal(t, []string{"20250101T000000Z-nightly-1735689600/"}, matching(RetentionPattern(backup)))
+	})
+}
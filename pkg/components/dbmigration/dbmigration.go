@@ -0,0 +1,238 @@
+package dbmigration
+
+import (
+	"fmt"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	componentUtils "github.com/mattermost/mattermost-operator/pkg/components/utils"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	loadFileKey    = "migration.load"
+	configMountDir = "/etc/pgloader"
+	countsMountDir = "/counts"
+)
+
+// defaultLoadFile copies the data into the schema created by the Mattermost
+// version being migrated. The configuration stored in the database is
+// switched to PostgreSQL once the data is loaded. Tables of plugins managing
+// their own schema are skipped.
+const defaultLoadFile = `LOAD DATABASE
+     FROM {{SOURCE_URL}}
+     INTO {{TARGET_URL}}
+
+WITH data only,
+     truncate,
+     workers = 8, concurrency = 1,
+     multiple readers per thread, rows per range = 10000,
+     prefetch rows = 10000, batch rows = 2500,
+     create no tables, create no indexes,
+     preserve index names
+
+SET PostgreSQL PARAMETERS
+     maintenance_work_mem to '128MB',
+     work_mem to '12MB'
+
+SET MySQL PARAMETERS
+     net_read_timeout = '120',
+     net_write_timeout = '120'
+
+EXCLUDING TABLE NAMES MATCHING ~<IR_>, ~<focalboard>, 'schema_migrations', 'db_migrations', 'db_lock'
+
+ALTER SCHEMA '{{MYSQL_DATABASE}}' RENAME TO 'public'
+
+AFTER LOAD DO
+     $$ UPDATE public.configurations SET value = jsonb_set(jsonb_set(jsonb_set(value::jsonb, '{SqlSettings,DriverName}', '"postgres"'), '{SqlSettings,DataSource}', to_jsonb('{{TARGET_URL}}'::text)), '{SqlSettings,DataSourceReplicas}', '[]')::text; $$;
+`
+
+// countSourceScript writes the row count of every source table to the
+// counts volume.
+const countSourceScript = `set -e
+mysql -h "$MYSQL_HOST" -u "$MYSQL_USERNAME" -N -B "$MYSQL_DATABASE" \
+  -e "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'" |
+while read -r table; do
+  echo "$table $(mysql -h "$MYSQL_HOST" -u "$MYSQL_USERNAME" -N -B "$MYSQL_DATABASE" -e "SELECT COUNT(*) FROM ` + "`$table`" + `")"
+done > ` + countsMountDir + `/source
+`
+
+// verifyScript compares row counts of the source tables with the migrated
+// ones. Tables which do not exist in the target schema were not migrated and
+// are skipped, migration bookkeeping tables are expected to differ.
+const verifyScript = `status=0
+while read -r table count; do
+  case "$table" in
+    schema_migrations|db_migrations|db_lock) continue ;;
+  esac
+  name=$(echo "$table" | tr '[:upper:]' '[:lower:]')
+  if [ "$(psql "$TARGET_URL" -tAc "SELECT to_regclass('public.\"$name\"') IS NOT NULL")" != "t" ]; then
+    echo "skipping table $table not present in target database"
+    continue
+  fi
+  target=$(psql "$TARGET_URL" -tAc "SELECT COUNT(*) FROM public.\"$name\"")
+  if [ "$target" != "$count" ]; then
+    echo "row count mismatch for table $table: source $count, target $target"
+    status=1
+  fi
+done < ` + countsMountDir + `/source
+exit $status
+`
+
+// pgloaderScript builds the source connection string and strips connection
+// parameters not understood by pgloader before running the migration.
+// pgloader reads the password up to the at sign preceding the host, at signs
+// in the password have to be doubled. It does not decode percent-encoded
+// credentials.
+const pgloaderScript = `export SOURCE_URL="mysql://$MYSQL_USERNAME:$(printf '%s' "$MYSQL_PWD" | sed 's/@/@@/g')@$MYSQL_HOST:3306/$MYSQL_DATABASE"
+export TARGET_URL="$(echo "$TARGET_URL" | sed -E 's/([?&])connect_timeout=[0-9]+&?/\1/; s/[?&]$//')"
+exec pgloader --on-error-stop ` + configMountDir + `/` + loadFileKey + `
+`
+
+// ResourceName returns the name of the job and config map used by the migration.
+func ResourceName(migration *mmv1beta.MattermostDBMigration) string {
+	return fmt.Sprintf("%s-db-migration", migration.Name)
+}
+
+// ConfigMap returns the config map with the pgloader command file.
+func ConfigMap(migration *mmv1beta.MattermostDBMigration) *corev1.ConfigMap {
+	loadFile := defaultLoadFile
+	if migration.Spec.PgloaderCommands != "" {
+		loadFile = migration.Spec.PgloaderCommands
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ResourceName(migration),
+			Namespace: migration.Namespace,
+			Labels:    mmv1beta.MattermostResourceLabels(migration.Spec.MattermostName),
+		},
+		Data: map[string]string{
+			loadFileKey: loadFile,
+		},
+	}
+}
+
+// SourceEnvVars returns the environment variables describing the
+// Operator-managed MySQL database of the Mattermost installation.
+func SourceEnvVars(mattermost *mmv1beta.Mattermost, secretName, databaseName string) []corev1.EnvVar {
+	mysqlName := componentUtils.HashWithPrefix("db", mattermost.Name)
+
+	return []corev1.EnvVar{
+		{
+			Name:      "MYSQL_USERNAME",
+			ValueFrom: mattermostApp.EnvSourceFromSecret(secretName, "USER"),
+		},
+		{
+			// MYSQL_PWD is read by the mysql client.
+			Name:      "MYSQL_PWD",
+			ValueFrom: mattermostApp.EnvSourceFromSecret(secretName, "PASSWORD"),
+		},
+		{
+			Name:  "MYSQL_HOST",
+			Value: fmt.Sprintf("%s-mysql-master.%s.svc.cluster.local", mysqlName, mattermost.Namespace),
+		},
+		{
+			Name:  "MYSQL_DATABASE",
+			Value: databaseName,
+		},
+	}
+}
+
+// Job returns the job migrating the data of the Mattermost installation.
+// The target schema is created by the Mattermost version of the installation,
+// then the data is copied with pgloader and row counts are verified.
+// The targetDB config has to be generated for the Mattermost installation
+// with the target database set.
+func Job(migration *mmv1beta.MattermostDBMigration, mattermost *mmv1beta.Mattermost, sourceEnv []corev1.EnvVar, targetDB mattermostApp.DatabaseConfig) *batchv1.Job {
+	targetEnv := append(targetDB.EnvVars(mattermost), corev1.EnvVar{
+		Name:  "TARGET_URL",
+		Value: "$(MM_CONFIG)",
+	})
+	env := append(append([]corev1.EnvVar{}, sourceEnv...), targetEnv...)
+
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "pgloader", MountPath: configMountDir, ReadOnly: true},
+		{Name: "counts", MountPath: countsMountDir},
+	}
+
+	backoffLimit := int32(0)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ResourceName(migration),
+			Namespace: migration.Namespace,
+			Labels:    mmv1beta.MattermostResourceLabels(mattermost.Name),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": ResourceName(migration)},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyNever,
+					ImagePullSecrets: mattermost.Spec.ImagePullSecrets,
+					NodeSelector:     mattermost.Spec.Scheduling.NodeSelector,
+					Affinity:         mattermost.Spec.Scheduling.Affinity,
+					Tolerations:      mattermost.Spec.Scheduling.Tolerations,
+					InitContainers: []corev1.Container{
+						{
+							Name:            "create-schema",
+							Image:           mattermost.GetImageName(),
+							ImagePullPolicy: mattermost.Spec.ImagePullPolicy,
+							Command:         []string{"mattermost"},
+							Args:            []string{"db", "migrate"},
+							Env:             targetEnv,
+							Resources:       migration.Spec.Resources,
+						},
+						{
+							Name:            "migrate-data",
+							Image:           migration.GetPgloaderImage(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"sh", "-c", pgloaderScript},
+							Env:             env,
+							VolumeMounts:    volumeMounts,
+							Resources:       migration.Spec.Resources,
+						},
+						{
+							Name:            "count-source-rows",
+							Image:           migration.GetMySQLClientImage(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"sh", "-c", countSourceScript},
+							Env:             sourceEnv,
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "verify-row-counts",
+							Image:           migration.GetPostgresClientImage(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"sh", "-c", verifyScript},
+							Env:             targetEnv,
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "pgloader",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: ResourceName(migration)},
+								},
+							},
+						},
+						{
+							Name:         "counts",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"fmt"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	componentUtils "github.com/mattermost/mattermost-operator/pkg/components/utils"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
+	mattermostApp "github.com/mattermost/mattermost-operator/pkg/mattermost"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterV1Beta returns the CloudNativePG cluster to deploy
+func ClusterV1Beta(mattermost *mmv1beta.Mattermost) *cnpgv1.Cluster {
+	operatorManaged := mattermost.Spec.Database.OperatorManaged
+
+	cluster := &cnpgv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            componentUtils.HashWithPrefix("db", mattermost.Name),
+			Namespace:       mattermost.Namespace,
+			Labels:          mmv1beta.MattermostResourceLabels(mattermost.Name),
+			OwnerReferences: mattermostApp.MattermostOwnerReference(mattermost),
+		},
+		Spec: cnpgv1.ClusterSpec{
+			Description: fmt.Sprintf("Database of Mattermost installation %s", mattermost.Name),
+			ImageName:   operatorManaged.GetPostgresImage(),
+			Instances:   1,
+			Bootstrap: &cnpgv1.BootstrapConfiguration{
+				InitDB: &cnpgv1.BootstrapInitDB{
+					Database: "mattermost",
+					Owner:    "mmuser",
+					Secret: &cnpgv1.LocalObjectReference{
+						Name: DefaultDatabaseSecretName(mattermost.Name),
+					},
+				},
+			},
+			StorageConfiguration: cnpgv1.StorageConfiguration{
+				Size: operatorManaged.StorageSize,
+			},
+			Resources: operatorManaged.Resources,
+		},
+	}
+
+	if operatorManaged.Replicas != nil && *operatorManaged.Replicas > 0 {
+		cluster.Spec.Instances = int(*operatorManaged.Replicas)
+	}
+
+	return cluster
+}
+
+// DefaultDatabaseSecretName returns the default database secret name based on
+// the provided installation name.
+func DefaultDatabaseSecretName(installationName string) string {
+	return fmt.Sprintf("%s-postgres-credentials", installationName)
+}
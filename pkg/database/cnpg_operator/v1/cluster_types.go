@@ -0,0 +1,163 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: only the fields used by the Mattermost operator are defined here.
+// Unknown fields are preserved by the API server, but they are dropped when
+// the Cluster is updated by the operator.
+
+// ClusterSpec defines the desired state of Cluster
+type ClusterSpec struct {
+	// Description of this PostgreSQL cluster
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Name of the container image, supporting both tags (`<image>:<tag>`)
+	// and digests for deterministic and repeatable deployments
+	// (`<image>:<tag>@sha256:<digestValue>`)
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// Number of instances required in the cluster
+	// +kubebuilder:validation:Minimum=1
+	Instances int `json:"instances"`
+
+	// Instructions to bootstrap this cluster
+	// +optional
+	Bootstrap *BootstrapConfiguration `json:"bootstrap,omitempty"`
+
+	// Configuration of the storage of the instances
+	// +optional
+	StorageConfiguration StorageConfiguration `json:"storage,omitempty"`
+
+	// Resources requirements of every generated Pod. Please refer to
+	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// for more information.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// BootstrapConfiguration contains information about how to create the PostgreSQL
+// cluster. Only a single bootstrap method can be defined among the supported
+// ones.
+type BootstrapConfiguration struct {
+	// Bootstrap the cluster via initdb
+	// +optional
+	InitDB *BootstrapInitDB `json:"initdb,omitempty"`
+}
+
+// BootstrapInitDB is the configuration of the bootstrap process when
+// initdb is used
+type BootstrapInitDB struct {
+	// Name of the database used by the application. Default: `app`.
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// Name of the owner of the database in the instance to be used
+	// by applications. Defaults to the value of the `database` key.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Name of the secret containing the initial credentials for the
+	// owner of the user database. If empty a new secret will be
+	// created from scratch
+	// +optional
+	Secret *LocalObjectReference `json:"secret,omitempty"`
+}
+
+// StorageConfiguration is the configuration used to create and reconcile PVCs,
+// usable for WAL volumes, PGDATA volumes, or tablespaces
+type StorageConfiguration struct {
+	// StorageClass to use for PVCs. Applied after
+	// evaluating the PVC template, if available.
+	// If not specified, the generated PVCs will use the
+	// default storage class
+	// +optional
+	StorageClass *string `json:"storageClass,omitempty"`
+
+	// Size of the storage. Required if not already specified in the PVC template.
+	// Changes to this field are automatically reapplied to the created PVCs.
+	// Size cannot be decreased.
+	// +optional
+	Size string `json:"size,omitempty"`
+}
+
+// LocalObjectReference contains enough information to let you locate a
+// local object with a known type inside the same namespace
+type LocalObjectReference struct {
+	// Name of the referent.
+	Name string `json:"name"`
+}
+
+// ClusterStatus defines the observed state of Cluster
+type ClusterStatus struct {
+	// The total number of PVC Groups detected in the cluster. It may differ from the number of existing instance pods.
+	// +optional
+	Instances int `json:"instances,omitempty"`
+
+	// The total number of ready instances in the cluster. It is equal to the number of ready instance pods.
+	// +optional
+	ReadyInstances int `json:"readyInstances,omitempty"`
+
+	// Current primary instance
+	// +optional
+	CurrentPrimary string `json:"currentPrimary,omitempty"`
+
+	// Current phase of the cluster
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Reason for the current phase
+	// +optional
+	PhaseReason string `json:"phaseReason,omitempty"`
+
+	// Current write pod
+	// +optional
+	WriteService string `json:"writeService,omitempty"`
+
+	// Current list of read pods
+	// +optional
+	ReadService string `json:"readService,omitempty"`
+}
+
+// Cluster is the Schema for the PostgreSQL API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterSpec   `json:"spec,omitempty"`
+	Status ClusterStatus `json:"status,omitempty"`
+}
+
+// ClusterList contains a list of Cluster
+// +kubebuilder:object:root=true
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
+}
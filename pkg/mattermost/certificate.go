@@ -0,0 +1,40 @@
+package mattermost
+
+import (
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GenerateCertificateV1Beta returns the cert-manager Certificate issuing the
+// TLS certificate of all Mattermost hosts.
+func GenerateCertificateV1Beta(mattermost *mmv1beta.Mattermost) *certmanagerv1.Certificate {
+	spec := &mmv1beta.CertManager{}
+	if mattermost.Spec.Ingress != nil && mattermost.Spec.Ingress.CertManager != nil {
+		spec = mattermost.Spec.Ingress.CertManager
+	}
+
+	issuerKind := spec.IssuerRef.Kind
+	if issuerKind == "" {
+		issuerKind = certmanagerv1.IssuerKind
+	}
+
+	return &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mattermost.Name,
+			Namespace:       mattermost.Namespace,
+			Labels:          mattermost.MattermostLabels(mattermost.Name),
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			DNSNames:   mattermost.GetIngressHostNames(),
+			Duration:   spec.Duration,
+			SecretName: mattermost.GetIngressTLSSecret(),
+			IssuerRef: certmanagerv1.ObjectReference{
+				Name:  spec.IssuerRef.Name,
+				Kind:  issuerKind,
+				Group: spec.IssuerRef.Group,
+			},
+		},
+	}
+}
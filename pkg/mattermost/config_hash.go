@@ -0,0 +1,120 @@
+package mattermost
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ConfigHashAnnotation is set on the pod template of Mattermost deployments
+// to the hash of the Secrets and ConfigMaps used by the pods, so that the pods
+// are restarted when their content changes.
+const ConfigHashAnnotation = "installation.mattermost.com/config-hash"
+
+// ReferencedConfig returns the sorted names of Secrets and ConfigMaps used by
+// the environment variables and volumes of the pod.
+func ReferencedConfig(podSpec *corev1.PodSpec) ([]string, []string) {
+	secrets := map[string]struct{}{}
+	configMaps := map[string]struct{}{}
+
+	containers := append([]corev1.Container{}, podSpec.InitContainers...)
+	containers = append(containers, podSpec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				secrets[env.ValueFrom.SecretKeyRef.Name] = struct{}{}
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				secrets[envFrom.SecretRef.Name] = struct{}{}
+			}
+			if envFrom.ConfigMapRef != nil {
+				configMaps[envFrom.ConfigMapRef.Name] = struct{}{}
+			}
+		}
+	}
+
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil {
+			secrets[volume.Secret.SecretName] = struct{}{}
+		}
+		if volume.ConfigMap != nil {
+			configMaps[volume.ConfigMap.Name] = struct{}{}
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					secrets[source.Secret.Name] = struct{}{}
+				}
+				if source.ConfigMap != nil {
+					configMaps[source.ConfigMap.Name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return sortedKeys(secrets), sortedKeys(configMaps)
+}
+
+// ConfigHash returns the hash of the content of the Secrets and ConfigMaps.
+func ConfigHash(secrets []corev1.Secret, configMaps []corev1.ConfigMap) string {
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	sort.Slice(configMaps, func(i, j int) bool { return configMaps[i].Name < configMaps[j].Name })
+
+	hash := sha256.New()
+	write := func(values ...string) {
+		for _, value := range values {
+			hash.Write([]byte(value))
+			hash.Write([]byte{0})
+		}
+	}
+
+	for _, secret := range secrets {
+		write("secret", secret.Name)
+		for _, key := range sortedKeys(secret.Data) {
+			write(key, string(secret.Data[key]))
+		}
+	}
+	for _, configMap := range configMaps {
+		write("configmap", configMap.Name)
+		for _, key := range sortedKeys(configMap.Data) {
+			write(key, configMap.Data[key])
+		}
+		for _, key := range sortedKeys(configMap.BinaryData) {
+			write(key, string(configMap.BinaryData[key]))
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SetConfigHash sets the config hash annotation on the pod template of the
+// deployment.
+func SetConfigHash(deployment *appsv1.Deployment, hash string) {
+	// Copy annotations as they may be shared with the Mattermost spec.
+	annotations := make(map[string]string, len(deployment.Spec.Template.Annotations)+1)
+	for k, v := range deployment.Spec.Template.Annotations {
+		annotations[k] = v
+	}
+	annotations[ConfigHashAnnotation] = hash
+	deployment.Spec.Template.Annotations = annotations
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
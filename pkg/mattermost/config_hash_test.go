@@ -0,0 +1,72 @@
+package mattermost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReferencedConfig(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Env: []corev1.EnvVar{{Name: "DB", ValueFrom: EnvSourceFromSecret("db", "url")}}},
+		},
+		Containers: []corev1.Container{
+			{
+				Env: []corev1.EnvVar{
+					{Name: "DB", ValueFrom: EnvSourceFromSecret("db", "url")},
+					{Name: "LITERAL", Value: "value"},
+					{Name: "SETTING", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "setting",
+					}}},
+				},
+				EnvFrom: []corev1.EnvFromSource{
+					{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "extra"}}},
+				},
+			},
+		},
+		Volumes: []corev1.Volume{
+			{Name: "license", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "license"}}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "config"},
+			}}},
+			{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		},
+	}
+
+	secrets, configMaps := ReferencedConfig(podSpec)
+	assert.Equal(t, []string{"db", "extra", "license"}, secrets)
+	assert.Equal(t, []string{"config", "settings"}, configMaps)
+}
+
+func TestConfigHash(t *testing.T) {
+	secret := func(name, value string) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string][]byte{"key": []byte(value)},
+		}
+	}
+	configMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Data:       map[string]string{"key": "value"},
+	}
+
+	hash := ConfigHash([]corev1.Secret{secret("a", "1"), secret("b", "2")}, []corev1.ConfigMap{configMap})
+	assert.Equal(t, hash, ConfigHash([]corev1.Secret{secret("b", "2"), secret("a", "1")}, []corev1.ConfigMap{configMap}))
+	assert.NotEqual(t, hash, ConfigHash([]corev1.Secret{secret("a", "1"), secret("b", "3")}, []corev1.ConfigMap{configMap}))
+	assert.NotEqual(t, hash, ConfigHash([]corev1.Secret{secret("a", "1"), secret("b", "2")}, nil))
+}
+
+func TestSetConfigHash(t *testing.T) {
+	annotations := map[string]string{"foo": "bar"}
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Annotations = annotations
+
+	SetConfigHash(deployment, "hash")
+
+	assert.Equal(t, map[string]string{"foo": "bar", ConfigHashAnnotation: "hash"}, deployment.Spec.Template.Annotations)
+	assert.Equal(t, map[string]string{"foo": "bar"}, annotations)
+}
@@ -0,0 +1,92 @@
+package mattermost
+
+import (
+	"errors"
+	"fmt"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/components/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type PostgresDBConfig struct {
+	secretName   string
+	userName     string
+	userPassword string
+	databaseName string
+}
+
+func NewPostgresDBConfig(secret corev1.Secret) (*PostgresDBConfig, error) {
+	userName := string(secret.Data[corev1.BasicAuthUsernameKey])
+	if userName == "" {
+		return nil, errors.New("database username shouldn't be empty")
+	}
+	userPassword := string(secret.Data[corev1.BasicAuthPasswordKey])
+	if userPassword == "" {
+		return nil, errors.New("database password shouldn't be empty")
+	}
+	databaseName := string(secret.Data["DATABASE"])
+	if databaseName == "" {
+		return nil, errors.New("database name shouldn't be empty")
+	}
+
+	return &PostgresDBConfig{
+		secretName:   secret.Name,
+		userName:     userName,
+		userPassword: userPassword,
+		databaseName: databaseName,
+	}, nil
+}
+
+func (p *PostgresDBConfig) EnvVars(mattermost *mmv1beta.Mattermost) []corev1.EnvVar {
+	postgresName := utils.HashWithPrefix("db", mattermost.Name)
+
+	dbEnvVars := []corev1.EnvVar{
+		{
+			Name:      "POSTGRES_USERNAME",
+			ValueFrom: EnvSourceFromSecret(p.secretName, corev1.BasicAuthUsernameKey),
+		},
+		{
+			Name:      "POSTGRES_PASSWORD",
+			ValueFrom: EnvSourceFromSecret(p.secretName, corev1.BasicAuthPasswordKey),
+		},
+		{
+			Name: "MM_SQLSETTINGS_DATASOURCEREPLICAS",
+			Value: fmt.Sprintf(
+				"postgres://$(POSTGRES_USERNAME):$(POSTGRES_PASSWORD)@%s-ro.%s.svc.cluster.local:5432/%s?sslmode=require&connect_timeout=10",
+				postgresName, mattermost.Namespace, p.databaseName,
+			),
+		},
+		{
+			Name: "MM_CONFIG",
+			Value: fmt.Sprintf(
+				"postgres://$(POSTGRES_USERNAME):$(POSTGRES_PASSWORD)@%s-rw.%s.svc.cluster.local:5432/%s?sslmode=require&connect_timeout=10",
+				postgresName, mattermost.Namespace, p.databaseName,
+			),
+		},
+	}
+
+	return dbEnvVars
+}
+
+func (p *PostgresDBConfig) InitContainers(mattermost *mmv1beta.Mattermost) []corev1.Container {
+	if mattermost.Spec.Database.DisableReadinessCheck {
+		return nil
+	}
+
+	postgresName := utils.HashWithPrefix("db", mattermost.Name)
+
+	return []corev1.Container{
+		{
+			Name:            "init-check-operator-postgres",
+			Image:           mattermost.Spec.Database.GetReadinessCheckImage(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command: []string{
+				"sh", "-c",
+				fmt.Sprintf("until pg_isready --host=%s-rw.%s.svc.cluster.local --port=5432; do echo waiting for postgres; sleep 5; done;",
+					postgresName, mattermost.Namespace,
+				),
+			},
+		},
+	}
+}
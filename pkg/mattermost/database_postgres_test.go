@@ -0,0 +1,106 @@
+package mattermost
+
+import (
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewPostgresDB(t *testing.T) {
+	mattermost := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{Name: "mm-test"},
+		Spec: mmv1beta.MattermostSpec{
+			Database: mmv1beta.Database{
+				OperatorManaged: &mmv1beta.OperatorManagedDatabase{Version: "16"},
+			},
+		},
+	}
+
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret"},
+		Data: map[string][]byte{
+			"username": []byte("user"),
+			"password": []byte("pass"),
+			"DATABASE": []byte("db"),
+		},
+	}
+
+	t.Run("create config", func(t *testing.T) {
+		config, err := NewPostgresDBConfig(secret)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", config.secretName)
+		assert.Equal(t, "user", config.userName)
+		assert.Equal(t, "pass", config.userPassword)
+		assert.Equal(t, "db", config.databaseName)
+
+		envs := config.EnvVars(mattermost)
+		assert.Equal(t, 4, len(envs))
+		assert.Equal(t, "MM_CONFIG", envs[3].Name)
+		assert.Contains(t, envs[3].Value, "-rw.")
+		assert.Contains(t, envs[3].Value, "/db?")
+
+		initContainers := config.InitContainers(mattermost)
+		assert.Equal(t, 1, len(initContainers))
+		assert.Equal(t, "ghcr.io/cloudnative-pg/postgresql:16", initContainers[0].Image)
+	})
+
+	t.Run("with custom readiness check image", func(t *testing.T) {
+		mattermost := mattermost.DeepCopy()
+		mattermost.Spec.Database.ReadinessCheck = &mmv1beta.DatabaseReadinessCheck{Image: "registry.local/postgresql:16.4"}
+		config, err := NewPostgresDBConfig(secret)
+		require.NoError(t, err)
+
+		initContainers := config.InitContainers(mattermost)
+		require.Equal(t, 1, len(initContainers))
+		assert.Equal(t, "registry.local/postgresql:16.4", initContainers[0].Image)
+	})
+
+	t.Run("with disabled DB readiness check", func(t *testing.T) {
+		mattermost.Spec.Database.DisableReadinessCheck = true
+		config, err := NewPostgresDBConfig(secret)
+		require.NoError(t, err)
+
+		initContainers := config.InitContainers(mattermost)
+		assert.Equal(t, 0, len(initContainers))
+	})
+
+	t.Run("should fail if missing key", func(t *testing.T) {
+		for _, testCase := range []struct {
+			description string
+			missingKey  string
+		}{
+			{
+				description: "user",
+				missingKey:  "username",
+			},
+			{
+				description: "pass",
+				missingKey:  "password",
+			},
+			{
+				description: "db",
+				missingKey:  "DATABASE",
+			},
+		} {
+			t.Run(testCase.description, func(t *testing.T) {
+				secret := corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "secret"},
+					Data: map[string][]byte{
+						"username": []byte("user"),
+						"password": []byte("pass"),
+						"DATABASE": []byte("db"),
+					},
+				}
+
+				delete(secret.Data, testCase.missingKey)
+
+				_, err := NewPostgresDBConfig(secret)
+				require.Error(t, err)
+			})
+		}
+	})
+}
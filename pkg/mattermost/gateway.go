@@ -0,0 +1,95 @@
+package mattermost
+
+import (
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultHTTPRouteRequestTimeout disables the request timeout of the Gateway,
+// as WebSocket connections to Mattermost are long lived.
+const defaultHTTPRouteRequestTimeout = "0s"
+
+// GenerateHTTPRouteV1Beta returns the Gateway API HTTPRoute for the
+// Mattermost app.
+func GenerateHTTPRouteV1Beta(mattermost *mmv1beta.Mattermost) *gatewayv1.HTTPRoute {
+	spec := mattermost.Spec.Gateway
+	if spec == nil {
+		spec = &mmv1beta.Gateway{}
+	}
+
+	gatewayGroup := gatewayv1.Group(gatewayv1.SchemeGroupVersion.Group)
+	gatewayKind := gatewayv1.Kind(gatewayv1.GatewayKind)
+	parentRefs := make([]gatewayv1.ParentReference, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := gatewayv1.ParentReference{
+			Group: &gatewayGroup,
+			Kind:  &gatewayKind,
+			Name:  gatewayv1.ObjectName(ref.Name),
+		}
+		if ref.Namespace != "" {
+			namespace := gatewayv1.Namespace(ref.Namespace)
+			parentRef.Namespace = &namespace
+		}
+		if ref.SectionName != "" {
+			sectionName := gatewayv1.SectionName(ref.SectionName)
+			parentRef.SectionName = &sectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	var hostnames []gatewayv1.Hostname
+	for _, host := range mattermost.GetIngressHostNames() {
+		hostnames = append(hostnames, gatewayv1.Hostname(host))
+	}
+
+	requestTimeout := gatewayv1.Duration(defaultHTTPRouteRequestTimeout)
+	if spec.RequestTimeout != "" {
+		requestTimeout = gatewayv1.Duration(spec.RequestTimeout)
+	}
+
+	pathType := gatewayv1.PathMatchPathPrefix
+	path := "/"
+	port := gatewayv1.PortNumber(mattermostAppPort)
+
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mattermost.Name,
+			Namespace:       mattermost.Namespace,
+			Labels:          mattermost.MattermostLabels(mattermost.Name),
+			Annotations:     spec.Annotations,
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: parentRefs,
+			},
+			Hostnames: hostnames,
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					Matches: []gatewayv1.HTTPRouteMatch{
+						{
+							Path: &gatewayv1.HTTPPathMatch{
+								Type:  &pathType,
+								Value: &path,
+							},
+						},
+					},
+					BackendRefs: []gatewayv1.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{
+									Name: gatewayv1.ObjectName(mattermost.Name),
+									Port: &port,
+								},
+							},
+						},
+					},
+					Timeouts: &gatewayv1.HTTPRouteTimeouts{
+						Request: &requestTimeout,
+					},
+				},
+			},
+		},
+	}
+}
@@ -323,11 +323,11 @@ func GenerateDeployment(mattermost *mattermostv1alpha1.ClusterInstallation, dbIn
 	volumeMountLicense := []corev1.VolumeMount{}
 	podAnnotations := map[string]string{}
 	if len(mattermost.Spec.MattermostLicenseSecret) != 0 {
-		env, vMount, volume, annotations := mattermostLicenceConfig(mattermost.Spec.MattermostLicenseSecret)
+		env, vMount, volume := mattermostLicenceConfig(mattermost.Spec.MattermostLicenseSecret)
 		envVarGeneral = append(envVarGeneral, env)
 		volumeMountLicense = append(volumeMountLicense, vMount)
 		volumeLicense = append(volumeLicense, volume)
-		podAnnotations = annotations
+		podAnnotations = prometheusAnnotations()
 	}
 
 	// EnvVars Section
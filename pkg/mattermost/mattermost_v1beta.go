@@ -13,8 +13,10 @@ import (
 
 	mattermostv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -22,7 +24,20 @@ import (
 )
 
 const (
+	// ElasticSearchSecretUsernameKey and ElasticSearchSecretPasswordKey are
+	// the keys of the ElasticSearch credentials secret.
+	ElasticSearchSecretUsernameKey = "username"
+	ElasticSearchSecretPasswordKey = "password"
+	// ElasticSearchSecretCAKey is the key of the CA bundle in the
+	// ElasticSearch CA secret.
+	ElasticSearchSecretCAKey = "ca.crt"
+
+	elasticSearchCAVolumeName = "elasticsearch-ca"
+	elasticSearchCAMountPath  = "/mattermost-elasticsearch-ca"
+
 	ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+	defaultTargetCPUUtilizationPercentage = 80
 )
 
 // sanitizeIngressAnnotations filters out annotations that could allow arbitrary
@@ -279,6 +294,106 @@ func makeIngressRules(hosts []string, mattermost *mmv1beta.Mattermost) []network
 	return rules
 }
 
+// GeneratePodDisruptionBudgetV1Beta returns the PodDisruptionBudget for the
+// pods of the deployment with the given name, selector and replicas.
+func GeneratePodDisruptionBudgetV1Beta(mattermost *mmv1beta.Mattermost, name string, selector map[string]string, replicas int32) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       mattermost.Namespace,
+			Labels:          mattermost.MattermostLabels(name),
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+		},
+	}
+
+	config := mattermost.Spec.PodDisruptionBudget
+	switch {
+	case config != nil && config.MaxUnavailable != nil:
+		pdb.Spec.MaxUnavailable = config.MaxUnavailable
+	case config != nil && config.MinAvailable != nil:
+		pdb.Spec.MinAvailable = config.MinAvailable
+	default:
+		minAvailable := intstr.FromInt32((replicas + 1) / 2)
+		pdb.Spec.MinAvailable = &minAvailable
+	}
+
+	return pdb
+}
+
+// GenerateHorizontalPodAutoscalerV1Beta returns the HorizontalPodAutoscaler
+// for the Mattermost deployment.
+func GenerateHorizontalPodAutoscalerV1Beta(mattermost *mmv1beta.Mattermost) *autoscalingv2.HorizontalPodAutoscaler {
+	minReplicas := mattermost.GetAutoscalingMinReplicas()
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mattermost.Name,
+			Namespace:       mattermost.Namespace,
+			Labels:          mattermost.MattermostLabels(mattermost.Name),
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       mattermost.Name,
+			},
+			MinReplicas: &minReplicas,
+		},
+	}
+
+	autoscaling := mattermost.Spec.Autoscaling
+	if autoscaling == nil {
+		return hpa
+	}
+	hpa.Spec.MaxReplicas = autoscaling.MaxReplicas
+
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceUtilizationMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceUtilizationMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+	if autoscaling.CustomMetric != nil {
+		targetValue := autoscaling.CustomMetric.TargetAverageValue
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{
+					Name: autoscaling.CustomMetric.Name,
+				},
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: &targetValue,
+				},
+			},
+		})
+	}
+	if len(hpa.Spec.Metrics) == 0 {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceUtilizationMetric(corev1.ResourceCPU, defaultTargetCPUUtilizationPercentage))
+	}
+
+	return hpa
+}
+
+func resourceUtilizationMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
 // GenerateDeploymentV1Beta returns the deployment for Mattermost app.
 func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig, fileStore FileStoreConfig, deploymentName, ingressHost, serviceAccountName, containerImage string) *appsv1.Deployment {
 	// DB
@@ -295,6 +410,13 @@ func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig
 	volumes = append(volumes, fsVolumes...)
 	volumeMounts = append(volumeMounts, fsVmounts...)
 	initContainers = append(initContainers, fileStore.InitContainers(mattermost)...)
+
+	// Plugins
+	pluginInitContainers, envVarPlugins, pluginVmounts, pluginVolumes := pluginsConfigV1Beta(mattermost)
+	initContainers = append(initContainers, pluginInitContainers...)
+	volumes = append(volumes, pluginVolumes...)
+	volumeMounts = append(volumeMounts, pluginVmounts...)
+
 	containerPorts := []corev1.ContainerPort{
 		{
 			ContainerPort: 8065,
@@ -322,11 +444,11 @@ func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig
 	// ES section vars
 	envVarES := []corev1.EnvVar{}
 	if mattermost.Spec.ElasticSearch.Host != "" {
-		envVarES = elasticSearchEnvVars(
-			mattermost.Spec.ElasticSearch.Host,
-			mattermost.Spec.ElasticSearch.UserName,
-			mattermost.Spec.ElasticSearch.Password,
-		)
+		var esVolumeMounts []corev1.VolumeMount
+		var esVolumes []corev1.Volume
+		envVarES, esVolumeMounts, esVolumes = elasticSearchConfigV1Beta(mattermost.Spec.ElasticSearch)
+		volumeMounts = append(volumeMounts, esVolumeMounts...)
+		volumes = append(volumes, esVolumes...)
 	}
 
 	// General settings
@@ -342,6 +464,13 @@ func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig
 		Value: bodySize,
 	})
 
+	if !mattermost.MetricsEnabled() {
+		envVarGeneral = mergeEnvVars(envVarGeneral, []corev1.EnvVar{{
+			Name:  "MM_METRICSSETTINGS_ENABLE",
+			Value: "false",
+		}})
+	}
+
 	// Apply optional job server settings
 	if mattermost.Spec.JobServer != nil && mattermost.Spec.JobServer.DedicatedJobServer {
 		envVarGeneral = append(envVarGeneral, corev1.EnvVar{
@@ -360,12 +489,15 @@ func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig
 
 	// Mattermost License
 	if len(mattermost.Spec.LicenseSecret) != 0 {
-		env, vMount, volume, annotations := mattermostLicenceConfig(mattermost.Spec.LicenseSecret)
+		env, vMount, volume := mattermostLicenceConfig(mattermost.Spec.LicenseSecret)
 		envVarGeneral = append(envVarGeneral, env)
 		volumeMounts = append(volumeMounts, vMount)
 		volumes = append(volumes, volume)
-		// Add prometheus annotations, overwriting user specified if needed
-		for k, v := range annotations {
+	}
+
+	// Add prometheus annotations, overwriting user specified if needed
+	if mattermost.MetricsEnabled() {
+		for k, v := range prometheusAnnotations() {
 			podAnnotations[k] = v
 		}
 	}
@@ -376,6 +508,7 @@ func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig
 	envVars = append(envVars, envVarFileStore...)
 	envVars = append(envVars, envVarES...)
 	envVars = append(envVars, envVarGeneral...)
+	envVars = append(envVars, envVarPlugins...)
 
 	// Merge our custom env vars in.
 	envVars = mergeEnvVars(envVars, mattermost.Spec.MattermostEnv)
@@ -435,6 +568,7 @@ func GenerateDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db DatabaseConfig
 			Ports:                    containerPorts,
 			ReadinessProbe:           readiness,
 			LivenessProbe:            liveness,
+			Lifecycle:                pluginSettingsLifecycleV1Beta(mattermost),
 			VolumeMounts:             volumeMounts,
 			Resources:                mattermost.Spec.Scheduling.Resources,
 			SecurityContext:          containerSecurityContext,
@@ -492,7 +626,7 @@ func GenerateJobServerDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db Datab
 		mattermost.Name,
 		mattermost.GetIngressHost(),
 		mattermost.Name,
-		mattermost.GetImageName(),
+		containerImage,
 	)
 
 	// Apply metadata overrides for dedicated job server configuration.
@@ -510,6 +644,8 @@ func GenerateJobServerDeploymentV1Beta(mattermost *mmv1beta.Mattermost, db Datab
 	mattermostContainer.Ports = nil
 	mattermostContainer.LivenessProbe = nil
 	mattermostContainer.ReadinessProbe = nil
+	// Plugin settings are applied by the Mattermost pods.
+	mattermostContainer.Lifecycle = nil
 
 	deployment.Spec.Template.Spec.Containers = []corev1.Container{mattermostContainer}
 
@@ -606,7 +742,7 @@ func newServiceV1Beta(mattermost *mmv1beta.Mattermost, annotations map[string]st
 	}
 }
 
-func mattermostLicenceConfig(secret string) (corev1.EnvVar, corev1.VolumeMount, corev1.Volume, map[string]string) {
+func mattermostLicenceConfig(secret string) (corev1.EnvVar, corev1.VolumeMount, corev1.Volume) {
 	envVar := corev1.EnvVar{
 		Name:  "MM_SERVICESETTINGS_LICENSEFILELOCATION",
 		Value: "/mattermost-license/license",
@@ -624,10 +760,80 @@ func mattermostLicenceConfig(secret string) (corev1.EnvVar, corev1.VolumeMount,
 			},
 		},
 	}
-	annotations := map[string]string{
+	return envVar, volumeMount, volume
+}
+
+// elasticSearchConfigV1Beta returns the environment variables configuring
+// ElasticSearch, and the volume of the CA bundle if it is specified. The
+// credentials are read from the secret if it is specified, otherwise the
+// deprecated inline credentials are used.
+func elasticSearchConfigV1Beta(es mmv1beta.ElasticSearch) ([]corev1.EnvVar, []corev1.VolumeMount, []corev1.Volume) {
+	var envVars []corev1.EnvVar
+	if es.Secret != "" {
+		envVars = mergeEnvVars(elasticSearchEnvVars(es.Host, "", ""), []corev1.EnvVar{
+			{
+				Name:      "MM_ELASTICSEARCHSETTINGS_USERNAME",
+				ValueFrom: EnvSourceFromSecret(es.Secret, ElasticSearchSecretUsernameKey),
+			},
+			{
+				Name:      "MM_ELASTICSEARCHSETTINGS_PASSWORD",
+				ValueFrom: EnvSourceFromSecret(es.Secret, ElasticSearchSecretPasswordKey),
+			},
+		})
+	} else {
+		envVars = elasticSearchEnvVars(es.Host, es.UserName, es.Password)
+	}
+
+	if es.Backend != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "MM_ELASTICSEARCHSETTINGS_BACKEND", Value: string(es.Backend)})
+	}
+	if es.IndexPrefix != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "MM_ELASTICSEARCHSETTINGS_INDEXPREFIX", Value: es.IndexPrefix})
+	}
+	for _, index := range []string{"POST", "CHANNEL", "USER"} {
+		if es.Shards != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:  fmt.Sprintf("MM_ELASTICSEARCHSETTINGS_%sINDEXSHARDS", index),
+				Value: strconv.Itoa(int(*es.Shards)),
+			})
+		}
+		if es.Replicas != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:  fmt.Sprintf("MM_ELASTICSEARCHSETTINGS_%sINDEXREPLICAS", index),
+				Value: strconv.Itoa(int(*es.Replicas)),
+			})
+		}
+	}
+
+	if es.CASecret == "" {
+		return envVars, nil, nil
+	}
+
+	envVars = append(envVars, corev1.EnvVar{
+		Name:  "MM_ELASTICSEARCHSETTINGS_CA",
+		Value: elasticSearchCAMountPath + "/" + ElasticSearchSecretCAKey,
+	})
+	volumeMount := corev1.VolumeMount{
+		MountPath: elasticSearchCAMountPath,
+		Name:      elasticSearchCAVolumeName,
+		ReadOnly:  true,
+	}
+	volume := corev1.Volume{
+		Name: elasticSearchCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: es.CASecret,
+				Items:      []corev1.KeyToPath{{Key: ElasticSearchSecretCAKey, Path: ElasticSearchSecretCAKey}},
+			},
+		},
+	}
+	return envVars, []corev1.VolumeMount{volumeMount}, []corev1.Volume{volume}
+}
+
+func prometheusAnnotations() map[string]string {
+	return map[string]string{
 		"prometheus.io/scrape": "true",
 		"prometheus.io/path":   "/metrics",
 		"prometheus.io/port":   "8067",
 	}
-	return envVar, volumeMount, volume, annotations
 }
@@ -3,14 +3,21 @@ package mattermost
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
 	"github.com/mattermost/mattermost-operator/pkg/database"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
 	"github.com/mattermost/mattermost-operator/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -389,6 +396,407 @@ func TestGenerateJobServerDeployment_V1Beta(t *testing.T) {
 	assert.Equal(t, []string{"mattermost", "jobserver"}, jobServerdeployment.Spec.Template.Spec.Containers[0].Command)
 }
 
+func TestGeneratePodDisruptionBudget_V1Beta(t *testing.T) {
+	minAvailable := intstr.FromInt32(3)
+	maxUnavailable := intstr.FromString("25%")
+
+	tests := []struct {
+		name                   string
+		replicas               int32
+		spec                   *mmv1beta.PodDisruptionBudget
+		expectedMinAvailable   *intstr.IntOrString
+		expectedMaxUnavailable *intstr.IntOrString
+	}{
+		{
+			name:                 "default for even replicas",
+			replicas:             4,
+			expectedMinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+		},
+		{
+			name:                 "default for odd replicas",
+			replicas:             3,
+			expectedMinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+		},
+		{
+			name:                 "min available",
+			replicas:             4,
+			spec:                 &mmv1beta.PodDisruptionBudget{MinAvailable: &minAvailable},
+			expectedMinAvailable: &minAvailable,
+		},
+		{
+			name:                   "max unavailable",
+			replicas:               4,
+			spec:                   &mmv1beta.PodDisruptionBudget{MaxUnavailable: &maxUnavailable},
+			expectedMaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mattermost := &mmv1beta.Mattermost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "namespace",
+				},
+				Spec: mmv1beta.MattermostSpec{
+					Replicas:            &tt.replicas,
+					PodDisruptionBudget: tt.spec,
+				},
+			}
+
+			pdb := GeneratePodDisruptionBudgetV1Beta(mattermost, mattermost.Name, mmv1beta.MattermostSelectorLabels(mattermost.Name), tt.replicas)
+			require.NotNil(t, pdb)
+
+			assert.Equal(t, "test", pdb.Name)
+			assert.Equal(t, "namespace", pdb.Namespace)
+			assert.Equal(t, mmv1beta.MattermostSelectorLabels(mattermost.Name), pdb.Spec.Selector.MatchLabels)
+			assert.Equal(t, tt.expectedMinAvailable, pdb.Spec.MinAvailable)
+			assert.Equal(t, tt.expectedMaxUnavailable, pdb.Spec.MaxUnavailable)
+		})
+	}
+}
+
+func TestGenerateHorizontalPodAutoscaler_V1Beta(t *testing.T) {
+	replicas := int32(3)
+	minReplicas := int32(2)
+	cpu := int32(60)
+	memory := int32(70)
+	websockets := resource.MustParse("500")
+
+	cpuMetric := func(utilization int32) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &utilization},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		autoscaling         *mmv1beta.Autoscaling
+		expectedMinReplicas int32
+		expectedMetrics     []autoscalingv2.MetricSpec
+	}{
+		{
+			name:                "default cpu target",
+			autoscaling:         &mmv1beta.Autoscaling{Enabled: true, MaxReplicas: 10},
+			expectedMinReplicas: replicas,
+			expectedMetrics:     []autoscalingv2.MetricSpec{cpuMetric(80)},
+		},
+		{
+			name:                "cpu and memory targets",
+			autoscaling:         &mmv1beta.Autoscaling{Enabled: true, MinReplicas: &minReplicas, MaxReplicas: 10, TargetCPUUtilizationPercentage: &cpu, TargetMemoryUtilizationPercentage: &memory},
+			expectedMinReplicas: minReplicas,
+			expectedMetrics: []autoscalingv2.MetricSpec{
+				cpuMetric(cpu),
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   corev1.ResourceMemory,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &memory},
+					},
+				},
+			},
+		},
+		{
+			name: "custom metric",
+			autoscaling: &mmv1beta.Autoscaling{Enabled: true, MaxReplicas: 10, CustomMetric: &mmv1beta.AutoscalingMetric{
+				Name:               "mattermost_http_websockets_total",
+				TargetAverageValue: websockets,
+			}},
+			expectedMinReplicas: replicas,
+			expectedMetrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.PodsMetricSourceType,
+					Pods: &autoscalingv2.PodsMetricSource{
+						Metric: autoscalingv2.MetricIdentifier{Name: "mattermost_http_websockets_total"},
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &websockets},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mattermost := &mmv1beta.Mattermost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "namespace",
+				},
+				Spec: mmv1beta.MattermostSpec{
+					Replicas:    &replicas,
+					Autoscaling: tt.autoscaling,
+				},
+			}
+
+			hpa := GenerateHorizontalPodAutoscalerV1Beta(mattermost)
+			require.NotNil(t, hpa)
+
+			assert.Equal(t, "test", hpa.Name)
+			assert.Equal(t, "namespace", hpa.Namespace)
+			assert.Equal(t, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "test"}, hpa.Spec.ScaleTargetRef)
+			assert.Equal(t, tt.expectedMinReplicas, *hpa.Spec.MinReplicas)
+			assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+			assert.Equal(t, tt.expectedMetrics, hpa.Spec.Metrics)
+		})
+	}
+}
+
+func TestGenerateNetworkPolicy_V1Beta(t *testing.T) {
+	newMattermost := func() *mmv1beta.Mattermost {
+		return &mmv1beta.Mattermost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "namespace",
+			},
+			Spec: mmv1beta.MattermostSpec{
+				NetworkPolicy: &mmv1beta.NetworkPolicy{Enabled: true},
+				Database: mmv1beta.Database{
+					OperatorManaged: &mmv1beta.OperatorManagedDatabase{Type: mmv1beta.DatabaseTypeMySQL},
+				},
+			},
+		}
+	}
+
+	egressPeers := func(networkPolicy *networkingv1.NetworkPolicy) []networkingv1.NetworkPolicyPeer {
+		var peers []networkingv1.NetworkPolicyPeer
+		for _, rule := range networkPolicy.Spec.Egress {
+			peers = append(peers, rule.To...)
+		}
+		return peers
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		mattermost := newMattermost()
+
+		networkPolicy := GenerateNetworkPolicyV1Beta(mattermost)
+		require.NotNil(t, networkPolicy)
+
+		assert.Equal(t, "test", networkPolicy.Name)
+		assert.Equal(t, "namespace", networkPolicy.Namespace)
+		assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, networkPolicy.Spec.PolicyTypes)
+		require.Len(t, networkPolicy.Spec.PodSelector.MatchExpressions, 1)
+		assert.Equal(t, []string{"test", "test-jobserver", "test-canary"}, networkPolicy.Spec.PodSelector.MatchExpressions[0].Values)
+
+		require.Len(t, networkPolicy.Spec.Ingress, 3)
+		assert.Equal(t, int32(8065), networkPolicy.Spec.Ingress[0].Ports[0].Port.IntVal)
+		assert.Equal(t, map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"}, networkPolicy.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels)
+		assert.Equal(t, int32(8067), networkPolicy.Spec.Ingress[1].Ports[0].Port.IntVal)
+		assert.Equal(t, map[string]string{"kubernetes.io/metadata.name": "monitoring"}, networkPolicy.Spec.Ingress[1].From[0].NamespaceSelector.MatchLabels)
+		assert.Equal(t, &networkPolicy.Spec.PodSelector, networkPolicy.Spec.Ingress[2].From[0].PodSelector)
+
+		peers := egressPeers(networkPolicy)
+		require.Len(t, peers, 3)
+		assert.Equal(t, &networkPolicy.Spec.PodSelector, peers[0].PodSelector)
+		assert.Equal(t, mmv1beta.MySQLLabels(), peers[1].PodSelector.MatchLabels)
+		assert.Equal(t, map[string]string{"v1beta1.min.io/instance": "test-minio"}, peers[2].PodSelector.MatchLabels)
+	})
+
+	t.Run("custom selectors and external dependencies", func(t *testing.T) {
+		mattermost := newMattermost()
+		ingressPods := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "traefik"}}
+		mattermost.Spec.NetworkPolicy.IngressPodSelector = ingressPods
+		mattermost.Spec.NetworkPolicy.EgressCIDRs = []string{"10.0.0.0/16", "10.1.0.0/16"}
+		mattermost.Spec.Database.External = &mmv1beta.ExternalDatabase{Secret: "db"}
+		mattermost.Spec.FileStore.External = &mmv1beta.ExternalFileStore{URL: "s3.amazonaws.com", Bucket: "bucket", Secret: "secret"}
+
+		networkPolicy := GenerateNetworkPolicyV1Beta(mattermost)
+		require.NotNil(t, networkPolicy)
+
+		assert.Nil(t, networkPolicy.Spec.Ingress[0].From[0].NamespaceSelector)
+		assert.Equal(t, ingressPods, networkPolicy.Spec.Ingress[0].From[0].PodSelector)
+
+		peers := egressPeers(networkPolicy)
+		require.Len(t, peers, 3)
+		assert.Equal(t, &networkingv1.IPBlock{CIDR: "10.0.0.0/16"}, peers[1].IPBlock)
+		assert.Equal(t, &networkingv1.IPBlock{CIDR: "10.1.0.0/16"}, peers[2].IPBlock)
+	})
+
+	t.Run("additional egress", func(t *testing.T) {
+		mattermost := newMattermost()
+		httpsPort := intstr.FromInt32(443)
+		httpsRule := networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{{Port: &httpsPort}},
+		}
+		mattermost.Spec.NetworkPolicy.AdditionalEgress = []networkingv1.NetworkPolicyEgressRule{httpsRule}
+
+		networkPolicy := GenerateNetworkPolicyV1Beta(mattermost)
+		require.NotNil(t, networkPolicy)
+
+		egress := networkPolicy.Spec.Egress
+		assert.Equal(t, httpsRule, egress[len(egress)-1])
+	})
+}
+
+func TestGenerateMonitors_V1Beta(t *testing.T) {
+	separator := ";"
+	mattermost := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "namespace",
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Monitoring: &mmv1beta.Monitoring{
+				ServiceMonitor: &mmv1beta.ServiceMonitor{
+					Enabled:  true,
+					Labels:   map[string]string{"release": "prometheus"},
+					Interval: "30s",
+					Relabelings: []mmv1beta.RelabelConfig{
+						{SourceLabels: []string{"__meta_kubernetes_pod_node_name"}, Separator: &separator, TargetLabel: "node", Action: "replace"},
+					},
+				},
+			},
+		},
+	}
+	expectedRelabelings := []monitoringv1.RelabelConfig{
+		{SourceLabels: []string{"__meta_kubernetes_pod_node_name"}, Separator: &separator, TargetLabel: "node", Action: "replace"},
+	}
+
+	t.Run("service monitor", func(t *testing.T) {
+		serviceMonitor := GenerateServiceMonitorV1Beta(mattermost)
+		require.NotNil(t, serviceMonitor)
+
+		assert.Equal(t, "test", serviceMonitor.Name)
+		assert.Equal(t, "namespace", serviceMonitor.Namespace)
+		assert.Equal(t, "prometheus", serviceMonitor.Labels["release"])
+		assert.Equal(t, mmv1beta.MattermostSelectorLabels("test"), serviceMonitor.Spec.Selector.MatchLabels)
+		require.Len(t, serviceMonitor.Spec.Endpoints, 1)
+		assert.Equal(t, monitoringv1.Endpoint{
+			Port:           "metrics",
+			Path:           "/metrics",
+			Interval:       "30s",
+			RelabelConfigs: expectedRelabelings,
+		}, serviceMonitor.Spec.Endpoints[0])
+	})
+
+	t.Run("job server pod monitor", func(t *testing.T) {
+		podMonitor := GenerateJobServerPodMonitorV1Beta(mattermost)
+		require.NotNil(t, podMonitor)
+
+		assert.Equal(t, "test-jobserver", podMonitor.Name)
+		assert.Equal(t, "namespace", podMonitor.Namespace)
+		assert.Equal(t, "prometheus", podMonitor.Labels["release"])
+		assert.Equal(t, map[string]string{
+			mmv1beta.ClusterLabel: "test",
+			"app":                 mmv1beta.MattermostJobServerContainerName,
+		}, podMonitor.Spec.Selector.MatchLabels)
+		require.Len(t, podMonitor.Spec.PodMetricsEndpoints, 1)
+		assert.Equal(t, monitoringv1.PodMetricsEndpoint{
+			Port:           "metrics",
+			Path:           "/metrics",
+			Interval:       "30s",
+			RelabelConfigs: expectedRelabelings,
+		}, podMonitor.Spec.PodMetricsEndpoints[0])
+	})
+}
+
+func TestGenerateHTTPRoute_V1Beta(t *testing.T) {
+	mattermost := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "namespace",
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Ingress: &mmv1beta.Ingress{
+				Host:  "chat.example.com",
+				Hosts: []mmv1beta.IngressHost{{HostName: "chat.example.com"}, {HostName: "mattermost.example.com"}},
+			},
+			Gateway: &mmv1beta.Gateway{
+				Enabled: true,
+				ParentRefs: []mmv1beta.GatewayParentReference{
+					{Name: "public"},
+					{Name: "internal", Namespace: "gateways", SectionName: "https"},
+				},
+				Annotations: map[string]string{"example.com/annotation": "value"},
+			},
+		},
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		route := GenerateHTTPRouteV1Beta(mattermost)
+		require.NotNil(t, route)
+
+		assert.Equal(t, "test", route.Name)
+		assert.Equal(t, "namespace", route.Namespace)
+		assert.Equal(t, map[string]string{"example.com/annotation": "value"}, route.Annotations)
+		assert.Equal(t, []gatewayv1.Hostname{"chat.example.com", "mattermost.example.com"}, route.Spec.Hostnames)
+
+		require.Len(t, route.Spec.ParentRefs, 2)
+		assert.Equal(t, gatewayv1.ObjectName("public"), route.Spec.ParentRefs[0].Name)
+		assert.Equal(t, gatewayv1.Kind("Gateway"), *route.Spec.ParentRefs[0].Kind)
+		assert.Nil(t, route.Spec.ParentRefs[0].Namespace)
+		assert.Nil(t, route.Spec.ParentRefs[0].SectionName)
+		assert.Equal(t, gatewayv1.Namespace("gateways"), *route.Spec.ParentRefs[1].Namespace)
+		assert.Equal(t, gatewayv1.SectionName("https"), *route.Spec.ParentRefs[1].SectionName)
+
+		require.Len(t, route.Spec.Rules, 1)
+		rule := route.Spec.Rules[0]
+		require.Len(t, rule.BackendRefs, 1)
+		assert.Equal(t, gatewayv1.ObjectName("test"), rule.BackendRefs[0].Name)
+		assert.Equal(t, gatewayv1.PortNumber(8065), *rule.BackendRefs[0].Port)
+		assert.Equal(t, gatewayv1.Duration("0s"), *rule.Timeouts.Request)
+	})
+
+	t.Run("custom request timeout", func(t *testing.T) {
+		mattermost.Spec.Gateway.RequestTimeout = "1h"
+
+		route := GenerateHTTPRouteV1Beta(mattermost)
+		require.NotNil(t, route)
+
+		assert.Equal(t, gatewayv1.Duration("1h"), *route.Spec.Rules[0].Timeouts.Request)
+	})
+}
+
+func TestGenerateCertificate_V1Beta(t *testing.T) {
+	mattermost := &mmv1beta.Mattermost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "namespace",
+		},
+		Spec: mmv1beta.MattermostSpec{
+			Ingress: &mmv1beta.Ingress{
+				Enabled: true,
+				Host:    "chat.example.com",
+				Hosts:   []mmv1beta.IngressHost{{HostName: "mattermost.example.com"}},
+				CertManager: &mmv1beta.CertManager{
+					IssuerRef: mmv1beta.CertManagerIssuerReference{Name: "letsencrypt"},
+					Duration:  &metav1.Duration{Duration: 2160 * time.Hour},
+				},
+			},
+		},
+	}
+
+	t.Run("default secret", func(t *testing.T) {
+		certificate := GenerateCertificateV1Beta(mattermost)
+		require.NotNil(t, certificate)
+
+		assert.Equal(t, "test", certificate.Name)
+		assert.Equal(t, "namespace", certificate.Namespace)
+		assert.Equal(t, []string{"chat.example.com", "mattermost.example.com"}, certificate.Spec.DNSNames)
+		assert.Equal(t, "chat-example-com-tls-cert", certificate.Spec.SecretName)
+		assert.Equal(t, certmanagerv1.ObjectReference{Name: "letsencrypt", Kind: "Issuer"}, certificate.Spec.IssuerRef)
+		assert.Equal(t, 2160*time.Hour, certificate.Spec.Duration.Duration)
+
+		ingress := GenerateIngressV1Beta(mattermost, logr.Discard())
+		require.Len(t, ingress.Spec.TLS, 1)
+		assert.Equal(t, "chat-example-com-tls-cert", ingress.Spec.TLS[0].SecretName)
+	})
+
+	t.Run("custom secret and cluster issuer", func(t *testing.T) {
+		mattermost.Spec.Ingress.TLSSecret = "mattermost-tls"
+		mattermost.Spec.Ingress.CertManager.IssuerRef.Kind = "ClusterIssuer"
+
+		certificate := GenerateCertificateV1Beta(mattermost)
+		require.NotNil(t, certificate)
+
+		assert.Equal(t, "mattermost-tls", certificate.Spec.SecretName)
+		assert.Equal(t, "ClusterIssuer", certificate.Spec.IssuerRef.Kind)
+	})
+}
+
 func TestGenerateDeployment_V1Beta(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -723,6 +1131,53 @@ func TestGenerateDeployment_V1Beta(t *testing.T) {
 				"MM_ELASTICSEARCHSETTINGS_PASSWORD":        "password",
 			},
 		},
+		{
+			name: "opensearch with secrets",
+			spec: mmv1beta.MattermostSpec{
+				ElasticSearch: mmv1beta.ElasticSearch{
+					Host:        "https://opensearch",
+					Password:    "ignored",
+					Secret:      "opensearch-credentials",
+					CASecret:    "opensearch-ca",
+					Backend:     mmv1beta.ElasticSearchBackendOpenSearch,
+					IndexPrefix: "mm-",
+					Shards:      utils.NewInt32(3),
+					Replicas:    utils.NewInt32(0),
+				},
+			},
+			want: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Volumes: []corev1.Volume{
+								{
+									Name: "elasticsearch-ca",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "opensearch-ca",
+											Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			requiredEnv: []string{"MM_ELASTICSEARCHSETTINGS_USERNAME", "MM_ELASTICSEARCHSETTINGS_PASSWORD"},
+			requiredEnvVals: map[string]string{
+				"MM_ELASTICSEARCHSETTINGS_CONNECTIONURL":        "https://opensearch",
+				"MM_ELASTICSEARCHSETTINGS_BACKEND":              "opensearch",
+				"MM_ELASTICSEARCHSETTINGS_INDEXPREFIX":          "mm-",
+				"MM_ELASTICSEARCHSETTINGS_POSTINDEXSHARDS":      "3",
+				"MM_ELASTICSEARCHSETTINGS_CHANNELINDEXSHARDS":   "3",
+				"MM_ELASTICSEARCHSETTINGS_USERINDEXSHARDS":      "3",
+				"MM_ELASTICSEARCHSETTINGS_POSTINDEXREPLICAS":    "0",
+				"MM_ELASTICSEARCHSETTINGS_CHANNELINDEXREPLICAS": "0",
+				"MM_ELASTICSEARCHSETTINGS_USERINDEXREPLICAS":    "0",
+				"MM_ELASTICSEARCHSETTINGS_CA":                   "/mattermost-elasticsearch-ca/ca.crt",
+			},
+		},
 		{
 			name: "precedence order of labels",
 			spec: mmv1beta.MattermostSpec{
@@ -753,6 +1208,31 @@ func TestGenerateDeployment_V1Beta(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "metrics without license",
+			spec: mmv1beta.MattermostSpec{},
+			want: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								"prometheus.io/scrape": "true",
+								"prometheus.io/port":   "8067",
+							},
+						},
+					},
+				},
+			},
+			requiredEnvVals: map[string]string{"MM_METRICSSETTINGS_ENABLE": "true"},
+		},
+		{
+			name: "metrics disabled",
+			spec: mmv1beta.MattermostSpec{
+				Monitoring: &mmv1beta.Monitoring{MetricsDisabled: true},
+			},
+			want:            &appsv1.Deployment{},
+			requiredEnvVals: map[string]string{"MM_METRICSSETTINGS_ENABLE": "false"},
+		},
 		{
 			name: "precedence order of annotations",
 			spec: mmv1beta.MattermostSpec{
@@ -1134,6 +1614,32 @@ func TestGenerateDeployment_V1Beta(t *testing.T) {
 		}
 	})
 
+	t.Run("elasticsearch credentials from secret", func(t *testing.T) {
+		mattermost := &mmv1beta.Mattermost{
+			Spec: mmv1beta.MattermostSpec{
+				ElasticSearch: mmv1beta.ElasticSearch{
+					Host:     "http://elastic",
+					Password: "password",
+					Secret:   "elastic-credentials",
+				},
+			},
+		}
+		dbCfg := &ExternalDBConfig{dbType: database.PostgreSQLDatabase}
+
+		deployment := GenerateDeploymentV1Beta(mattermost, dbCfg, &ExternalFileStore{}, "", "", "", "")
+		mattermostAppContainer := mmv1beta.GetMattermostAppContainer(deployment.Spec.Template.Spec.Containers)
+
+		for _, env := range mattermostAppContainer.Env {
+			switch env.Name {
+			case "MM_ELASTICSEARCHSETTINGS_USERNAME":
+				assert.Equal(t, EnvSourceFromSecret("elastic-credentials", "username"), env.ValueFrom)
+			case "MM_ELASTICSEARCHSETTINGS_PASSWORD":
+				assert.Empty(t, env.Value)
+				assert.Equal(t, EnvSourceFromSecret("elastic-credentials", "password"), env.ValueFrom)
+			}
+		}
+	})
+
 	t.Run("should set SiteURL env if ingress host provided", func(t *testing.T) {
 		mattermost := &mmv1beta.Mattermost{
 			Spec: mmv1beta.MattermostSpec{},
@@ -0,0 +1,111 @@
+package mattermost
+
+import (
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	metricsPortName = "metrics"
+	metricsPath     = "/metrics"
+)
+
+// GenerateServiceMonitorV1Beta returns the Prometheus Operator ServiceMonitor
+// scraping metrics of the Mattermost pods through the Mattermost service.
+func GenerateServiceMonitorV1Beta(mattermost *mmv1beta.Mattermost) *monitoringv1.ServiceMonitor {
+	spec := serviceMonitorSpec(mattermost)
+
+	return &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mattermost.Name,
+			Namespace:       mattermost.Namespace,
+			Labels:          monitorLabels(mattermost, spec, mattermost.Name),
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Endpoints: []monitoringv1.Endpoint{
+				{
+					Port:           metricsPortName,
+					Path:           metricsPath,
+					Interval:       monitoringv1.Duration(spec.Interval),
+					RelabelConfigs: relabelConfigs(spec.Relabelings),
+				},
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: mmv1beta.MattermostSelectorLabels(mattermost.Name),
+			},
+		},
+	}
+}
+
+// GenerateJobServerPodMonitorV1Beta returns the Prometheus Operator
+// PodMonitor scraping metrics of the dedicated job server pods, which are not
+// part of the Mattermost service.
+func GenerateJobServerPodMonitorV1Beta(mattermost *mmv1beta.Mattermost) *monitoringv1.PodMonitor {
+	spec := serviceMonitorSpec(mattermost)
+	name := mattermost.DedicatedJobServerName()
+
+	return &monitoringv1.PodMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       mattermost.Namespace,
+			Labels:          monitorLabels(mattermost, spec, name),
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: monitoringv1.PodMonitorSpec{
+			PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{
+				{
+					Port:           metricsPortName,
+					Path:           metricsPath,
+					Interval:       monitoringv1.Duration(spec.Interval),
+					RelabelConfigs: relabelConfigs(spec.Relabelings),
+				},
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					mmv1beta.ClusterLabel: mattermost.Name,
+					"app":                 mmv1beta.MattermostJobServerContainerName,
+				},
+			},
+		},
+	}
+}
+
+func serviceMonitorSpec(mattermost *mmv1beta.Mattermost) *mmv1beta.ServiceMonitor {
+	if mattermost.Spec.Monitoring == nil || mattermost.Spec.Monitoring.ServiceMonitor == nil {
+		return &mmv1beta.ServiceMonitor{}
+	}
+	return mattermost.Spec.Monitoring.ServiceMonitor
+}
+
+func monitorLabels(mattermost *mmv1beta.Mattermost, spec *mmv1beta.ServiceMonitor, name string) map[string]string {
+	labels := map[string]string{}
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	// Overwrite with default labels
+	for k, v := range mattermost.MattermostLabels(name) {
+		labels[k] = v
+	}
+	return labels
+}
+
+func relabelConfigs(relabelings []mmv1beta.RelabelConfig) []monitoringv1.RelabelConfig {
+	if len(relabelings) == 0 {
+		return nil
+	}
+
+	configs := make([]monitoringv1.RelabelConfig, 0, len(relabelings))
+	for _, relabeling := range relabelings {
+		configs = append(configs, monitoringv1.RelabelConfig{
+			SourceLabels: relabeling.SourceLabels,
+			Separator:    relabeling.Separator,
+			TargetLabel:  relabeling.TargetLabel,
+			Regex:        relabeling.Regex,
+			Replacement:  relabeling.Replacement,
+			Action:       relabeling.Action,
+		})
+	}
+	return configs
+}
@@ -0,0 +1,162 @@
+package mattermost
+
+import (
+	"fmt"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/components/utils"
+	minioConstants "github.com/minio/minio-operator/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// namespaceNameLabel is set by Kubernetes on every namespace.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+
+	defaultIngressNamespace    = "ingress-nginx"
+	defaultMonitoringNamespace = "monitoring"
+
+	// cnpgClusterLabel is set by CloudNativePG on the pods of a cluster.
+	cnpgClusterLabel = "cnpg.io/cluster"
+
+	mattermostAppPort     = 8065
+	mattermostMetricsPort = 8067
+	clusterGossipPort     = 8074
+	clusterStreamingPort  = 8075
+	dnsPort               = 53
+	mysqlPort             = 3306
+	postgresPort          = 5432
+	minioPort             = 9000
+)
+
+// GenerateNetworkPolicyV1Beta returns the NetworkPolicy isolating the pods of
+// the Mattermost installation.
+func GenerateNetworkPolicyV1Beta(mattermost *mmv1beta.Mattermost) *networkingv1.NetworkPolicy {
+	spec := mattermost.Spec.NetworkPolicy
+	if spec == nil {
+		spec = &mmv1beta.NetworkPolicy{}
+	}
+
+	mattermostPods := mattermostPodsSelector(mattermost)
+	clusterPorts := []networkingv1.NetworkPolicyPort{
+		networkPolicyPort(corev1.ProtocolTCP, clusterGossipPort),
+		networkPolicyPort(corev1.ProtocolUDP, clusterGossipPort),
+		networkPolicyPort(corev1.ProtocolTCP, clusterStreamingPort),
+	}
+
+	ingressNamespaceSelector := spec.IngressNamespaceSelector
+	if ingressNamespaceSelector == nil && spec.IngressPodSelector == nil {
+		ingressNamespaceSelector = namespaceSelector(defaultIngressNamespace)
+	}
+	monitoringNamespaceSelector := spec.MonitoringNamespaceSelector
+	if monitoringNamespaceSelector == nil && spec.MonitoringPodSelector == nil {
+		monitoringNamespaceSelector = namespaceSelector(defaultMonitoringNamespace)
+	}
+
+	ingressRules := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, mattermostAppPort)},
+			From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: ingressNamespaceSelector, PodSelector: spec.IngressPodSelector}},
+		},
+		{
+			Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, mattermostMetricsPort)},
+			From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: monitoringNamespaceSelector, PodSelector: spec.MonitoringPodSelector}},
+		},
+		{
+			Ports: clusterPorts,
+			From:  []networkingv1.NetworkPolicyPeer{{PodSelector: mattermostPods}},
+		},
+	}
+
+	egressRules := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				networkPolicyPort(corev1.ProtocolUDP, dnsPort),
+				networkPolicyPort(corev1.ProtocolTCP, dnsPort),
+			},
+		},
+		{
+			Ports: clusterPorts,
+			To:    []networkingv1.NetworkPolicyPeer{{PodSelector: mattermostPods}},
+		},
+	}
+
+	if !mattermost.Spec.Database.IsExternal() && mattermost.Spec.Database.OperatorManaged != nil {
+		switch mattermost.Spec.Database.OperatorManaged.Type {
+		case mmv1beta.DatabaseTypeMySQL:
+			egressRules = append(egressRules, podEgressRule(mmv1beta.MySQLLabels(), mysqlPort))
+		case mmv1beta.DatabaseTypePostgres:
+			egressRules = append(egressRules, podEgressRule(map[string]string{
+				cnpgClusterLabel: utils.HashWithPrefix("db", mattermost.Name),
+			}, postgresPort))
+		}
+	}
+
+	if mattermost.Spec.FileStore.IsOperatorManaged() {
+		egressRules = append(egressRules, podEgressRule(map[string]string{
+			minioConstants.InstanceLabel: fmt.Sprintf("%s-minio", mattermost.Name),
+		}, minioPort))
+	}
+
+	if len(spec.EgressCIDRs) > 0 {
+		rule := networkingv1.NetworkPolicyEgressRule{}
+		for _, cidr := range spec.EgressCIDRs {
+			rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+		}
+		egressRules = append(egressRules, rule)
+	}
+	egressRules = append(egressRules, spec.AdditionalEgress...)
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mattermost.Name,
+			Namespace:       mattermost.Namespace,
+			Labels:          mattermost.MattermostLabels(mattermost.Name),
+			OwnerReferences: MattermostOwnerReference(mattermost),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *mattermostPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress:     ingressRules,
+			Egress:      egressRules,
+		},
+	}
+}
+
+// mattermostPodsSelector selects the pods of the Mattermost deployment, the
+// dedicated job server and the deployment of a Canary or BlueGreen rollout.
+func mattermostPodsSelector(mattermost *mmv1beta.Mattermost) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      mmv1beta.ClusterLabel,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{mattermost.Name, mattermost.DedicatedJobServerName(), mattermost.RolloutDeploymentName()},
+			},
+		},
+	}
+}
+
+func namespaceSelector(namespace string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{namespaceNameLabel: namespace},
+	}
+}
+
+func podEgressRule(labels map[string]string, port int32) networkingv1.NetworkPolicyEgressRule {
+	return networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, port)},
+		To:    []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: labels}}},
+	}
+}
+
+func networkPolicyPort(protocol corev1.Protocol, port int32) networkingv1.NetworkPolicyPort {
+	portValue := intstr.FromInt32(port)
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &portValue,
+	}
+}
@@ -0,0 +1,160 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	pluginsVolumeName       = "mattermost-plugins"
+	pluginsDir              = "/mattermost/plugins"
+	pluginBundlesVolumeName = "mattermost-plugin-bundles"
+	pluginBundlesDir        = "/plugin-bundles"
+	pluginSourcesDir        = "/plugin-sources"
+	pluginBundleFile        = "bundle.tar.gz"
+
+	// localModeSocket is the default socket of the Mattermost local mode
+	// used by mmctl.
+	localModeSocket                 = "/var/tmp/mattermost_local.socket"
+	pluginSettingsAttempts          = 120
+	pluginSettingsRetryDelaySeconds = 5
+)
+
+// pluginsConfigV1Beta returns the init containers staging the plugin bundles
+// in the plugins directory of Mattermost, the environment variables enabling
+// plugins and the local mode used to set their states, and the volumes used.
+// Bundles from images are copied by an init container running the image, all
+// bundles are verified and extracted by the staging container.
+func pluginsConfigV1Beta(mattermost *mmv1beta.Mattermost) ([]corev1.Container, []corev1.EnvVar, []corev1.VolumeMount, []corev1.Volume) {
+	plugins := mattermost.Spec.Plugins
+	if len(plugins) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name:         pluginsVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name:         pluginBundlesVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	}
+	bundlesMount := corev1.VolumeMount{Name: pluginBundlesVolumeName, MountPath: pluginBundlesDir}
+	stagingMounts := []corev1.VolumeMount{
+		{Name: pluginsVolumeName, MountPath: pluginsDir},
+		bundlesMount,
+	}
+
+	var initContainers []corev1.Container
+	script := []string{"set -e"}
+	for i, plugin := range plugins {
+		bundle := fmt.Sprintf("%s/%d.tar.gz", pluginBundlesDir, i)
+		switch {
+		case plugin.URL != "":
+			script = append(script, fmt.Sprintf("curl -fsSL --retry 3 -o %s %s", bundle, shellQuote(plugin.URL)))
+		case plugin.ConfigMap != nil:
+			volumeName := fmt.Sprintf("mattermost-plugin-%d", i)
+			sourceDir := fmt.Sprintf("%s/%d", pluginSourcesDir, i)
+			volumes = append(volumes, corev1.Volume{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: plugin.ConfigMap.LocalObjectReference,
+						Items:                []corev1.KeyToPath{{Key: plugin.ConfigMap.Key, Path: pluginBundleFile}},
+					},
+				},
+			})
+			stagingMounts = append(stagingMounts, corev1.VolumeMount{Name: volumeName, MountPath: sourceDir, ReadOnly: true})
+			script = append(script, fmt.Sprintf("cp %s/%s %s", sourceDir, pluginBundleFile, bundle))
+		case plugin.Image != nil:
+			initContainers = append(initContainers, corev1.Container{
+				Name:            fmt.Sprintf("copy-plugin-%d", i),
+				Image:           plugin.Image.Name,
+				ImagePullPolicy: mattermost.Spec.ImagePullPolicy,
+				Command:         []string{"cp", plugin.Image.Path, bundle},
+				VolumeMounts:    []corev1.VolumeMount{bundlesMount},
+			})
+		}
+		if plugin.Checksum != "" {
+			script = append(script, fmt.Sprintf("echo %s | sha256sum -c -", shellQuote(strings.ToLower(plugin.Checksum)+"  "+bundle)))
+		}
+		pluginDir := shellQuote(pluginsDir + "/" + plugin.ID)
+		script = append(script,
+			fmt.Sprintf("tar -xzf %s -C %s", bundle, pluginsDir),
+			fmt.Sprintf("test -d %s || { echo %s; exit 1; }", pluginDir, shellQuote(fmt.Sprintf("bundle of plugin %s does not contain the plugin", plugin.ID))),
+		)
+	}
+	script = append(script, fmt.Sprintf("chmod -R a+rX %s", pluginsDir))
+
+	initContainers = append(initContainers, corev1.Container{
+		Name:            "stage-plugins",
+		Image:           mattermost.GetPluginStagingImage(),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"sh", "-c", strings.Join(script, "\n")},
+		VolumeMounts:    stagingMounts,
+	})
+
+	envVars := []corev1.EnvVar{
+		{
+			Name:  "MM_PLUGINSETTINGS_ENABLE",
+			Value: "true",
+		},
+		{
+			Name:  "MM_SERVICESETTINGS_ENABLELOCALMODE",
+			Value: "true",
+		},
+	}
+
+	return initContainers, envVars, []corev1.VolumeMount{{Name: pluginsVolumeName, MountPath: pluginsDir}}, volumes
+}
+
+// pluginSettingsLifecycleV1Beta returns the post start hook setting the
+// states and configuration of the plugins in the spec with mmctl in local
+// mode, once Mattermost listens on the socket. Only the plugins in the spec
+// are patched, the settings of other plugins are kept.
+func pluginSettingsLifecycleV1Beta(mattermost *mmv1beta.Mattermost) *corev1.Lifecycle {
+	if len(mattermost.Spec.Plugins) == 0 {
+		return nil
+	}
+
+	states := map[string]map[string]bool{}
+	configs := map[string]json.RawMessage{}
+	for _, plugin := range mattermost.Spec.Plugins {
+		states[plugin.ID] = map[string]bool{"Enable": plugin.IsEnabled()}
+		if plugin.Config != nil {
+			configs[plugin.ID] = json.RawMessage(plugin.Config.Raw)
+		}
+	}
+
+	settings := map[string]any{"PluginStates": states}
+	// Configs which are not valid JSON are rejected by the webhook.
+	if _, err := json.Marshal(configs); err == nil && len(configs) > 0 {
+		settings["Plugins"] = configs
+	}
+	patch, _ := json.Marshal(map[string]any{"PluginSettings": settings})
+
+	script := []string{
+		fmt.Sprintf("for i in $(seq %d); do", pluginSettingsAttempts),
+		fmt.Sprintf("  [ -S %s ] && printf '%%s' %s | mmctl --local config patch /dev/stdin && exit 0", localModeSocket, shellQuote(string(patch))),
+		fmt.Sprintf("  sleep %d", pluginSettingsRetryDelaySeconds),
+		"done",
+		"exit 1",
+	}
+
+	return &corev1.Lifecycle{
+		PostStart: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"sh", "-c", strings.Join(script, "\n")}},
+		},
+	}
+}
+
+// shellQuote quotes the value as a single shell word.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
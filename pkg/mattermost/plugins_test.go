@@ -0,0 +1,106 @@
+package mattermost
+
+import (
+	"testing"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/mattermost/mattermost-operator/pkg/database"
+	"github.com/mattermost/mattermost-operator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGenerateDeploymentPlugins_V1Beta(t *testing.T) {
+	checksum := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	mattermost := &mmv1beta.Mattermost{
+		Spec: mmv1beta.MattermostSpec{
+			Plugins: []mmv1beta.Plugin{
+				{
+					ID:       "com.mattermost.plugin-todo",
+					URL:      "https://github.com/mattermost/mattermost-plugin-todo/releases/download/v0.8.1/com.mattermost.plugin-todo-0.8.1.tar.gz",
+					Checksum: checksum,
+				},
+				{
+					ID: "com.example.internal",
+					ConfigMap: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "plugins"},
+						Key:                  "internal.tar.gz",
+					},
+					Enabled: utils.NewBool(false),
+				},
+				{
+					ID:     "com.example.image",
+					Image:  &mmv1beta.PluginImage{Name: "registry.example.com/plugins:1.0", Path: "/plugin.tar.gz"},
+					Config: &runtime.RawExtension{Raw: []byte(`{"enablefeature":true}`)},
+				},
+			},
+		},
+	}
+	dbConfig := &ExternalDBConfig{dbType: database.PostgreSQLDatabase, hasDBCheckURL: false}
+
+	deployment := GenerateDeploymentV1Beta(mattermost, dbConfig, &ExternalFileStore{}, "", "", "", "image")
+	podSpec := deployment.Spec.Template.Spec
+
+	require.Len(t, podSpec.InitContainers, 2)
+	copyContainer := podSpec.InitContainers[0]
+	assert.Equal(t, "registry.example.com/plugins:1.0", copyContainer.Image)
+	assert.Equal(t, []string{"cp", "/plugin.tar.gz", "/plugin-bundles/2.tar.gz"}, copyContainer.Command)
+
+	stageContainer := podSpec.InitContainers[1]
+	assert.Equal(t, "stage-plugins", stageContainer.Name)
+	assert.Equal(t, mmv1beta.DefaultPluginStagingImage, stageContainer.Image)
+	require.Len(t, stageContainer.Command, 3)
+	script := stageContainer.Command[2]
+	assert.Contains(t, script, "curl -fsSL --retry 3 -o /plugin-bundles/0.tar.gz 'https://github.com/mattermost/mattermost-plugin-todo/releases/download/v0.8.1/com.mattermost.plugin-todo-0.8.1.tar.gz'")
+	assert.Contains(t, script, "echo '"+checksum+"  /plugin-bundles/0.tar.gz' | sha256sum -c -")
+	assert.Contains(t, script, "cp /plugin-sources/1/bundle.tar.gz /plugin-bundles/1.tar.gz")
+	assert.Contains(t, script, "test -d '/mattermost/plugins/com.example.image'")
+	assert.Contains(t, stageContainer.VolumeMounts, corev1.VolumeMount{Name: "mattermost-plugin-1", MountPath: "/plugin-sources/1", ReadOnly: true})
+
+	assert.Contains(t, podSpec.Volumes, corev1.Volume{
+		Name: "mattermost-plugin-1",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "plugins"},
+			Items:                []corev1.KeyToPath{{Key: "internal.tar.gz", Path: "bundle.tar.gz"}},
+		}},
+	})
+	container := podSpec.Containers[0]
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: "mattermost-plugins", MountPath: "/mattermost/plugins"})
+	assertEnvVarEqual(t, "MM_PLUGINSETTINGS_ENABLE", "true", container.Env)
+	assertEnvVarEqual(t, "MM_SERVICESETTINGS_ENABLELOCALMODE", "true", container.Env)
+	assertEnvVarNotExist(t, "MM_PLUGINSETTINGS_PLUGINSTATES", container.Env)
+	assertEnvVarNotExist(t, "MM_PLUGINSETTINGS_PLUGINS", container.Env)
+
+	require.NotNil(t, container.Lifecycle)
+	require.NotNil(t, container.Lifecycle.PostStart)
+	postStart := container.Lifecycle.PostStart.Exec.Command
+	require.Len(t, postStart, 3)
+	assert.Contains(t, postStart[2], "mmctl --local config patch /dev/stdin")
+	assert.Contains(t, postStart[2], `'{"PluginSettings":{"PluginStates":{"com.example.image":{"Enable":true},"com.example.internal":{"Enable":false},"com.mattermost.plugin-todo":{"Enable":true}},"Plugins":{"com.example.image":{"enablefeature":true}}}}'`)
+
+	t.Run("job server does not apply plugin settings", func(t *testing.T) {
+		jobServer := GenerateJobServerDeploymentV1Beta(mattermost, dbConfig, &ExternalFileStore{}, "", "", "", "image")
+		assert.Nil(t, jobServer.Spec.Template.Spec.Containers[0].Lifecycle)
+	})
+
+	_, configMaps := ReferencedConfig(&podSpec)
+	assert.Equal(t, []string{"plugins"}, configMaps)
+
+	t.Run("custom staging image", func(t *testing.T) {
+		mattermost := mattermost.DeepCopy()
+		mattermost.Spec.PluginStagingImage = "registry.example.com/curl:8.11.1"
+
+		deployment := GenerateDeploymentV1Beta(mattermost, dbConfig, &ExternalFileStore{}, "", "", "", "image")
+		assert.Equal(t, "registry.example.com/curl:8.11.1", deployment.Spec.Template.Spec.InitContainers[1].Image)
+	})
+
+	t.Run("no plugins", func(t *testing.T) {
+		deployment := GenerateDeploymentV1Beta(&mmv1beta.Mattermost{}, dbConfig, &ExternalFileStore{}, "", "", "", "image")
+		assert.Empty(t, deployment.Spec.Template.Spec.InitContainers)
+		assertEnvVarExists(t, "MM_PLUGINSETTINGS_ENABLEUPLOADS", deployment.Spec.Template.Spec.Containers[0].Env)
+		assertEnvVarNotExist(t, "MM_SERVICESETTINGS_ENABLELOCALMODE", deployment.Spec.Template.Spec.Containers[0].Env)
+		assert.Nil(t, deployment.Spec.Template.Spec.Containers[0].Lifecycle)
+	})
+}
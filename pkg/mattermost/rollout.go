@@ -0,0 +1,68 @@
+package mattermost
+
+import (
+	"strconv"
+
+	"github.com/go-logr/logr"
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	canaryAnnotation         = "nginx.ingress.kubernetes.io/canary"
+	canaryWeightAnnotation   = "nginx.ingress.kubernetes.io/canary-weight"
+	canaryByCookieAnnotation = "nginx.ingress.kubernetes.io/canary-by-cookie"
+)
+
+// GenerateRolloutServiceV1Beta returns the service for the deployment running
+// the new Mattermost image during a Canary or BlueGreen rollout.
+func GenerateRolloutServiceV1Beta(mattermost *mmv1beta.Mattermost, name string) *corev1.Service {
+	service := newServiceV1Beta(mattermost, nil)
+	service.Name = name
+	service.Labels = mattermost.MattermostLabels(name)
+	service.Spec.Selector = mmv1beta.MattermostSelectorLabels(name)
+
+	return configureMattermostService(service)
+}
+
+// GenerateRolloutIngressV1Beta returns the NGINX canary ingress routing the
+// given percentage of requests, and requests selected by the cookie, to the
+// service of the new Mattermost image.
+func GenerateRolloutIngressV1Beta(mattermost *mmv1beta.Mattermost, name string, weight int32, cookie string, logger logr.Logger) *networkingv1.Ingress {
+	ingress := GenerateIngressV1Beta(mattermost, logger)
+	ingress.Name = name
+	ingress.Labels = mattermost.MattermostLabels(name)
+
+	ingress.Annotations[canaryAnnotation] = "true"
+	ingress.Annotations[canaryWeightAnnotation] = strconv.Itoa(int(weight))
+	delete(ingress.Annotations, canaryByCookieAnnotation)
+	if cookie != "" {
+		ingress.Annotations[canaryByCookieAnnotation] = cookie
+	}
+
+	for i := range ingress.Spec.Rules {
+		for j := range ingress.Spec.Rules[i].HTTP.Paths {
+			ingress.Spec.Rules[i].HTTP.Paths[j].Backend.Service.Name = name
+		}
+	}
+
+	return ingress
+}
+
+// GenerateRolloutDeploymentV1Beta returns the deployment running the new
+// Mattermost image during a Canary or BlueGreen rollout, based on the desired
+// Mattermost deployment.
+func GenerateRolloutDeploymentV1Beta(mattermost *mmv1beta.Mattermost, base *appsv1.Deployment, name string, replicas int32) *appsv1.Deployment {
+	deployment := base.DeepCopy()
+	deployment.ObjectMeta.Name = name
+	deployment.ObjectMeta.Labels = mattermost.MattermostLabels(name)
+	deployment.ObjectMeta.Annotations = nil
+	deployment.ObjectMeta.ResourceVersion = ""
+	deployment.Spec.Replicas = &replicas
+	deployment.Spec.Selector.MatchLabels = mmv1beta.MattermostSelectorLabels(name)
+	deployment.Spec.Template.ObjectMeta.Labels = mattermost.MattermostPodLabels(name)
+
+	return deployment
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "mattermost_operator"
+
+// Reconcile steps reported by the ReconcileErrors metric.
+const (
+	StepDatabase   = "database"
+	StepFileStore  = "filestore"
+	StepDeployment = "deployment"
+	StepJobServer  = "jobserver"
+	StepHealth     = "health"
+)
+
+// Outcomes of the update job reported by the UpdateJob metrics.
+const (
+	UpdateJobSucceeded = "succeeded"
+	UpdateJobFailed    = "failed"
+)
+
+var installationStates = []mmv1beta.RunningState{
+	mmv1beta.Reconciling,
+	mmv1beta.Ready,
+	mmv1beta.Stable,
+}
+
+var (
+	// InstallationState is set to 1 for the current state of the Mattermost
+	// installation and to 0 for all other states.
+	InstallationState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "installation_state",
+			Help:      "Current state of the Mattermost installation.",
+		},
+		[]string{"namespace", "name", "state"},
+	)
+
+	// InstallationDesiredReplicas is the number of replicas requested for
+	// the Mattermost installation.
+	InstallationDesiredReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "installation_desired_replicas",
+			Help:      "Number of Mattermost replicas requested in the spec.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// InstallationUpdatedReplicas is the number of replicas running the
+	// desired version of the Mattermost installation.
+	InstallationUpdatedReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "installation_updated_replicas",
+			Help:      "Number of Mattermost replicas running the desired version.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// UpdateJobDuration tracks how long update jobs take to complete.
+	UpdateJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "update_job_duration_seconds",
+			Help:      "Duration of Mattermost update jobs.",
+			Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		},
+		[]string{"outcome"},
+	)
+
+	// UpdateJobTotal counts completed update jobs by outcome.
+	UpdateJobTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "update_jobs_total",
+			Help:      "Number of completed Mattermost update jobs.",
+		},
+		[]string{"outcome"},
+	)
+
+	// ReconcileLimited is the number of installations currently waiting
+	// because the limit of reconciling installations was reached.
+	ReconcileLimited = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "reconcile_limited_installations",
+			Help:      "Number of installations waiting for the reconciling installations limit.",
+		},
+	)
+
+	// ReconcileErrors counts reconcile errors by the step that failed.
+	ReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "reconcile_errors_total",
+			Help:      "Number of Mattermost reconcile errors by reconcile step.",
+		},
+		[]string{"step"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		InstallationState,
+		InstallationDesiredReplicas,
+		InstallationUpdatedReplicas,
+		UpdateJobDuration,
+		UpdateJobTotal,
+		ReconcileLimited,
+		ReconcileErrors,
+	)
+}
+
+// SetInstallationState marks the given state as the current one for the
+// Mattermost installation.
+func SetInstallationState(namespace, name string, state mmv1beta.RunningState) {
+	for _, s := range installationStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		InstallationState.WithLabelValues(namespace, name, string(s)).Set(value)
+	}
+}
+
+// SetInstallationReplicas records desired and updated replicas of the
+// Mattermost installation.
+func SetInstallationReplicas(namespace, name string, desired, updated int32) {
+	InstallationDesiredReplicas.WithLabelValues(namespace, name).Set(float64(desired))
+	InstallationUpdatedReplicas.WithLabelValues(namespace, name).Set(float64(updated))
+}
+
+// DeleteInstallation removes all metrics of the Mattermost installation.
+func DeleteInstallation(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	InstallationState.DeletePartialMatch(labels)
+	InstallationDesiredReplicas.Delete(labels)
+	InstallationUpdatedReplicas.Delete(labels)
+}
+
+// ObserveUpdateJob records the outcome and duration of a completed update job.
+func ObserveUpdateJob(outcome string, duration time.Duration) {
+	UpdateJobTotal.WithLabelValues(outcome).Inc()
+	if duration > 0 {
+		UpdateJobDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+	}
+}
+
+// IncReconcileError increments the error counter of the reconcile step.
+func IncReconcileError(step string) {
+	ReconcileErrors.WithLabelValues(step).Inc()
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	mmv1beta "github.com/mattermost/mattermost-operator/apis/mattermost/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	metric := &dto.Metric{}
+	require.NoError(t, gauge.Write(metric))
+	return metric.GetGauge().GetValue()
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	metric := &dto.Metric{}
+	require.NoError(t, counter.Write(metric))
+	return metric.GetCounter().GetValue()
+}
+
+func TestInstallationMetrics(t *testing.T) {
+	SetInstallationState("ns", "mm", mmv1beta.Reconciling)
+	assert.Equal(t, 1.0, gaugeValue(t, InstallationState.WithLabelValues("ns", "mm", string(mmv1beta.Reconciling))))
+	assert.Equal(t, 0.0, gaugeValue(t, InstallationState.WithLabelValues("ns", "mm", string(mmv1beta.Stable))))
+
+	SetInstallationState("ns", "mm", mmv1beta.Stable)
+	assert.Equal(t, 0.0, gaugeValue(t, InstallationState.WithLabelValues("ns", "mm", string(mmv1beta.Reconciling))))
+	assert.Equal(t, 1.0, gaugeValue(t, InstallationState.WithLabelValues("ns", "mm", string(mmv1beta.Stable))))
+
+	SetInstallationReplicas("ns", "mm", 3, 2)
+	assert.Equal(t, 3.0, gaugeValue(t, InstallationDesiredReplicas.WithLabelValues("ns", "mm")))
+	assert.Equal(t, 2.0, gaugeValue(t, InstallationUpdatedReplicas.WithLabelValues("ns", "mm")))
+
+	DeleteInstallation("ns", "mm")
+	for _, collector := range []prometheus.Collector{InstallationState, InstallationDesiredReplicas, InstallationUpdatedReplicas} {
+		ch := make(chan prometheus.Metric, 10)
+		collector.Collect(ch)
+		close(ch)
+		assert.Empty(t, ch)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	before := counterValue(t, UpdateJobTotal.WithLabelValues(UpdateJobFailed))
+	ObserveUpdateJob(UpdateJobFailed, time.Minute)
+	assert.Equal(t, before+1, counterValue(t, UpdateJobTotal.WithLabelValues(UpdateJobFailed)))
+
+	before = counterValue(t, ReconcileErrors.WithLabelValues(StepDatabase))
+	IncReconcileError(StepDatabase)
+	assert.Equal(t, before+1, counterValue(t, ReconcileErrors.WithLabelValues(StepDatabase)))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2018 The prometheus-operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: only the fields used by the Mattermost operator are defined here.
+// Unknown fields are preserved by the API server, but they are dropped when
+// the monitors are updated by the operator.
+
+const (
+	ServiceMonitorsKind = "ServiceMonitor"
+	PodMonitorsKind     = "PodMonitor"
+)
+
+// Duration is a valid time duration that can be parsed by Prometheus
+// Supported units: y, w, d, h, m, s, ms
+// Examples: `30s`, `1m`, `1h20m15s`, `15d`
+// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+type Duration string
+
+// RelabelConfig allows dynamic rewriting of the label set for targets, alerts,
+// scraped samples and remote write samples.
+type RelabelConfig struct {
+	// The source labels select values from existing labels. Their content is
+	// concatenated using the configured Separator and matched against the
+	// configured regular expression.
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	// Separator is the string between concatenated SourceLabels.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+	// Label to which the resulting string is written in a replacement.
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// Regular expression against which the extracted value is matched.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+	// Modulus to take of the hash of the source label values.
+	// +optional
+	Modulus uint64 `json:"modulus,omitempty"`
+	// Replacement value against which a Replace action is performed if the
+	// regular expression matches.
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+	// Action to perform based on the regex matching.
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// Endpoint defines an endpoint serving Prometheus metrics to be scraped by
+// Prometheus.
+type Endpoint struct {
+	// Name of the Service port which this endpoint refers to.
+	// +optional
+	Port string `json:"port,omitempty"`
+	// HTTP path from which to scrape for metrics.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Interval at which Prometheus scrapes the metrics from the target.
+	// +optional
+	Interval Duration `json:"interval,omitempty"`
+	// Timeout after which Prometheus considers the scrape to be failed.
+	// +optional
+	ScrapeTimeout Duration `json:"scrapeTimeout,omitempty"`
+	// RelabelConfigs to apply to the target's metadata labels.
+	// +optional
+	RelabelConfigs []RelabelConfig `json:"relabelings,omitempty"`
+	// MetricRelabelConfigs to apply to samples before ingestion.
+	// +optional
+	MetricRelabelConfigs []RelabelConfig `json:"metricRelabelings,omitempty"`
+}
+
+// ServiceMonitorSpec defines the specification parameters for a
+// ServiceMonitor.
+type ServiceMonitorSpec struct {
+	// List of endpoints part of this ServiceMonitor.
+	Endpoints []Endpoint `json:"endpoints"`
+	// Label selector to select the Kubernetes `Endpoints` objects.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// ServiceMonitor defines monitoring for a set of services.
+// +kubebuilder:object:root=true
+type ServiceMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceMonitorSpec `json:"spec"`
+}
+
+// ServiceMonitorList is a list of ServiceMonitors.
+// +kubebuilder:object:root=true
+type ServiceMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServiceMonitor `json:"items"`
+}
+
+// PodMetricsEndpoint defines an endpoint serving Prometheus metrics to be
+// scraped by Prometheus.
+type PodMetricsEndpoint struct {
+	// Name of the Pod port which this endpoint refers to.
+	// +optional
+	Port string `json:"port,omitempty"`
+	// HTTP path from which to scrape for metrics.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Interval at which Prometheus scrapes the metrics from the target.
+	// +optional
+	Interval Duration `json:"interval,omitempty"`
+	// Timeout after which Prometheus considers the scrape to be failed.
+	// +optional
+	ScrapeTimeout Duration `json:"scrapeTimeout,omitempty"`
+	// RelabelConfigs to apply to the target's metadata labels.
+	// +optional
+	RelabelConfigs []RelabelConfig `json:"relabelings,omitempty"`
+	// MetricRelabelConfigs to apply to samples before ingestion.
+	// +optional
+	MetricRelabelConfigs []RelabelConfig `json:"metricRelabelings,omitempty"`
+}
+
+// PodMonitorSpec contains specification parameters for a PodMonitor.
+type PodMonitorSpec struct {
+	// List of endpoints part of this PodMonitor.
+	PodMetricsEndpoints []PodMetricsEndpoint `json:"podMetricsEndpoints"`
+	// Label selector to select the Kubernetes `Pod` objects.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// PodMonitor defines monitoring for a set of pods.
+// +kubebuilder:object:root=true
+type PodMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PodMonitorSpec `json:"spec"`
+}
+
+// PodMonitorList is a list of PodMonitors.
+// +kubebuilder:object:root=true
+type PodMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PodMonitor `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceMonitor{}, &ServiceMonitorList{}, &PodMonitor{}, &PodMonitorList{})
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: only the fields used by the Mattermost operator are defined here.
+// Unknown fields are preserved by the API server, but they are dropped when
+// the HTTPRoute is updated by the operator.
+
+const (
+	// GatewayKind is the kind of the Gateway resource.
+	GatewayKind = "Gateway"
+	// HTTPRouteKind is the kind of the HTTPRoute resource.
+	HTTPRouteKind = "HTTPRoute"
+
+	// RouteConditionAccepted indicates whether the route has been accepted
+	// or rejected by a Gateway.
+	RouteConditionAccepted = "Accepted"
+	// RouteConditionResolvedRefs indicates whether the controller was able
+	// to resolve all the object references for the route.
+	RouteConditionResolvedRefs = "ResolvedRefs"
+)
+
+// Group refers to a Kubernetes Group.
+type Group string
+
+// Kind refers to a Kubernetes Kind.
+type Kind string
+
+// ObjectName refers to the name of a Kubernetes object.
+type ObjectName string
+
+// Namespace refers to a Kubernetes namespace.
+type Namespace string
+
+// SectionName is the name of a section in a Kubernetes resource, such as
+// a Gateway listener.
+type SectionName string
+
+// PortNumber defines a network port.
+type PortNumber int32
+
+// Hostname is the fully qualified domain name of a network host.
+type Hostname string
+
+// Duration is a string value representing a duration in time, for example
+// `1h`, `10s` or `500ms`.
+type Duration string
+
+// PathMatchType specifies the semantics of how HTTP paths should be compared.
+type PathMatchType string
+
+const (
+	// PathMatchExact matches the URL path exactly.
+	PathMatchExact PathMatchType = "Exact"
+	// PathMatchPathPrefix matches based on a URL path prefix split by `/`.
+	PathMatchPathPrefix PathMatchType = "PathPrefix"
+)
+
+// ParentReference identifies an API object, usually a Gateway, that the
+// route wants to be attached to.
+type ParentReference struct {
+	// Group is the group of the referent.
+	// +optional
+	Group *Group `json:"group,omitempty"`
+	// Kind is kind of the referent.
+	// +optional
+	Kind *Kind `json:"kind,omitempty"`
+	// Namespace is the namespace of the referent.
+	// +optional
+	Namespace *Namespace `json:"namespace,omitempty"`
+	// Name is the name of the referent.
+	Name ObjectName `json:"name"`
+	// SectionName is the name of a section within the target resource, for
+	// example the name of a Gateway listener.
+	// +optional
+	SectionName *SectionName `json:"sectionName,omitempty"`
+	// Port is the network port this route targets.
+	// +optional
+	Port *PortNumber `json:"port,omitempty"`
+}
+
+// CommonRouteSpec defines the common attributes that all routes must include
+// within their spec.
+type CommonRouteSpec struct {
+	// ParentRefs references the resources that the route wants to be
+	// attached to.
+	// +optional
+	ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+}
+
+// HTTPRouteSpec defines the desired state of HTTPRoute.
+type HTTPRouteSpec struct {
+	CommonRouteSpec `json:",inline"`
+
+	// Hostnames defines a set of hostnames that should match against the
+	// HTTP Host header to select a HTTPRoute used to process the request.
+	// +optional
+	Hostnames []Hostname `json:"hostnames,omitempty"`
+
+	// Rules are a list of HTTP matchers, filters and actions.
+	// +optional
+	Rules []HTTPRouteRule `json:"rules,omitempty"`
+}
+
+// HTTPRouteRule defines semantics for matching an HTTP request based on
+// conditions (matches) and forwarding the request to an API object
+// (backendRefs).
+type HTTPRouteRule struct {
+	// Matches define conditions used for matching the rule against incoming
+	// HTTP requests.
+	// +optional
+	Matches []HTTPRouteMatch `json:"matches,omitempty"`
+	// BackendRefs defines the backend(s) where matching requests should be
+	// sent.
+	// +optional
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+	// Timeouts defines the timeouts that can be configured for an HTTP
+	// request.
+	// +optional
+	Timeouts *HTTPRouteTimeouts `json:"timeouts,omitempty"`
+}
+
+// HTTPRouteTimeouts defines timeouts that can be configured for an HTTPRoute.
+type HTTPRouteTimeouts struct {
+	// Request specifies the maximum duration for a gateway to respond to an
+	// HTTP request. The zero duration disables the timeout.
+	// +optional
+	Request *Duration `json:"request,omitempty"`
+	// BackendRequest specifies a timeout for an individual request from the
+	// gateway to a backend.
+	// +optional
+	BackendRequest *Duration `json:"backendRequest,omitempty"`
+}
+
+// HTTPRouteMatch defines the predicate used to match requests to a given
+// action.
+type HTTPRouteMatch struct {
+	// Path specifies a HTTP request path matcher.
+	// +optional
+	Path *HTTPPathMatch `json:"path,omitempty"`
+}
+
+// HTTPPathMatch describes how to select a HTTP route by matching the HTTP
+// request path.
+type HTTPPathMatch struct {
+	// Type specifies how to match against the path Value.
+	// +optional
+	Type *PathMatchType `json:"type,omitempty"`
+	// Value of the HTTP path to match against.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// HTTPBackendRef defines how a HTTPRoute forwards a HTTP request.
+type HTTPBackendRef struct {
+	BackendRef `json:",inline"`
+}
+
+// BackendRef defines how a Route should forward a request to a Kubernetes
+// resource.
+type BackendRef struct {
+	BackendObjectReference `json:",inline"`
+
+	// Weight specifies the proportion of requests forwarded to the
+	// referenced backend.
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// BackendObjectReference defines how an ObjectReference that is specific to
+// BackendRef.
+type BackendObjectReference struct {
+	// Group is the group of the referent. Defaults to the core API group.
+	// +optional
+	Group *Group `json:"group,omitempty"`
+	// Kind is the Kubernetes resource kind of the referent. Defaults to
+	// `Service`.
+	// +optional
+	Kind *Kind `json:"kind,omitempty"`
+	// Name is the name of the referent.
+	Name ObjectName `json:"name"`
+	// Namespace is the namespace of the backend.
+	// +optional
+	Namespace *Namespace `json:"namespace,omitempty"`
+	// Port specifies the destination port number to use for this resource.
+	// +optional
+	Port *PortNumber `json:"port,omitempty"`
+}
+
+// RouteParentStatus describes the status of a route with respect to an
+// associated Parent.
+type RouteParentStatus struct {
+	// ParentRef corresponds with a ParentRef in the spec that this
+	// RouteParentStatus struct describes the status of.
+	ParentRef ParentReference `json:"parentRef"`
+	// ControllerName is a domain/path string that indicates the name of the
+	// controller that wrote this status.
+	ControllerName string `json:"controllerName"`
+	// Conditions describes the status of the route with respect to the
+	// Gateway.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// RouteStatus defines the common attributes that all routes must include
+// within their status.
+type RouteStatus struct {
+	// Parents is a list of parent resources (usually Gateways) that are
+	// associated with the route, and the status of the route with respect to
+	// each parent.
+	Parents []RouteParentStatus `json:"parents"`
+}
+
+// HTTPRouteStatus defines the observed state of HTTPRoute.
+type HTTPRouteStatus struct {
+	RouteStatus `json:",inline"`
+}
+
+// HTTPRoute provides a way to route HTTP requests.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HTTPRouteSpec   `json:"spec"`
+	Status HTTPRouteStatus `json:"status,omitempty"`
+}
+
+// HTTPRouteList contains a list of HTTPRoute.
+// +kubebuilder:object:root=true
+type HTTPRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HTTPRoute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HTTPRoute{}, &HTTPRouteList{})
+}
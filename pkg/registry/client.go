@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// manifestMediaTypes are the manifest types accepted from registries. The
+// image index is preferred, so the digest covers all platforms of the image.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Client resolves image tags to digests.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a registry client using the HTTP client, or a default
+// client if nil.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{httpClient: httpClient}
+}
+
+// ResolveDigest returns the digest the tag of the image currently points to.
+// Registries requiring authentication are accessed with the credentials of
+// the registry of the image.
+func (c *Client) ResolveDigest(ctx context.Context, image string, credentials Credentials) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Endpoint(), ref.Repository, ref.Tag)
+	auth, hasAuth := credentials.For(ref)
+
+	resp, err := c.getManifest(ctx, http.MethodHead, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	authorization := ""
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err = c.authorize(ctx, resp.Header.Get("WWW-Authenticate"), ref, auth, hasAuth)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to authenticate to %s", ref.Registry)
+		}
+		resp, err = c.getManifest(ctx, http.MethodHead, manifestURL, authorization)
+		if err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get manifest of %s: %s", ref, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		// Registries are not required to return the digest on HEAD requests,
+		// it is the digest of the manifest then.
+		digest, err = c.manifestDigest(ctx, manifestURL, authorization)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get manifest of %s", ref)
+		}
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("unsupported digest %q of %s", digest, ref)
+	}
+
+	return digest, nil
+}
+
+func (c *Client) getManifest(ctx context.Context, method, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodHead {
+		resp.Body.Close()
+	}
+	return resp, nil
+}
+
+// manifestDigest returns the digest of the manifest content.
+func (c *Client) manifestDigest(ctx context.Context, manifestURL, authorization string) (string, error) {
+	resp, err := c.getManifest(ctx, http.MethodGet, manifestURL, authorization)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New(resp.Status)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// authorize returns the Authorization header answering the challenge of the
+// registry. Bearer challenges are answered with a token requested from the
+// token service of the registry, with the credentials if any.
+func (c *Client) authorize(ctx context.Context, challenge string, ref Reference, auth Auth, hasAuth bool) (string, error) {
+	scheme, rawParams, _ := strings.Cut(challenge, " ")
+	params := map[string]string{}
+	for _, match := range challengeParamRegexp.FindAllStringSubmatch(rawParams, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasAuth {
+			return "", errors.New("registry requires credentials, set them in an image pull secret")
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(auth.Username, auth.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := c.requestToken(ctx, params, ref, auth, hasAuth)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+func (c *Client) requestToken(ctx context.Context, params map[string]string, ref Reference, auth Auth, hasAuth bool) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", ref.Repository)
+	}
+	query := realm.Query()
+	query.Set("scope", scope)
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if hasAuth {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to request token")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to request token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, "failed to decode token")
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", errors.New("token service returned no token")
+}
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/mattermost-operator/pkg/registry/registrytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ResolveDigest(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Client())
+	ctx := context.Background()
+
+	digest := server.Push("mattermost/mattermost-enterprise-edition", "10.5.2", `{"manifests":[]}`)
+
+	t.Run("anonymous", func(t *testing.T) {
+		resolved, err := client.ResolveDigest(ctx, server.Image("mattermost/mattermost-enterprise-edition", "10.5.2"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, digest, resolved)
+	})
+
+	t.Run("moved tag", func(t *testing.T) {
+		moved := server.Push("mattermost/mattermost-enterprise-edition", "10.5.2", `{"manifests":[{}]}`)
+		resolved, err := client.ResolveDigest(ctx, server.Image("mattermost/mattermost-enterprise-edition", "10.5.2"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, moved, resolved)
+		assert.NotEqual(t, digest, resolved)
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		_, err := client.ResolveDigest(ctx, server.Image("mattermost/mattermost-enterprise-edition", "10.6.0"), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("token authentication", func(t *testing.T) {
+		server.Username, server.Password = "robot", "secret"
+		defer func() { server.Username, server.Password = "", "" }()
+		image := server.Image("mattermost/mattermost-enterprise-edition", "10.5.2")
+
+		_, err := client.ResolveDigest(ctx, image, nil)
+		assert.Error(t, err)
+
+		_, err = client.ResolveDigest(ctx, image, Credentials{server.Host(): {Username: "robot", Password: "wrong"}})
+		assert.Error(t, err)
+
+		resolved, err := client.ResolveDigest(ctx, image, Credentials{server.Host(): {Username: "robot", Password: "secret"}})
+		require.NoError(t, err)
+		assert.Equal(t, registrytest.Digest(`{"manifests":[{}]}`), resolved)
+	})
+}
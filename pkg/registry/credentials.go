@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Auth holds the credentials of a registry.
+type Auth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Auth is the base64 encoded "username:password" pair.
+	Auth string `json:"auth,omitempty"`
+}
+
+// Credentials are registry credentials by registry host.
+type Credentials map[string]Auth
+
+// For returns the credentials of the registry of the reference.
+func (c Credentials) For(ref Reference) (Auth, bool) {
+	auth, ok := c[ref.Registry]
+	return auth, ok
+}
+
+// CredentialsFromSecrets reads the registry credentials of image pull secrets
+// of the kubernetes.io/dockerconfigjson and kubernetes.io/dockercfg types.
+// Credentials of earlier secrets take precedence, like for the kubelet.
+func CredentialsFromSecrets(secrets []corev1.Secret) (Credentials, error) {
+	credentials := Credentials{}
+	for _, secret := range secrets {
+		var auths map[string]Auth
+		switch secret.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			var config struct {
+				Auths map[string]Auth `json:"auths"`
+			}
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse image pull secret %s", secret.Name)
+			}
+			auths = config.Auths
+		case corev1.SecretTypeDockercfg:
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse image pull secret %s", secret.Name)
+			}
+		default:
+			continue
+		}
+
+		for address, auth := range auths {
+			auth, err := decodeAuth(auth)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse credentials of %s in image pull secret %s", address, secret.Name)
+			}
+			registry := normalizeRegistry(address)
+			if _, ok := credentials[registry]; !ok {
+				credentials[registry] = auth
+			}
+		}
+	}
+
+	return credentials, nil
+}
+
+// decodeAuth fills the username and password from the encoded auth field if
+// they are not set.
+func decodeAuth(auth Auth) (Auth, error) {
+	if auth.Auth == "" || auth.Username != "" {
+		return auth, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+	if err != nil {
+		return Auth{}, err
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return Auth{}, errors.New("auth is not a username:password pair")
+	}
+	auth.Username, auth.Password = username, password
+	return auth, nil
+}
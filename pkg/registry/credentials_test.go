@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCredentialsFromSecrets(t *testing.T) {
+	secrets := []corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dockerconfigjson"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{
+					"https://index.docker.io/v1/":{"auth":"dXNlcjpwYXNz"},
+					"registry.example.com":{"username":"robot","password":"secret"}}}`),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dockercfg"},
+			Type:       corev1.SecretTypeDockercfg,
+			Data: map[string][]byte{
+				corev1.DockerConfigKey: []byte(`{
+					"registry.example.com":{"username":"other","password":"other"},
+					"quay.io":{"username":"quay","password":"quay"}}`),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "opaque"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"key": []byte("value")},
+		},
+	}
+
+	credentials, err := CredentialsFromSecrets(secrets)
+	require.NoError(t, err)
+	assert.Len(t, credentials, 3)
+
+	auth, ok := credentials.For(Reference{Registry: "docker.io"})
+	require.True(t, ok)
+	assert.Equal(t, "user", auth.Username)
+	assert.Equal(t, "pass", auth.Password)
+
+	auth, ok = credentials.For(Reference{Registry: "registry.example.com"})
+	require.True(t, ok)
+	assert.Equal(t, "robot", auth.Username, "credentials of the first secret take precedence")
+
+	_, ok = credentials.For(Reference{Registry: "ghcr.io"})
+	assert.False(t, ok)
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, err := CredentialsFromSecrets([]corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{")},
+		}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+// Package registry resolves image tags to digests with the Docker Registry
+// HTTP API V2 implemented by Docker Hub and OCI registries.
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// dockerHub is the registry of images without a registry host.
+	dockerHub = "docker.io"
+	// dockerHubEndpoint serves the registry API of Docker Hub.
+	dockerHubEndpoint = "registry-1.docker.io"
+)
+
+// Reference is an image reference split into its parts.
+type Reference struct {
+	// Registry host, "docker.io" for Docker Hub.
+	Registry string
+	// Repository in the registry, for example "mattermost/mattermost-enterprise-edition".
+	Repository string
+	// Tag of the image, "latest" if not set.
+	Tag string
+}
+
+// ParseReference parses a tagged image reference like
+// "mattermost/mattermost-enterprise-edition:10.5.2". References pinned to a
+// digest are not accepted since they need no resolution.
+func ParseReference(image string) (Reference, error) {
+	if image == "" {
+		return Reference{}, fmt.Errorf("image reference is empty")
+	}
+	if strings.Contains(image, "@") {
+		return Reference{}, fmt.Errorf("image reference %q is already pinned to a digest", image)
+	}
+
+	name, tag := image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, tag = image[:i], image[i+1:]
+	}
+	if name == "" || tag == "" {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	ref := Reference{Registry: dockerHub, Repository: name, Tag: tag}
+	if i := strings.Index(name, "/"); i > 0 && isRegistryHost(name[:i]) {
+		ref.Registry, ref.Repository = normalizeRegistry(name[:i]), name[i+1:]
+	}
+	if ref.Registry == dockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	return ref, nil
+}
+
+// Endpoint returns the host serving the registry API.
+func (r Reference) Endpoint() string {
+	if r.Registry == dockerHub {
+		return dockerHubEndpoint
+	}
+	return r.Registry
+}
+
+// String returns the reference in the "registry/repository:tag" format.
+func (r Reference) String() string {
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Tag)
+}
+
+// isRegistryHost returns true if the first component of the image name is a
+// registry host rather than a Docker Hub namespace.
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// normalizeRegistry returns the registry host of a registry address as used
+// in image references and docker config files.
+func normalizeRegistry(address string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	switch host {
+	case "index.docker.io", dockerHubEndpoint:
+		return dockerHub
+	}
+	return host
+}
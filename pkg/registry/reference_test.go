@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	for _, testCase := range []struct {
+		image    string
+		expected Reference
+		endpoint string
+	}{
+		{
+			image:    "mattermost/mattermost-enterprise-edition:10.5.2",
+			expected: Reference{Registry: "docker.io", Repository: "mattermost/mattermost-enterprise-edition", Tag: "10.5.2"},
+			endpoint: "registry-1.docker.io",
+		},
+		{
+			image:    "alpine",
+			expected: Reference{Registry: "docker.io", Repository: "library/alpine", Tag: "latest"},
+			endpoint: "registry-1.docker.io",
+		},
+		{
+			image:    "docker.io/mattermost/mattermost-team-edition:latest",
+			expected: Reference{Registry: "docker.io", Repository: "mattermost/mattermost-team-edition", Tag: "latest"},
+			endpoint: "registry-1.docker.io",
+		},
+		{
+			image:    "registry.example.com:5000/team/mattermost:10.5",
+			expected: Reference{Registry: "registry.example.com:5000", Repository: "team/mattermost", Tag: "10.5"},
+			endpoint: "registry.example.com:5000",
+		},
+		{
+			image:    "localhost/mattermost:dev",
+			expected: Reference{Registry: "localhost", Repository: "mattermost", Tag: "dev"},
+			endpoint: "localhost",
+		},
+	} {
+		t.Run(testCase.image, func(t *testing.T) {
+			ref, err := ParseReference(testCase.image)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, ref)
+			assert.Equal(t, testCase.endpoint, ref.Endpoint())
+		})
+	}
+
+	for _, image := range []string{
+		"",
+		"mattermost/mattermost-enterprise-edition:10.5.2@sha256:dd15a51ac7dafd213744d1ef23394e7532f71a90f477c969b94600e46da5a0cf",
+		"mattermost/mattermost-enterprise-edition:",
+	} {
+		t.Run("invalid "+image, func(t *testing.T) {
+			_, err := ParseReference(image)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package registrytest provides a registry stand-in serving the manifest
+// endpoints of the Docker Registry HTTP API V2 for tests.
+package registrytest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const token = "registrytest-token"
+
+// Server is a TLS registry serving the manifests pushed to it. If Username
+// is set, clients have to get a token with the credentials first, like from
+// Docker Hub.
+type Server struct {
+	*httptest.Server
+
+	Username string
+	Password string
+
+	lock      sync.Mutex
+	manifests map[string]string
+	requests  int
+}
+
+// NewServer starts a registry. The server has to be closed by the caller,
+// and has to be accessed with the client of the server to trust its
+// certificate.
+func NewServer() *Server {
+	s := &Server{manifests: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", s.serveToken)
+	mux.HandleFunc("/v2/", s.serveManifest)
+	s.Server = httptest.NewTLSServer(mux)
+	return s
+}
+
+// Host returns the registry host to use in image references.
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "https://")
+}
+
+// Image returns the reference of the repository and tag in the registry.
+func (s *Server) Image(repository, tag string) string {
+	return fmt.Sprintf("%s/%s:%s", s.Host(), repository, tag)
+}
+
+// Push tags a manifest with the content in the repository and returns the
+// digest of the manifest. Pushing a different content moves the tag.
+func (s *Server) Push(repository, tag, content string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.manifests[repository+":"+tag] = content
+	return Digest(content)
+}
+
+// Requests returns the number of manifest requests served.
+func (s *Server) Requests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests
+}
+
+// Digest returns the digest of the manifest content.
+func Digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	username, password, _ := r.BasicAuth()
+	if username != s.Username || password != s.Password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+func (s *Server) serveManifest(w http.ResponseWriter, r *http.Request) {
+	repository, tag, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v2/"), "/manifests/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if s.Username != "" && r.Header.Get("Authorization") != "Bearer "+token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm="%s/token",service="registrytest",scope="repository:%s:pull"`, s.URL, repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	s.lock.Lock()
+	s.requests++
+	content, found := s.manifests[repository+":"+tag]
+	s.lock.Unlock()
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+	w.Header().Set("Docker-Content-Digest", Digest(content))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write([]byte(content))
+}
@@ -0,0 +1,43 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	certmanagerv1 "github.com/mattermost/mattermost-operator/pkg/certificates/cert_manager/v1"
+	"github.com/pkg/errors"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (r *ResourceHelper) CreateCertificateIfNotExists(owner v1.Object, certificate *certmanagerv1.Certificate, reqLogger logr.Logger) error {
+	foundCertificate := &certmanagerv1.Certificate{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: certificate.Name, Namespace: certificate.Namespace}, foundCertificate)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating Certificate", "name", certificate.Name)
+		return r.Create(owner, certificate, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if Certificate exists")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) DeleteCertificate(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundCertificate := &certmanagerv1.Certificate{}
+	err := r.client.Get(context.TODO(), key, foundCertificate)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if Certificate exists")
+	}
+
+	reqLogger.Info("Deleting Certificate", "name", foundCertificate.Name)
+	err = r.client.Delete(context.TODO(), foundCertificate)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete Certificate")
+	}
+
+	return nil
+}
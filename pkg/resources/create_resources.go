@@ -2,6 +2,7 @@ package resources
 
 import (
 	"context"
+	"slices"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -12,9 +13,12 @@ import (
 	objectMatcher "github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -192,6 +196,32 @@ func (r *ResourceHelper) CreatePvcIfNotExists(owner v1.Object, pvc *corev1.Persi
 	return nil
 }
 
+func (r *ResourceHelper) CreatePodDisruptionBudgetIfNotExists(owner v1.Object, pdb *policyv1.PodDisruptionBudget, reqLogger logr.Logger) error {
+	foundPDB := &policyv1.PodDisruptionBudget{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, foundPDB)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating pod disruption budget", "name", pdb.Name)
+		return r.Create(owner, pdb, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if pod disruption budget exists")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) CreateHorizontalPodAutoscalerIfNotExists(owner v1.Object, hpa *autoscalingv2.HorizontalPodAutoscaler, reqLogger logr.Logger) error {
+	foundHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: hpa.Name, Namespace: hpa.Namespace}, foundHPA)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating horizontal pod autoscaler", "name", hpa.Name)
+		return r.Create(owner, hpa, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if horizontal pod autoscaler exists")
+	}
+
+	return nil
+}
+
 func (r *ResourceHelper) DeleteDeployment(key types.NamespacedName, reqLogger logr.Logger) error {
 	foundDeployment := &appsv1.Deployment{}
 	err := r.client.Get(context.TODO(), key, foundDeployment)
@@ -263,3 +293,99 @@ func (r *ResourceHelper) DeleteService(key types.NamespacedName, reqLogger logr.
 
 	return nil
 }
+
+func (r *ResourceHelper) DeletePodDisruptionBudget(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundPDB := &policyv1.PodDisruptionBudget{}
+	err := r.client.Get(context.TODO(), key, foundPDB)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if pod disruption budget exists")
+	}
+
+	reqLogger.Info("Deleting pod disruption budget", "name", foundPDB.Name)
+	err = r.client.Delete(context.TODO(), foundPDB)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete pod disruption budget")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) DeleteHorizontalPodAutoscaler(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.client.Get(context.TODO(), key, foundHPA)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if horizontal pod autoscaler exists")
+	}
+
+	reqLogger.Info("Deleting horizontal pod autoscaler", "name", foundHPA.Name)
+	err = r.client.Delete(context.TODO(), foundHPA)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete horizontal pod autoscaler")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) CreateNetworkPolicyIfNotExists(owner v1.Object, networkPolicy *networkingv1.NetworkPolicy, reqLogger logr.Logger) error {
+	foundNetworkPolicy := &networkingv1.NetworkPolicy{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: networkPolicy.Name, Namespace: networkPolicy.Namespace}, foundNetworkPolicy)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating network policy", "name", networkPolicy.Name)
+		return r.Create(owner, networkPolicy, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if network policy exists")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) DeleteNetworkPolicy(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundNetworkPolicy := &networkingv1.NetworkPolicy{}
+	err := r.client.Get(context.TODO(), key, foundNetworkPolicy)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if network policy exists")
+	}
+
+	reqLogger.Info("Deleting network policy", "name", foundNetworkPolicy.Name)
+	err = r.client.Delete(context.TODO(), foundNetworkPolicy)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete network policy")
+	}
+
+	return nil
+}
+
+// RemoveOwnerReference removes the reference to the owner from the resource,
+// so that the resource is not garbage collected together with the owner.
+// Returns false if the resource does not exist.
+func (r *ResourceHelper) RemoveOwnerReference(owner v1.Object, key types.NamespacedName, resource client.Object, reqLogger logr.Logger) (bool, error) {
+	err := r.client.Get(context.TODO(), key, resource)
+	if err != nil && (k8sErrors.IsNotFound(err) || meta.IsNoMatchError(err)) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to check if resource exists")
+	}
+
+	references := resource.GetOwnerReferences()
+	retained := slices.DeleteFunc(slices.Clone(references), func(ref v1.OwnerReference) bool {
+		return ref.UID == owner.GetUID()
+	})
+	if len(retained) == len(references) {
+		return true, nil
+	}
+
+	reqLogger.Info("Removing owner reference", "name", key.Name, "owner", owner.GetName())
+	resource.SetOwnerReferences(retained)
+	err = r.client.Update(context.TODO(), resource)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to remove owner reference")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,43 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	gatewayv1 "github.com/mattermost/mattermost-operator/pkg/networking/gateway_api/v1"
+	"github.com/pkg/errors"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (r *ResourceHelper) CreateHTTPRouteIfNotExists(owner v1.Object, route *gatewayv1.HTTPRoute, reqLogger logr.Logger) error {
+	foundRoute := &gatewayv1.HTTPRoute{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, foundRoute)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating HTTPRoute", "name", route.Name)
+		return r.Create(owner, route, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if HTTPRoute exists")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) DeleteHTTPRoute(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundRoute := &gatewayv1.HTTPRoute{}
+	err := r.client.Get(context.TODO(), key, foundRoute)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if HTTPRoute exists")
+	}
+
+	reqLogger.Info("Deleting HTTPRoute", "name", foundRoute.Name)
+	err = r.client.Delete(context.TODO(), foundRoute)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete HTTPRoute")
+	}
+
+	return nil
+}
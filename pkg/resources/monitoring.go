@@ -0,0 +1,74 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	monitoringv1 "github.com/mattermost/mattermost-operator/pkg/monitoring/prometheus_operator/v1"
+	"github.com/pkg/errors"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (r *ResourceHelper) CreateServiceMonitorIfNotExists(owner v1.Object, serviceMonitor *monitoringv1.ServiceMonitor, reqLogger logr.Logger) error {
+	foundServiceMonitor := &monitoringv1.ServiceMonitor{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: serviceMonitor.Name, Namespace: serviceMonitor.Namespace}, foundServiceMonitor)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating service monitor", "name", serviceMonitor.Name)
+		return r.Create(owner, serviceMonitor, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if service monitor exists")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) CreatePodMonitorIfNotExists(owner v1.Object, podMonitor *monitoringv1.PodMonitor, reqLogger logr.Logger) error {
+	foundPodMonitor := &monitoringv1.PodMonitor{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: podMonitor.Name, Namespace: podMonitor.Namespace}, foundPodMonitor)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating pod monitor", "name", podMonitor.Name)
+		return r.Create(owner, podMonitor, reqLogger)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if pod monitor exists")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) DeleteServiceMonitor(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundServiceMonitor := &monitoringv1.ServiceMonitor{}
+	err := r.client.Get(context.TODO(), key, foundServiceMonitor)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if service monitor exists")
+	}
+
+	reqLogger.Info("Deleting service monitor", "name", foundServiceMonitor.Name)
+	err = r.client.Delete(context.TODO(), foundServiceMonitor)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete service monitor")
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) DeletePodMonitor(key types.NamespacedName, reqLogger logr.Logger) error {
+	foundPodMonitor := &monitoringv1.PodMonitor{}
+	err := r.client.Get(context.TODO(), key, foundPodMonitor)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if pod monitor exists")
+	}
+
+	reqLogger.Info("Deleting pod monitor", "name", foundPodMonitor.Name)
+	err = r.client.Delete(context.TODO(), foundPodMonitor)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete pod monitor")
+	}
+
+	return nil
+}
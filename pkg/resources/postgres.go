@@ -0,0 +1,74 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/mattermost/mattermost-operator/pkg/components/utils"
+	cnpgv1 "github.com/mattermost/mattermost-operator/pkg/database/cnpg_operator/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (r *ResourceHelper) CreatePostgresClusterIfNotExists(owner v1.Object, cluster *cnpgv1.Cluster, reqLogger logr.Logger) error {
+	foundCluster := &cnpgv1.Cluster{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, foundCluster)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		reqLogger.Info("Creating postgres cluster")
+		return r.Create(owner, cluster, reqLogger)
+	} else if err != nil {
+		reqLogger.Error(err, "Failed to check if postgres cluster exists")
+		return err
+	}
+
+	return nil
+}
+
+func (r *ResourceHelper) GetOrCreatePostgresSecrets(owner v1.Object, name string, reqLogger logr.Logger) (*corev1.Secret, error) {
+	var err error
+	dbSecret := &corev1.Secret{}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, dbSecret)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return r.createPostgresSecret(owner, name, reqLogger)
+		}
+
+		reqLogger.Error(err, "failed to check if postgres secret exists")
+		return nil, err
+	}
+
+	return dbSecret, nil
+}
+
+// createPostgresSecret creates the secret used by CloudNativePG to bootstrap
+// the database owner. CloudNativePG requires a basic-auth secret with
+// username and password keys.
+func (r *ResourceHelper) createPostgresSecret(owner v1.Object, secretName string, reqLogger logr.Logger) (*corev1.Secret, error) {
+	reqLogger.Info("Creating new postgres secret")
+
+	dbSecret := &corev1.Secret{}
+
+	dbSecret.SetName(secretName)
+	dbSecret.SetNamespace(owner.GetNamespace())
+	dbSecret.Type = corev1.SecretTypeBasicAuth
+	userName := "mmuser"
+	dbName := "mattermost"
+	userPassword := string(utils.New16ID())
+
+	dbSecret.Data = map[string][]byte{
+		corev1.BasicAuthUsernameKey: []byte(userName),
+		corev1.BasicAuthPasswordKey: []byte(userPassword),
+		"DATABASE":                  []byte(dbName),
+	}
+
+	err := r.Create(owner, dbSecret, reqLogger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create postgres secret")
+	}
+
+	return dbSecret, nil
+}
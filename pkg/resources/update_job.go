@@ -94,6 +94,16 @@ func PrepareMattermostJobTemplate(name, namespace string, baseDeployment *appsv1
 	// Set default app label always
 	podLabels["app"] = name
 
+	// Keep the hash of the configuration verified by the job.
+	if hash, ok := baseDeployment.Spec.Template.Annotations[mattermostApp.ConfigHashAnnotation]; ok {
+		annotations := make(map[string]string, len(podAnnotations)+1)
+		for k, v := range podAnnotations {
+			annotations[k] = v
+		}
+		annotations[mattermostApp.ConfigHashAnnotation] = hash
+		podAnnotations = annotations
+	}
+
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -125,6 +135,8 @@ func PrepareMattermostJobTemplate(name, namespace string, baseDeployment *appsv1
 		// We dont need to validate the readiness/liveness for this short lived job.
 		job.Spec.Template.Spec.Containers[i].LivenessProbe = nil
 		job.Spec.Template.Spec.Containers[i].ReadinessProbe = nil
+		// The job does not start the server, which applies the plugin settings.
+		job.Spec.Template.Spec.Containers[i].Lifecycle = nil
 
 		// We don't want the full server to start so we print the version and exit
 		job.Spec.Template.Spec.Containers[i].Args = []string{"db", "migrate"}
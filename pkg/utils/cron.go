@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed standard 5-field cron expression.
+type CronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// Day of month and day of week are OR-ed when both are restricted.
+	dayOfMonthStar, dayOfWeekStar bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var (
+	cronMinute     = cronField{0, 59}
+	cronHour       = cronField{0, 23}
+	cronDayOfMonth = cronField{1, 31}
+	cronMonth      = cronField{1, 12}
+	cronDayOfWeek  = cronField{0, 6}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseCronSchedule parses a cron expression in the "minute hour day-of-month
+// month day-of-week" format. Lists, ranges, steps and the @yearly, @monthly,
+// @weekly, @daily and @hourly descriptors are supported.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression %q, found %d", spec, len(fields))
+	}
+
+	schedule := &CronSchedule{
+		dayOfMonthStar: fields[2] == "*",
+		dayOfWeekStar:  fields[4] == "*",
+	}
+
+	var err error
+	for i, target := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&schedule.minute, cronMinute},
+		{&schedule.hour, cronHour},
+		{&schedule.dayOfMonth, cronDayOfMonth},
+		{&schedule.month, cronMonth},
+		{&schedule.dayOfWeek, cronDayOfWeek},
+	} {
+		*target.bits, err = parseCronField(fields[i], target.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+	}
+
+	// Sunday can be specified as 7.
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+
+	return schedule, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		max := field.max
+		if field == cronDayOfWeek {
+			max = 7
+		}
+
+		start, end := field.min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(bounds[0])
+			end, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			start, end = n, n
+			if strings.Contains(part, "/") {
+				end = max
+			}
+		}
+
+		if start < field.min || end > max || start > end {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, field.min, field.max)
+		}
+		for n := start; n <= end; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first time matching the schedule after the given time.
+// The returned time is truncated to the minute and in the location of t.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Every valid schedule matches at least once in 5 years, accounting for
+	// leap days.
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Matches returns true if the minute of the given time matches the schedule.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	return s.month&(1<<uint(t.Month())) != 0 &&
+		s.matchesDay(t) &&
+		s.hour&(1<<uint(t.Hour())) != 0 &&
+		s.minute&(1<<uint(t.Minute())) != 0
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthStar || s.dayOfWeekStar {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
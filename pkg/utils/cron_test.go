@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, spec := range []string{
+		"* * * * *",
+		"0 2 * * *",
+		"*/15 1-5 1,15 * 1-5",
+		"30 4 * * 7",
+		"@daily",
+		"@hourly",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseCronSchedule(spec)
+			assert.NoError(t, err)
+		})
+	}
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		t.Run("invalid "+spec, func(t *testing.T) {
+			_, err := ParseCronSchedule(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2024, time.February, 28, 10, 30, 45, 0, time.UTC)
+
+	for _, testCase := range []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.February, 28, 10, 31, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2024, time.February, 29, 2, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.February, 28, 11, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2024, time.February, 28, 10, 40, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		// Saturday
+		{"0 3 * * 6", time.Date(2024, time.March, 2, 3, 0, 0, 0, time.UTC)},
+		// Sunday as 7
+		{"0 3 * * 7", time.Date(2024, time.March, 3, 3, 0, 0, 0, time.UTC)},
+		// Day of month or day of week
+		{"0 0 15 * 5", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	} {
+		t.Run(testCase.spec, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(testCase.spec)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, schedule.Next(from))
+			assert.True(t, schedule.Matches(testCase.expected))
+		})
+	}
+}
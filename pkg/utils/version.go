@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed "major.minor.patch" version.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses a semantic version like "10.5.1". The "v" prefix, a
+// missing patch number and pre-release or build suffixes are accepted, the
+// suffixes are ignored.
+func ParseVersion(value string) (Version, error) {
+	trimmed := strings.TrimPrefix(value, "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("version %q is not in the major.minor.patch format", value)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return Version{}, fmt.Errorf("version %q is not in the major.minor.patch format", value)
+		}
+		numbers[i] = number
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Compare returns -1, 0 or 1 if the version is lower than, equal to or
+// greater than the other version.
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
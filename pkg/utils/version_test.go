@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	for value, expected := range map[string]Version{
+		"10.5.1":       {10, 5, 1},
+		"v9.11.0":      {9, 11, 0},
+		"10.5":         {10, 5, 0},
+		"10.6.0-rc1":   {10, 6, 0},
+		"10.6.0+build": {10, 6, 0},
+	} {
+		t.Run(value, func(t *testing.T) {
+			version, err := ParseVersion(value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, version)
+		})
+	}
+
+	for _, value := range []string{"", "latest", "10", "10.5.1.2", "10.x.1", "sha256:dd15a51ac7da"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			_, err := ParseVersion(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	assert.Equal(t, 0, Version{10, 5, 1}.Compare(Version{10, 5, 1}))
+	assert.Equal(t, -1, Version{9, 11, 5}.Compare(Version{10, 0, 0}))
+	assert.Equal(t, 1, Version{10, 5, 2}.Compare(Version{10, 5, 1}))
+	assert.Equal(t, -1, Version{10, 4, 9}.Compare(Version{10, 5, 0}))
+}